@@ -11,6 +11,8 @@ import (
 	"time"
 
 	"trading-systemv1/internal/gateway"
+	"trading-systemv1/internal/indicator"
+	"trading-systemv1/internal/markethours"
 
 	goredis "github.com/go-redis/redis/v8"
 )
@@ -84,13 +86,7 @@ func parseTFs(s string) []int {
 		if p == "" {
 			continue
 		}
-		n := 0
-		for _, c := range p {
-			if c >= '0' && c <= '9' {
-				n = n*10 + int(c-'0')
-			}
-		}
-		if n > 0 {
+		if n, err := markethours.ParseTF(p); err == nil {
 			tfs = append(tfs, n)
 		}
 	}
@@ -147,18 +143,13 @@ func parseIndicatorNames(s string) []string {
 	}
 
 	var names []string
-	for _, part := range strings.Split(s, ",") {
-		part = strings.TrimSpace(part)
-		tokens := strings.SplitN(part, ":", 2)
-		if len(tokens) != 2 {
-			continue
-		}
-		typ := strings.ToUpper(strings.TrimSpace(tokens[0]))
-		period := strings.TrimSpace(tokens[1])
-		if typ == "" || period == "" {
+	for _, part := range indicator.SplitIndicatorSpecs(s) {
+		cfg, err := indicator.ParseIndicatorConfig(part)
+		if err != nil {
+			log.Printf("[api_gateway] skipping indicator spec: %v", err)
 			continue
 		}
-		names = append(names, typ+"_"+period)
+		names = append(names, cfg.Name())
 	}
 	if len(names) == 0 {
 		return defaults
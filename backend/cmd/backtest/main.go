@@ -13,12 +13,12 @@ import (
 	"log"
 	"os"
 	"os/signal"
-	"strconv"
 	"strings"
 	"syscall"
 
 	"trading-systemv1/internal/indicator"
 	"trading-systemv1/internal/marketdata/replay"
+	"trading-systemv1/internal/markethours"
 	"trading-systemv1/internal/model"
 	sqlitestore "trading-systemv1/internal/store/sqlite"
 )
@@ -31,7 +31,7 @@ func main() {
 	tfStr := flag.String("tf", "60,300", "Comma-separated TFs to replay")
 	fromTS := flag.Int64("from", 0, "Unix timestamp to start replay from (0=all)")
 	dbPath := flag.String("db", "data/candles.db", "Path to SQLite database")
-	indicatorCfg := flag.String("indicators", "", "Indicator specs: TYPE:PERIOD,... (default: SMA:20,EMA:9,RSI:14)")
+	indicatorCfg := flag.String("indicators", "", "Indicator specs: TYPE:PERIOD or TYPE(P1,P2,...), with optional :SOURCE and @INNER (default: SMA:20,EMA:9,RSI:14)")
 	flag.Parse()
 
 	tfs := parseTFs(*tfStr)
@@ -117,7 +117,7 @@ func parseTFs(s string) []int {
 	var tfs []int
 	for _, p := range strings.Split(s, ",") {
 		p = strings.TrimSpace(p)
-		if n, err := strconv.Atoi(p); err == nil && n > 0 {
+		if n, err := markethours.ParseTF(p); err == nil {
 			tfs = append(tfs, n)
 		}
 	}
@@ -135,19 +135,13 @@ func parseIndicatorSpecs(s string) []indicator.IndicatorConfig {
 		}
 	}
 	var configs []indicator.IndicatorConfig
-	for _, part := range strings.Split(s, ",") {
-		tokens := strings.SplitN(strings.TrimSpace(part), ":", 2)
-		if len(tokens) != 2 {
+	for _, part := range indicator.SplitIndicatorSpecs(s) {
+		cfg, err := indicator.ParseIndicatorConfig(part)
+		if err != nil {
+			log.Printf("[backtest] skipping indicator spec: %v", err)
 			continue
 		}
-		period, err := strconv.Atoi(strings.TrimSpace(tokens[1]))
-		if err != nil || period <= 0 {
-			continue
-		}
-		configs = append(configs, indicator.IndicatorConfig{
-			Type:   strings.ToUpper(strings.TrimSpace(tokens[0])),
-			Period: period,
-		})
+		configs = append(configs, cfg)
 	}
 	return configs
 }
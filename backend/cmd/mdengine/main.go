@@ -15,16 +15,20 @@ import (
 
 	"trading-systemv1/config"
 	"trading-systemv1/internal/marketdata/agg"
+	"trading-systemv1/internal/marketdata/barbuilder"
 	"trading-systemv1/internal/marketdata/bus"
 	"trading-systemv1/internal/marketdata/closedetector"
+	"trading-systemv1/internal/marketdata/heikinashi"
 	"trading-systemv1/internal/marketdata/tfbuilder"
 	"trading-systemv1/internal/marketdata/ws"
 	"trading-systemv1/internal/marketdata/wssim"
 	"trading-systemv1/internal/markethours"
 	"trading-systemv1/internal/metrics"
 	"trading-systemv1/internal/model"
+	"trading-systemv1/internal/oi"
 	redisstore "trading-systemv1/internal/store/redis"
 	sqlitestore "trading-systemv1/internal/store/sqlite"
+	"trading-systemv1/internal/store/tickstore"
 	smartconnect "trading-systemv1/pkg/smartconnect"
 )
 
@@ -63,6 +67,19 @@ func main() {
 	}
 	log.Printf("[mdengine] enabled TFs: %v seconds", enabledTFs)
 
+	var heikinAshi bool
+	amendWindow := config.ParseDuration(getEnv("CANDLE_AMEND_WINDOW", "0"))
+	gapFill := getEnv("GAP_FILL", "")
+	gapFillMax := config.ParseDuration(getEnv("GAP_FILL_MAX", "30m"))
+	if stagingMode {
+		heikinAshi = config.HeikinAshiEnabled()
+	} else {
+		heikinAshi = cfg.HeikinAshi
+		amendWindow = cfg.CandleAmendWindow
+		gapFill = cfg.GapFill
+		gapFillMax = cfg.GapFillMax
+	}
+
 	// ---- Setup pipeline channels ----
 	tickCh := make(chan model.Tick, 10000)
 	candleCh := make(chan model.Candle, 5000)
@@ -77,11 +94,17 @@ func main() {
 	redisAddr := getEnv("REDIS_ADDR", "localhost:6379")
 	redisPassword := getEnv("REDIS_PASSWORD", "")
 	sqlitePath := getEnv("SQLITE_PATH", "data/candles.db")
+	tickArchive := config.ParseBool(getEnv("TICK_ARCHIVE", "false"))
+	tickArchivePath := getEnv("TICK_ARCHIVE_PATH", "data/ticks.db")
+	tickRetentionDays, _ := strconv.Atoi(getEnv("TICK_RETENTION_DAYS", "30"))
 	if !stagingMode {
 		metricsAddr = cfg.MetricsAddr
 		redisAddr = cfg.RedisAddr
 		redisPassword = cfg.RedisPassword
 		sqlitePath = cfg.SQLitePath
+		tickArchive = cfg.TickArchive
+		tickArchivePath = cfg.TickArchivePath
+		tickRetentionDays = cfg.TickRetentionDays
 	}
 
 	prom := metrics.NewMetrics()
@@ -167,13 +190,14 @@ func main() {
 	}
 
 	// ---- TF Builder (HOT PATH) ----
-	tfBuilder := tfbuilder.New(enabledTFs)
+	tfBuilder := tfbuilder.New(timeTFs(enabledTFs))
 	tfBuilder.OnTFCandle = func(c model.TFCandle) {
 		prom.TFCandlesTotal.WithLabelValues(strconv.Itoa(c.TF)).Inc()
 	}
 	tfBuilder.OnStaleCandle = func() {
 		prom.StaleCandlesRejected.Inc()
 	}
+	tfBuilder.AmendWindow = amendWindow
 	health.SetTFBuilderOK(true)
 	log.Printf("[mdengine] TF builder started with TFs=%v (stale tolerance=%v)", enabledTFs, tfBuilder.StaleTolerance)
 
@@ -194,8 +218,48 @@ func main() {
 		}
 	}()
 
+	// ---- Bar Builder (tick/volume/range/Renko series in ENABLED_TFS) ----
+	barBuilder := barbuilder.New(enabledTFs)
+	if len(barBuilder.TFs()) > 0 {
+		barBuilder.OnBar = func(c model.TFCandle) {
+			prom.TFCandlesTotal.WithLabelValues(model.TFKey(c.TF)).Inc()
+		}
+		log.Printf("[mdengine] bar builder started with series=%v (fed from ticks)", tfKeys(barBuilder.TFs()))
+	}
+
+	// ---- Heikin-Ashi (derived from every TF candle, finalized and forming) ----
+	var haDeriver *heikinashi.Deriver
+	if heikinAshi {
+		haDeriver = heikinashi.New()
+		log.Println("[mdengine] Heikin-Ashi candles enabled for all TFs")
+	}
+
+	// ---- Open interest analytics (F&O bars carrying SnapQuote OI) ----
+	oiTracker := oi.NewTracker()
+	oiBarCh := make(chan model.OIBar, 1000)
+
 	// ---- Fan out TF candles to Redis + SQLite (OFF hot path) ----
 	redisFormingCh := make(chan model.TFCandle, 5000)
+	route := func(tfc model.TFCandle) {
+		if tfc.Forming {
+			select {
+			case redisFormingCh <- tfc:
+			default:
+			}
+			return
+		}
+		select {
+		case redisTFCandleCh <- tfc:
+		default:
+		}
+		if model.IsBarTF(tfc.TF) || model.IsHATF(tfc.TF) {
+			return // bar timestamps are not second-unique (Renko bursts), HA is derived: Redis only
+		}
+		select {
+		case sqliteTFCandleCh <- tfc:
+		default:
+		}
+	}
 	go func() {
 		for {
 			select {
@@ -205,20 +269,18 @@ func main() {
 				if !ok {
 					return
 				}
-				if tfc.Forming {
+				route(tfc)
+				if tfc.Revision > 0 {
+					continue // revisions do not advance OI or Heikin-Ashi series
+				}
+				if bar, ok := oiTracker.Update(tfc); ok {
 					select {
-					case redisFormingCh <- tfc:
+					case oiBarCh <- bar:
 					default:
 					}
-					continue
 				}
-				select {
-				case redisTFCandleCh <- tfc:
-				default:
-				}
-				select {
-				case sqliteTFCandleCh <- tfc:
-				default:
+				if haDeriver != nil {
+					route(haDeriver.Derive(tfc))
 				}
 			}
 		}
@@ -227,6 +289,30 @@ func main() {
 	if redisWriter != nil {
 		go redisWriter.RunTFCandles(ctx, redisTFCandleCh)
 		go redisWriter.RunFormingTFCandles(ctx, redisFormingCh)
+		go redisWriter.RunOIBars(ctx, oiBarCh)
+	}
+
+	// ---- Quotes / depth (SUBSCRIBE_MODE quote|snapquote, SUBSCRIBE_DEPTH) → Redis ----
+	quoteCh := make(chan model.Quote, 4096)
+	depthCh := make(chan model.Depth, 1024)
+	if redisWriter != nil {
+		go redisWriter.RunMarketData(ctx, quoteCh, depthCh)
+	}
+	attachMarketData := func(ing *ws.Ingest) {
+		ing.OnQuote = func(q model.Quote) {
+			select {
+			case quoteCh <- q:
+			default:
+				prom.MarketDataDrops.Inc()
+			}
+		}
+		ing.OnDepth = func(d model.Depth) {
+			select {
+			case depthCh <- d:
+			default:
+				prom.MarketDataDrops.Inc()
+			}
+		}
 	}
 	go sqlWriter.RunTFCandles(ctx, sqliteTFCandleCh)
 
@@ -235,6 +321,55 @@ func main() {
 	aggregator.OnDroppedTick = func() {
 		prom.DroppedTicks.Inc()
 	}
+	if amendWindow > 0 {
+		aggregator.AmendWindow = amendWindow
+		aggregator.OnAmend = func() {
+			prom.CandleAmendments.Inc()
+		}
+		log.Printf("[mdengine] candle amendments enabled: late ticks up to %v behind revise their candles", amendWindow)
+	}
+	if fill := config.ParseGapFill(gapFill); fill != nil {
+		aggregator.GapFill = fill
+		aggregator.MaxGapFill = gapFillMax
+		aggregator.OnGapFill = func(n int) {
+			prom.GapFilledCandles.Add(float64(n))
+		}
+		log.Printf("[mdengine] gap fill enabled for %s: empty seconds get flat synthetic candles", gapFill)
+	}
+
+	// Bars close on the exact tick: fed inline from the aggregator goroutine
+	if len(barBuilder.TFs()) > 0 {
+		aggregator.OnTick = func(t model.Tick) {
+			barBuilder.RunTick(t, tfCandleCh)
+		}
+	}
+
+	// ---- Raw tick archive (optional, OFF hot path) ----
+	if tickArchive {
+		os.MkdirAll(filepath.Dir(tickArchivePath), 0o755)
+		tickWriter, err := tickstore.New(tickstore.WriterConfig{
+			DBPath:        tickArchivePath,
+			RetentionDays: tickRetentionDays,
+		})
+		if err != nil {
+			log.Printf("[mdengine] WARNING: tick archive init failed: %v (continuing without it)", err)
+		} else {
+			defer tickWriter.Close()
+			archiveCh := make(chan model.Tick, 20000)
+			next := aggregator.OnTick
+			aggregator.OnTick = func(t model.Tick) {
+				select {
+				case archiveCh <- t:
+				default:
+					prom.TickArchiveDrops.Inc()
+				}
+				if next != nil {
+					next(t)
+				}
+			}
+			go tickWriter.Run(ctx, archiveCh)
+		}
+	}
 	go aggregator.Run(ctx, tickCh, candleCh)
 	log.Println("[mdengine] pipeline ready (24/7)")
 
@@ -242,26 +377,55 @@ func main() {
 	// WS Lifecycle: STAGING vs PRODUCTION
 	// ═══════════════════════════════════════════════════════════════
 	if stagingMode {
-		// ---- STAGING: connect to tickserver via wssim ----
-		simWSURL := getEnv("SIM_WS_URL", "ws://localhost:9001/ws")
-		log.Printf("[mdengine] staging tick source: %s", simWSURL)
-
-		ingest, err := wssim.New(wssim.Config{
-			URL:               simWSURL,
-			ReconnectDelay:    2 * time.Second,
-			MaxReconnectDelay: 30 * time.Second,
-		})
-		if err != nil {
-			log.Fatalf("[mdengine] wssim init failed: %v", err)
-		}
-		ingest.OnReconnect = func() {
-			prom.WSReconnects.Inc()
+		// ---- STAGING: connect to tickserver via wssim (JSON) or the production
+		// SmartStream ingest against tickserver's emulator ----
+		simProtocol := getEnv("SIM_PROTOCOL", "json")
+		var simWSURL string
+		var startIngest func(context.Context, chan<- model.Tick) error
+		switch simProtocol {
+		case "json":
+			simWSURL = getEnv("SIM_WS_URL", "ws://localhost:9001/ws")
+			ingest, err := wssim.New(wssim.Config{
+				URL:               simWSURL,
+				ReconnectDelay:    2 * time.Second,
+				MaxReconnectDelay: 30 * time.Second,
+			})
+			if err != nil {
+				log.Fatalf("[mdengine] wssim init failed: %v", err)
+			}
+			ingest.OnReconnect = func() {
+				prom.WSReconnects.Inc()
+			}
+			startIngest = ingest.Start
+		case "smartstream":
+			simWSURL = getEnv("SIM_WS_URL", "ws://localhost:9001/smart-stream")
+			ingest, err := ws.New(ws.IngestConfig{
+				AuthToken:      "staging",
+				APIKey:         "staging",
+				ClientCode:     "staging",
+				FeedToken:      "staging",
+				SubscribeMode:  config.ParseSubscribeMode(getEnv("SUBSCRIBE_MODE", "ltp")),
+				SubscribeDepth: config.ParseBool(getEnv("SUBSCRIBE_DEPTH", "false")),
+				TokenList:      parseTokenList(getEnv("SUBSCRIBE_TOKENS", "1:99926000")),
+				URL:            simWSURL,
+			})
+			if err != nil {
+				log.Fatalf("[mdengine] smartstream ingest init failed: %v", err)
+			}
+			attachMarketData(ingest)
+			ingest.OnReconnect = func() {
+				prom.WSReconnects.Inc()
+			}
+			startIngest = ingest.Start
+		default:
+			log.Fatalf("[mdengine] unknown SIM_PROTOCOL %q (want json or smartstream)", simProtocol)
 		}
+		log.Printf("[mdengine] staging tick source: %s (%s)", simWSURL, simProtocol)
 		health.SetWSConnected(true)
 
 		go func() {
-			if err := ingest.Start(ctx, tickCh); err != nil {
-				log.Printf("[mdengine] wssim error: %v", err)
+			if err := startIngest(ctx, tickCh); err != nil {
+				log.Printf("[mdengine] %s ingest error: %v", simProtocol, err)
 				health.SetWSConnected(false)
 			}
 		}()
@@ -361,12 +525,13 @@ func main() {
 				wsCtx, wsCancel := context.WithDeadline(ctx, wsDeadline)
 
 				ingest, err := ws.New(ws.IngestConfig{
-					AuthToken:     authToken,
-					APIKey:        cfg.AngelAPIKey,
-					ClientCode:    cfg.AngelClientCode,
-					FeedToken:     feedToken,
-					SubscribeMode: smartconnect.ModeLTP,
-					TokenList:     tokenList,
+					AuthToken:      authToken,
+					APIKey:         cfg.AngelAPIKey,
+					ClientCode:     cfg.AngelClientCode,
+					FeedToken:      feedToken,
+					SubscribeMode:  cfg.SubscribeMode,
+					SubscribeDepth: cfg.SubscribeDepth,
+					TokenList:      tokenList,
 				})
 				if err != nil {
 					log.Printf("[mdengine] ws init failed: %v, retrying in 30s", err)
@@ -378,6 +543,7 @@ func main() {
 				ingest.OnReconnect = func() {
 					prom.WSReconnects.Inc()
 				}
+				attachMarketData(ingest)
 
 				// Close detection callback: observe each tick after 15:30
 				ingest.OnTick = func(price int64) {
@@ -412,6 +578,7 @@ func main() {
 				// Finalize all in-progress candles (ADR-006 Contract #3)
 				aggregator.FlushSession(candleCh)
 				tfBuilder.FlushSession(tfCandleCh)
+				barBuilder.FlushSession(tfCandleCh)
 
 				log.Printf("[mdengine] 🔌 WS disconnected — closing price: %d", detector.ClosingPrice())
 
@@ -512,7 +679,27 @@ func minDur(a, b time.Duration) time.Duration {
 	return b
 }
 
-// parseTFsFromEnv parses comma-separated TF seconds for staging mode.
+// timeTFs drops the bar series from tfs, leaving the TFs tfbuilder resamples.
+func timeTFs(tfs []int) []int {
+	var out []int
+	for _, tf := range tfs {
+		if !model.IsBarTF(tf) {
+			out = append(out, tf)
+		}
+	}
+	return out
+}
+
+// tfKeys formats TFs for logging: "tick500", "renko1000".
+func tfKeys(tfs []int) []string {
+	keys := make([]string, len(tfs))
+	for i, tf := range tfs {
+		keys[i] = model.TFKey(tf)
+	}
+	return keys
+}
+
+// parseTFsFromEnv parses comma-separated TF seconds (or D/W/M) for staging mode.
 func parseTFsFromEnv(s string) []int {
 	var tfs []int
 	for _, p := range strings.Split(s, ",") {
@@ -520,8 +707,8 @@ func parseTFsFromEnv(s string) []int {
 		if p == "" {
 			continue
 		}
-		n, err := strconv.Atoi(p)
-		if err != nil || n <= 0 {
+		n, err := markethours.ParseTF(p)
+		if err != nil {
 			log.Printf("[mdengine] skipping invalid TF %q", p)
 			continue
 		}
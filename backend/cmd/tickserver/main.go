@@ -1,20 +1,27 @@
 // cmd/tickserver — Demo WebSocket tick server.
 // Broadcasts simulated tick data for testing mdengine-sim without real broker credentials.
 //
-// Tick JSON shape is identical to model.Tick:
+// Tick JSON shape on /ws is identical to model.Tick:
 //
 //	{"token":"2885","exchange":"NSE","price":185005000,"qty":10,"tick_ts":"..."}
 //
 // Price is stored in paise (1 INR = 100 paise), same as live feed.
 //
+// The same ticks are served on /smart-stream in the Angel One SmartStream v2
+// binary protocol (see package wsemu), so mdengine's production ingest path
+// can run offline (SIM_PROTOCOL=smartstream). POST /emulator/drop closes
+// every SmartStream connection to exercise reconnect and resubscribe.
+//
 // Config (env vars):
 //
 //	TICK_SERVER_ADDR  — listen address  (default: ":9001")
 //	TICK_TOKENS       — comma-separated TOKEN:EXCHANGE pairs (default: "99926000:NSE")
 //	TICK_INTERVAL_MS  — broadcast interval milliseconds (default: "100")
+//	TICK_MODE         — random | replay | scenario (default: "random"; see replay.go, scenario.go)
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -26,18 +33,12 @@ import (
 	"sync"
 	"time"
 
+	"trading-systemv1/internal/marketdata/wsemu"
+	"trading-systemv1/internal/model"
+
 	"github.com/gorilla/websocket"
 )
 
-// tickMsg mirrors model.Tick for JSON serialisation.
-type tickMsg struct {
-	Token    string    `json:"token"`
-	Exchange string    `json:"exchange"`
-	Price    int64     `json:"price"` // paise
-	Qty      int64     `json:"qty"`
-	TickTS   time.Time `json:"tick_ts"`
-}
-
 // instrument holds per-symbol simulation state.
 type instrument struct {
 	Token     string
@@ -51,10 +52,12 @@ type instrument struct {
 type hub struct {
 	mu      sync.RWMutex
 	clients map[*websocket.Conn]chan []byte
+
+	emu *wsemu.Server // SmartStream clients
 }
 
 func newHub() *hub {
-	return &hub{clients: make(map[*websocket.Conn]chan []byte)}
+	return &hub{clients: make(map[*websocket.Conn]chan []byte), emu: wsemu.New()}
 }
 
 func (h *hub) register(conn *websocket.Conn) chan []byte {
@@ -85,6 +88,14 @@ func (h *hub) broadcast(msg []byte) {
 	}
 }
 
+// publish sends a tick to JSON and SmartStream clients alike.
+func (h *hub) publish(t model.Tick) {
+	if b, err := json.Marshal(t); err == nil {
+		h.broadcast(b)
+	}
+	h.emu.Publish(t)
+}
+
 // ─── WebSocket handler ────────────────────────────────────────────────────────
 
 var upgrader = websocket.Upgrader{
@@ -149,18 +160,13 @@ func runGenerator(h *hub, instruments []instrument, intervalMs int) {
 	for range ticker.C {
 		for i := range instruments {
 			instruments[i].Price = walkPrice(instruments[i].Price, instruments[i].BasePrice)
-			msg := tickMsg{
+			h.publish(model.Tick{
 				Token:    instruments[i].Token,
 				Exchange: instruments[i].Exchange,
 				Price:    instruments[i].Price,
 				Qty:      int64(rand.Intn(100) + 1),
 				TickTS:   time.Now().UTC(),
-			}
-			b, err := json.Marshal(msg)
-			if err != nil {
-				continue
-			}
-			h.broadcast(b)
+			})
 		}
 	}
 }
@@ -182,20 +188,38 @@ func main() {
 		log.Fatalf("[tickserver] no instruments configured via TICK_TOKENS")
 	}
 	log.Printf("[tickserver] instruments: %+v", instruments)
-	log.Printf("[tickserver] broadcast interval: %dms", intervalMs)
 
 	h := newHub()
 
-	// Start tick generator
-	go runGenerator(h, instruments, intervalMs)
+	// Start tick source
+	switch mode := envOrDefault("TICK_MODE", "random"); mode {
+	case "random":
+		log.Printf("[tickserver] broadcast interval: %dms", intervalMs)
+		go runGenerator(h, instruments, intervalMs)
+	case "replay":
+		startReplay(context.Background(), h, instruments)
+	case "scenario":
+		startScenario(context.Background(), h)
+	default:
+		log.Fatalf("[tickserver] unknown TICK_MODE %q (want random, replay or scenario)", mode)
+	}
 
 	// HTTP routes
 	http.HandleFunc("/ws", wsHandler(h))
+	http.Handle("/smart-stream", h.emu)
+	http.HandleFunc("/emulator/drop", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, `{"error":"POST required"}`, http.StatusMethodNotAllowed)
+			return
+		}
+		fmt.Fprintf(w, `{"dropped":%d}`+"\n", h.emu.DropClients())
+	})
 	http.HandleFunc("/health", func(w http.ResponseWriter, _ *http.Request) {
 		fmt.Fprintln(w, `{"status":"ok","service":"tickserver"}`)
 	})
 
 	log.Printf("[tickserver] ✅ listening on %s  (WebSocket: ws://localhost%s/ws)", addr, addr)
+	log.Printf("[tickserver]    SmartStream v2 emulator: ws://localhost%s/smart-stream", addr)
 	if err := http.ListenAndServe(addr, nil); err != nil {
 		log.Fatalf("[tickserver] server error: %v", err)
 	}
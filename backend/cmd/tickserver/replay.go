@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"trading-systemv1/internal/marketdata/tickreplay"
+	"trading-systemv1/internal/markethours"
+	"trading-systemv1/internal/model"
+	sqlitestore "trading-systemv1/internal/store/sqlite"
+	"trading-systemv1/internal/store/tickstore"
+)
+
+// ─── Replay mode ──────────────────────────────────────────────────────────────
+//
+// TICK_MODE=replay streams a recorded session instead of the random walk:
+//
+//	REPLAY_DATE        — IST trading day to replay, "2006-01-02" (required)
+//	REPLAY_SOURCE      — auto | ticks | candles (default: "auto" — archived ticks, else candles_1s)
+//	REPLAY_SPEED       — speed multiplier, 0 = as fast as possible (default: "1")
+//	TICK_ARCHIVE_PATH  — tick archive written by mdengine (default: "data/ticks.db")
+//	SQLITE_PATH        — candle database for the candles_1s fallback (default: "data/candles.db")
+//
+// Ticks keep their recorded timestamps. Controls (HTTP):
+//
+//	GET  /replay/status
+//	POST /replay/pause | /replay/resume
+//	POST /replay/speed?x=10
+//	POST /replay/seek?ts=10:30   (IST time on REPLAY_DATE, or RFC3339)
+
+// startReplay loads the session, registers the control endpoints and starts
+// playback into the hub.
+func startReplay(ctx context.Context, h *hub, instruments []instrument) {
+	dateStr := os.Getenv("REPLAY_DATE")
+	day, err := time.ParseInLocation("2006-01-02", dateStr, markethours.IST)
+	if err != nil {
+		log.Fatalf("[tickserver] REPLAY_DATE must be YYYY-MM-DD, got %q", dateStr)
+	}
+	source := envOrDefault("REPLAY_SOURCE", tickreplay.SourceAuto)
+	speed, err := strconv.ParseFloat(envOrDefault("REPLAY_SPEED", "1"), 64)
+	if err != nil || speed < 0 {
+		log.Fatalf("[tickserver] invalid REPLAY_SPEED %q", os.Getenv("REPLAY_SPEED"))
+	}
+
+	var ticksReader *tickstore.Reader
+	if path := envOrDefault("TICK_ARCHIVE_PATH", "data/ticks.db"); source != tickreplay.SourceCandles && fileExists(path) {
+		if ticksReader, err = tickstore.NewReader(path); err != nil {
+			log.Fatalf("[tickserver] tick archive: %v", err)
+		}
+		defer ticksReader.Close()
+	}
+	var candlesReader *sqlitestore.Reader
+	if path := envOrDefault("SQLITE_PATH", "data/candles.db"); source != tickreplay.SourceTicks && fileExists(path) {
+		if candlesReader, err = sqlitestore.NewReader(path); err != nil {
+			log.Fatalf("[tickserver] candle database: %v", err)
+		}
+		defer candlesReader.Close()
+	}
+
+	ins := make([]tickreplay.Instrument, len(instruments))
+	for i, in := range instruments {
+		ins[i] = tickreplay.Instrument{Exchange: in.Exchange, Token: in.Token}
+	}
+	ticks, err := tickreplay.Load(day, ins, source, ticksReader, candlesReader)
+	if err != nil {
+		log.Fatalf("[tickserver] replay load failed: %v", err)
+	}
+	if len(ticks) == 0 {
+		log.Fatalf("[tickserver] nothing to replay for %s (source=%s)", dateStr, source)
+	}
+	play(ctx, h, ticks, speed, day)
+}
+
+// play starts a Player over ticks that broadcasts into the hub and exposes
+// its controls under /replay/. Seek times of day are taken on day (IST).
+func play(ctx context.Context, h *hub, ticks []model.Tick, speed float64, day time.Time) {
+	log.Printf("[tickserver] playing %d ticks (%s → %s IST) at %.1fx",
+		len(ticks),
+		ticks[0].TickTS.In(markethours.IST).Format("2006-01-02 15:04:05"),
+		ticks[len(ticks)-1].TickTS.In(markethours.IST).Format("15:04:05"), speed)
+
+	player := tickreplay.NewPlayer(ticks, speed)
+	registerReplayHandlers(player, day)
+
+	go func() {
+		player.Run(ctx, h.publish)
+	}()
+}
+
+// registerReplayHandlers exposes the player controls under /replay/.
+func registerReplayHandlers(p *tickreplay.Player, day time.Time) {
+	status := func(w http.ResponseWriter) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(p.Status())
+	}
+	control := func(apply func(r *http.Request) error) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost {
+				http.Error(w, `{"error":"POST required"}`, http.StatusMethodNotAllowed)
+				return
+			}
+			if err := apply(r); err != nil {
+				http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusBadRequest)
+				return
+			}
+			status(w)
+		}
+	}
+
+	http.HandleFunc("/replay/status", func(w http.ResponseWriter, _ *http.Request) { status(w) })
+	http.HandleFunc("/replay/pause", control(func(*http.Request) error { p.Pause(); return nil }))
+	http.HandleFunc("/replay/resume", control(func(*http.Request) error { p.Resume(); return nil }))
+	http.HandleFunc("/replay/speed", control(func(r *http.Request) error {
+		x, err := strconv.ParseFloat(r.URL.Query().Get("x"), 64)
+		if err != nil {
+			return errInvalid("x")
+		}
+		return p.SetSpeed(x)
+	}))
+	http.HandleFunc("/replay/seek", control(func(r *http.Request) error {
+		ts, err := parseSeekTS(r.URL.Query().Get("ts"), day)
+		if err != nil {
+			return errInvalid("ts")
+		}
+		p.Seek(ts)
+		return nil
+	}))
+}
+
+// parseSeekTS parses an IST wall-clock time on day ("10:30", "10:30:15") or an RFC3339 timestamp.
+func parseSeekTS(s string, day time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	layout := "15:04"
+	if strings.Count(s, ":") == 2 {
+		layout = "15:04:05"
+	}
+	clock, err := time.Parse(layout, s)
+	if err != nil {
+		return time.Time{}, err
+	}
+	y, m, d := day.In(markethours.IST).Date()
+	return time.Date(y, m, d, clock.Hour(), clock.Minute(), clock.Second(), 0, markethours.IST), nil
+}
+
+type errInvalid string
+
+func (e errInvalid) Error() string { return "invalid or missing " + string(e) }
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"time"
+
+	"trading-systemv1/internal/marketdata/scenario"
+)
+
+// ─── Scenario mode ────────────────────────────────────────────────────────────
+//
+// TICK_MODE=scenario plays a scripted, seeded session (see package scenario
+// and scenarios/*.json) instead of the random walk:
+//
+//	SCENARIO_FILE   — scenario JSON (required); its instruments replace TICK_TOKENS
+//	SCENARIO_SPEED  — speed multiplier, 0 = as fast as possible (default: "1")
+//
+// A scenario without "start" begins at the current second. The /replay/
+// controls work the same as in replay mode.
+
+// startScenario generates the scenario's ticks and starts playback into the hub.
+func startScenario(ctx context.Context, h *hub) {
+	path := envOrDefault("SCENARIO_FILE", "")
+	if path == "" {
+		log.Fatalf("[tickserver] SCENARIO_FILE is required in scenario mode")
+	}
+	s, err := scenario.Load(path)
+	if err != nil {
+		log.Fatalf("[tickserver] %v", err)
+	}
+	speed, err := strconv.ParseFloat(envOrDefault("SCENARIO_SPEED", "1"), 64)
+	if err != nil || speed < 0 {
+		log.Fatalf("[tickserver] invalid SCENARIO_SPEED %q", envOrDefault("SCENARIO_SPEED", ""))
+	}
+	if s.Start.IsZero() {
+		s.Start = time.Now().Truncate(time.Second)
+	}
+
+	ticks := scenario.Generate(s)
+	if len(ticks) == 0 {
+		log.Fatalf("[tickserver] scenario %s produces no ticks", path)
+	}
+	log.Printf("[tickserver] scenario %s: seed=%d, %d instruments, %d phases",
+		path, s.Seed, len(s.Instruments), len(s.Phases))
+	play(ctx, h, ticks, speed, s.Start)
+}
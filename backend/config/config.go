@@ -5,6 +5,10 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
+
+	"trading-systemv1/internal/markethours"
+	smartconnect "trading-systemv1/pkg/smartconnect"
 )
 
 // Config holds all application configuration loaded from environment variables.
@@ -23,9 +27,30 @@ type Config struct {
 
 	// Subscription
 	SubscribeTokens string
+	SubscribeMode   int  // smartconnect mode for ticks: LTP, Quote or SnapQuote
+	SubscribeDepth  bool // also subscribe the tokens in Depth mode
 
 	// Dynamic Timeframes (comma-separated seconds, e.g. "60,300,900")
 	EnabledTFs string
+
+	// Derive Heikin-Ashi candles for every enabled TF
+	HeikinAshi bool
+
+	// How far behind the watermark a late tick still amends its finalized
+	// candles (0 = late ticks are dropped)
+	CandleAmendWindow time.Duration
+
+	// Instruments whose empty seconds get flat synthetic candles: "all" or
+	// "exchange:token" keys, comma-separated ("" = none)
+	GapFill string
+
+	// Longest run of empty seconds gap filling backfills at once (0 = no cap)
+	GapFillMax time.Duration
+
+	// Raw tick archive (off by default)
+	TickArchive       bool
+	TickArchivePath   string
+	TickRetentionDays int
 }
 
 // Load reads configuration from environment variables with sensible defaults.
@@ -43,9 +68,21 @@ func Load() *Config {
 
 		// Default: NIFTY 50 on NSE_CM
 		SubscribeTokens: getEnv("SUBSCRIBE_TOKENS", "1:99926000"),
+		SubscribeMode:   ParseSubscribeMode(getEnv("SUBSCRIBE_MODE", "ltp")),
+		SubscribeDepth:  ParseBool(getEnv("SUBSCRIBE_DEPTH", "false")),
 
 		// Default TFs: 1m, 5m, 15m
 		EnabledTFs: getEnv("ENABLED_TFS", "60,120,180,300"),
+
+		HeikinAshi: HeikinAshiEnabled(),
+
+		CandleAmendWindow: ParseDuration(getEnv("CANDLE_AMEND_WINDOW", "0")),
+		GapFill:           getEnv("GAP_FILL", ""),
+		GapFillMax:        ParseDuration(getEnv("GAP_FILL_MAX", "30m")),
+
+		TickArchive:       ParseBool(getEnv("TICK_ARCHIVE", "false")),
+		TickArchivePath:   getEnv("TICK_ARCHIVE_PATH", "data/ticks.db"),
+		TickRetentionDays: atoi(getEnv("TICK_RETENTION_DAYS", "30")),
 	}
 }
 
@@ -58,8 +95,8 @@ func (c *Config) ParseTFs() []int {
 		if p == "" {
 			continue
 		}
-		n, err := strconv.Atoi(p)
-		if err != nil || n <= 0 {
+		n, err := markethours.ParseTF(p)
+		if err != nil {
 			log.Printf("[config] skipping invalid TF value: %q", p)
 			continue
 		}
@@ -68,6 +105,82 @@ func (c *Config) ParseTFs() []int {
 	return tfs
 }
 
+// HeikinAshiEnabled reports whether HEIKIN_ASHI turns on the Heikin-Ashi
+// series (off by default). Services that run without Load (mdengine in
+// staging, indengine) read the flag here too, so the default lives in one place.
+func HeikinAshiEnabled() bool {
+	return ParseBool(getEnv("HEIKIN_ASHI", "false"))
+}
+
+// ParseBool reports whether an env flag value is enabled ("true", "1", "yes", "on").
+func ParseBool(s string) bool {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "true", "1", "yes", "on":
+		return true
+	}
+	return false
+}
+
+// ParseSubscribeMode maps "ltp", "quote" or "snapquote" to the smartconnect
+// mode, defaulting to LTP. Depth is subscribed separately (SUBSCRIBE_DEPTH)
+// because its packets carry no LTP.
+func ParseSubscribeMode(s string) int {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "ltp", "":
+		return smartconnect.ModeLTP
+	case "quote":
+		return smartconnect.ModeQuote
+	case "snapquote", "snap_quote":
+		return smartconnect.ModeSnapQuote
+	}
+	log.Printf("[config] invalid SUBSCRIBE_MODE %q, using ltp", s)
+	return smartconnect.ModeLTP
+}
+
+// ParseDuration parses a non-negative duration such as "30s" or "2m".
+// Empty, "0" and invalid values give 0.
+func ParseDuration(s string) time.Duration {
+	s = strings.TrimSpace(s)
+	if s == "" || s == "0" {
+		return 0
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		log.Printf("[config] invalid duration %q, using 0", s)
+		return 0
+	}
+	return d
+}
+
+// ParseGapFill parses the gap-fill policy: "all", or "exchange:token" keys
+// such as "NSE:2885,NFO:43650". It returns the predicate selecting the
+// instruments to fill, or nil if none are.
+func ParseGapFill(s string) func(key string) bool {
+	s = strings.TrimSpace(s)
+	switch strings.ToLower(s) {
+	case "", "none", "false", "off":
+		return nil
+	case "all", "true", "on":
+		return func(string) bool { return true }
+	}
+	keys := make(map[string]bool)
+	for _, k := range strings.Split(s, ",") {
+		if k = strings.TrimSpace(k); k != "" {
+			keys[k] = true
+		}
+	}
+	return func(key string) bool { return keys[key] }
+}
+
+// atoi parses a non-negative int, returning 0 for invalid values.
+func atoi(s string) int {
+	n, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil || n < 0 {
+		return 0
+	}
+	return n
+}
+
 func mustEnv(key string) string {
 	v := os.Getenv(key)
 	if v == "" {
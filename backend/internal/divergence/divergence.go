@@ -0,0 +1,215 @@
+// Package divergence detects price/oscillator divergences on top of the
+// indicator Engine's results.
+//
+// For every token, TF and configured oscillator a Detector tracks swing
+// pivots in price — a bar whose low (high) is below (above) the Left bars
+// before it and not beaten by the Right bars after it — together with the
+// oscillator value at each pivot. Each new pivot is compared with the
+// previous pivot of the same side:
+//
+//	regular bullish: price lower low,   oscillator higher low
+//	hidden bullish:  price higher low,  oscillator lower low
+//	regular bearish: price higher high, oscillator lower high
+//	hidden bearish:  price lower high,  oscillator higher high
+//
+// A pivot is only confirmed Right bars after it forms, so events are
+// emitted with that delay and never repainted.
+package divergence
+
+import (
+	"time"
+
+	"trading-systemv1/internal/model"
+)
+
+// Config configures a Detector. Zero fields take the defaults noted.
+type Config struct {
+	// Oscillators are indicator result names, e.g. "RSI_14" or
+	// "MACD_12_26_9" (multi-output indicators use their first component).
+	// They must be computed by the engine for the token and TF.
+	Oscillators []string
+
+	Left  int // bars before a pivot that it must beat (default 5)
+	Right int // bars after a pivot that must not beat it (default 3)
+
+	MinBars int // minimum bars between the two pivots (default 5)
+	MaxBars int // maximum bars between the two pivots (default 60)
+}
+
+func (c Config) withDefaults() Config {
+	if c.Left <= 0 {
+		c.Left = 5
+	}
+	if c.Right <= 0 {
+		c.Right = 3
+	}
+	if c.MinBars <= 0 {
+		c.MinBars = 5
+	}
+	if c.MaxBars <= 0 {
+		c.MaxBars = 60
+	}
+	return c
+}
+
+// bar is one candle with the oscillator value at its close.
+type bar struct {
+	idx  int // position in the series
+	ts   time.Time
+	high int64
+	low  int64
+	osc  float64
+}
+
+// pivot is a confirmed swing point: its bar and the pivot price (the low
+// for swing lows, the high for swing highs).
+type pivot struct {
+	bar
+	price int64
+}
+
+// series tracks one token, TF and oscillator.
+type series struct {
+	n      int   // bars seen
+	window []bar // last Left+Right+1 bars, oldest first
+	low    *pivot
+	high   *pivot
+}
+
+// Detector emits divergence events from finalized candles and their
+// indicator results. Designed for single-goroutine usage — no locks needed.
+type Detector struct {
+	cfg Config
+
+	// series["TF:exchange:token:oscillator"] → pivot tracking state
+	series map[string]*series
+}
+
+// NewDetector creates a divergence detector.
+func NewDetector(cfg Config) *Detector {
+	return &Detector{
+		cfg:    cfg.withDefaults(),
+		series: make(map[string]*series, 64),
+	}
+}
+
+// Process takes a finalized TF candle and the engine's results for it and
+// returns any divergences confirmed on this candle. Oscillators without a
+// ready result for the candle are skipped.
+func (d *Detector) Process(tfc model.TFCandle, results []model.IndicatorResult) []model.DivergenceEvent {
+	if tfc.Forming {
+		return nil
+	}
+
+	var events []model.DivergenceEvent
+	for _, osc := range d.cfg.Oscillators {
+		value, ok := oscillatorValue(results, osc)
+		if !ok {
+			continue
+		}
+		key := model.Itoa(tfc.TF) + ":" + tfc.Key() + ":" + osc
+		s, exists := d.series[key]
+		if !exists {
+			s = &series{window: make([]bar, 0, d.cfg.Left+d.cfg.Right+1)}
+			d.series[key] = s
+		}
+		events = append(events, d.update(s, osc, tfc, value)...)
+	}
+	return events
+}
+
+// oscillatorValue finds a ready result by name.
+func oscillatorValue(results []model.IndicatorResult, name string) (float64, bool) {
+	for i := range results {
+		if results[i].Name == name {
+			return results[i].Value, results[i].Ready
+		}
+	}
+	return 0, false
+}
+
+// update pushes a bar and checks the bar Right bars back for a pivot.
+func (d *Detector) update(s *series, osc string, tfc model.TFCandle, value float64) []model.DivergenceEvent {
+	size := d.cfg.Left + d.cfg.Right + 1
+	if len(s.window) == size {
+		copy(s.window, s.window[1:])
+		s.window = s.window[:size-1]
+	}
+	s.window = append(s.window, bar{idx: s.n, ts: tfc.TS, high: tfc.High, low: tfc.Low, osc: value})
+	s.n++
+	if len(s.window) < size {
+		return nil
+	}
+
+	var events []model.DivergenceEvent
+	cand := s.window[d.cfg.Left]
+	if d.isPivot(s.window, func(b bar) int64 { return -b.low }) {
+		p := &pivot{bar: cand, price: cand.low}
+		if kind, ok := d.compare(s.low, p, false); ok {
+			events = append(events, newEvent(kind, osc, tfc, s.low, p))
+		}
+		s.low = p
+	}
+	if d.isPivot(s.window, func(b bar) int64 { return b.high }) {
+		p := &pivot{bar: cand, price: cand.high}
+		if kind, ok := d.compare(s.high, p, true); ok {
+			events = append(events, newEvent(kind, osc, tfc, s.high, p))
+		}
+		s.high = p
+	}
+	return events
+}
+
+// isPivot reports whether the window's candidate bar is a strict maximum of
+// key over the Left bars before it and not exceeded by the Right bars after.
+func (d *Detector) isPivot(w []bar, key func(bar) int64) bool {
+	c := key(w[d.cfg.Left])
+	for i, b := range w {
+		switch {
+		case i < d.cfg.Left && key(b) >= c:
+			return false
+		case i > d.cfg.Left && key(b) > c:
+			return false
+		}
+	}
+	return true
+}
+
+// compare classifies a new pivot against the previous one on the same side.
+func (d *Detector) compare(prev, cur *pivot, high bool) (string, bool) {
+	if prev == nil {
+		return "", false
+	}
+	if dist := cur.idx - prev.idx; dist < d.cfg.MinBars || dist > d.cfg.MaxBars {
+		return "", false
+	}
+	o1, o2 := prev.osc, cur.osc
+	switch {
+	case !high && cur.price < prev.price && o2 > o1:
+		return model.DivRegularBullish, true
+	case !high && cur.price > prev.price && o2 < o1:
+		return model.DivHiddenBullish, true
+	case high && cur.price > prev.price && o2 < o1:
+		return model.DivRegularBearish, true
+	case high && cur.price < prev.price && o2 > o1:
+		return model.DivHiddenBearish, true
+	}
+	return "", false
+}
+
+func newEvent(kind, osc string, tfc model.TFCandle, p1, p2 *pivot) model.DivergenceEvent {
+	return model.DivergenceEvent{
+		Kind:       kind,
+		Oscillator: osc,
+		Token:      tfc.Token,
+		Exchange:   tfc.Exchange,
+		TF:         tfc.TF,
+		TS:         tfc.TS,
+		Pivot1TS:   p1.ts,
+		Pivot2TS:   p2.ts,
+		Price1:     p1.price,
+		Price2:     p2.price,
+		Osc1:       p1.osc,
+		Osc2:       p2.osc,
+	}
+}
@@ -0,0 +1,110 @@
+package divergence
+
+import (
+	"testing"
+	"time"
+
+	"trading-systemv1/internal/model"
+)
+
+var base = time.Date(2026, 1, 5, 3, 45, 0, 0, time.UTC)
+
+// feed runs lows (in paise, highs = low+500) with matching RSI values through
+// a detector and returns every event.
+func feed(d *Detector, lows []int64, rsi []float64, high bool) []model.DivergenceEvent {
+	var events []model.DivergenceEvent
+	for i := range lows {
+		c := model.TFCandle{
+			Token: "99926000", Exchange: "NSE", TF: 60,
+			TS:   base.Add(time.Duration(i) * time.Minute),
+			Low:  lows[i],
+			High: lows[i] + 500,
+		}
+		if high {
+			// Mirror the series so lows become highs.
+			c.High = 20000 - lows[i]
+			c.Low = c.High - 500
+		}
+		results := []model.IndicatorResult{{Name: "RSI_14", Value: rsi[i], Ready: true}}
+		events = append(events, d.Process(c, results)...)
+	}
+	return events
+}
+
+func testConfig() Config {
+	return Config{Oscillators: []string{"RSI_14"}, Left: 2, Right: 2, MinBars: 3, MaxBars: 20}
+}
+
+// Two swing lows at bars 2 and 7.
+var lows = []int64{10000, 9800, 9500, 9700, 9900, 10000, 9800, 9300, 9600, 9900, 10000}
+
+func TestDivergence_RegularBullish(t *testing.T) {
+	rsi := []float64{40, 35, 30, 38, 45, 50, 42, 35, 40, 48, 52}
+	events := feed(NewDetector(testConfig()), lows, rsi, false)
+
+	if len(events) != 1 {
+		t.Fatalf("expected 1 event, got %+v", events)
+	}
+	e := events[0]
+	if e.Kind != model.DivRegularBullish || e.Oscillator != "RSI_14" {
+		t.Errorf("got %s on %s, want regular_bullish on RSI_14", e.Kind, e.Oscillator)
+	}
+	if !e.Pivot1TS.Equal(base.Add(2*time.Minute)) || !e.Pivot2TS.Equal(base.Add(7*time.Minute)) {
+		t.Errorf("pivots %s / %s, want bars 2 / 7", e.Pivot1TS, e.Pivot2TS)
+	}
+	if !e.TS.Equal(base.Add(9 * time.Minute)) {
+		t.Errorf("confirmed at %s, want bar 9 (Right=2 after the pivot)", e.TS)
+	}
+	if e.Price1 != 9500 || e.Price2 != 9300 || e.Osc1 != 30 || e.Osc2 != 35 {
+		t.Errorf("unexpected pivot values: %+v", e)
+	}
+}
+
+func TestDivergence_HiddenBullish(t *testing.T) {
+	higherLows := append([]int64(nil), lows...)
+	higherLows[7] = 9600 // price higher low
+	higherLows[8] = 9700
+	rsi := []float64{40, 35, 30, 38, 45, 50, 42, 25, 40, 48, 52}
+	events := feed(NewDetector(testConfig()), higherLows, rsi, false)
+	if len(events) != 1 || events[0].Kind != model.DivHiddenBullish {
+		t.Fatalf("expected hidden_bullish, got %+v", events)
+	}
+}
+
+func TestDivergence_RegularBearish(t *testing.T) {
+	// Mirrored: swing highs at bars 2 and 7, second one higher, RSI lower.
+	rsi := []float64{60, 65, 70, 62, 55, 50, 58, 65, 60, 52, 48}
+	events := feed(NewDetector(testConfig()), lows, rsi, true)
+	if len(events) != 1 || events[0].Kind != model.DivRegularBearish {
+		t.Fatalf("expected regular_bearish, got %+v", events)
+	}
+}
+
+func TestDivergence_NoEventWhenConfirming(t *testing.T) {
+	// Lower low with lower RSI low: trend confirmed, no divergence.
+	rsi := []float64{40, 35, 30, 38, 45, 50, 42, 25, 40, 48, 52}
+	if events := feed(NewDetector(testConfig()), lows, rsi, false); len(events) != 0 {
+		t.Errorf("expected no events, got %+v", events)
+	}
+}
+
+func TestDivergence_PivotDistance(t *testing.T) {
+	cfg := testConfig()
+	cfg.MaxBars = 4 // pivots are 5 bars apart
+	rsi := []float64{40, 35, 30, 38, 45, 50, 42, 35, 40, 48, 52}
+	if events := feed(NewDetector(cfg), lows, rsi, false); len(events) != 0 {
+		t.Errorf("expected pivots too far apart to be ignored, got %+v", events)
+	}
+}
+
+func TestDivergence_SkipsMissingOscillator(t *testing.T) {
+	d := NewDetector(Config{Oscillators: []string{"MACD_12_26_9"}})
+	c := model.TFCandle{Token: "1", Exchange: "NSE", TF: 60, TS: base}
+	results := []model.IndicatorResult{{Name: "RSI_14", Value: 50, Ready: true}}
+	if events := d.Process(c, results); events != nil {
+		t.Errorf("expected nil, got %+v", events)
+	}
+	if len(d.series) != 0 {
+		t.Error("no series should be tracked without the oscillator")
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"strconv"
 	"testing"
 	"time"
+
+	"trading-systemv1/internal/model"
 )
 
 // buildEnvelope reproduces the exact hand-crafted JSON logic from Broadcaster.Broadcast
@@ -135,6 +137,16 @@ func TestChannelParsing(t *testing.T) {
 		{"indicator_SMA", "pub:ind:SMA_9:60s:NSE:99926000", 60, "SMA_9", false},
 		{"indicator_RSI", "pub:ind:RSI_14:120s:NSE:99926000", 120, "RSI_14", false},
 		{"indicator_EMA", "pub:ind:EMA_21:300s:NSE:99926000", 300, "EMA_21", false},
+		{"pattern_DOJI", "pub:pattern:DOJI:300s:NSE:99926000", 300, "DOJI", false},
+		{"divergence", "pub:div:120s:NSE:99926000", 120, "", false},
+		{"oi", "pub:oi:300s:NFO:43650", 300, "", false},
+		{"amend", "pub:amend:60s:NSE:99926000", 60, "", false},
+		{"amend_1s", "pub:amend:1s:NSE:99926000", 1, "", false},
+		{"levels", "pub:levels:NSE:99926000", 0, "", false},
+		{"quote", "pub:quote:NFO:43650", 0, "", false},
+		{"depth", "pub:depth:NFO:43650", 0, "", false},
+		{"candle_ha", "pub:candle:ha60s:NSE:99926000", model.HATF(60), "", false},
+		{"indicator_ha", "pub:ind:SMA_9:ha300s:NSE:99926000", model.HATF(300), "SMA_9", false},
 		{"invalid_garbage", "garbage", 0, "", true},
 		{"invalid_short", "pub:candle", 0, "", true},
 		{"tick_channel", "pub:tick:NSE:99926000", 0, "", false},
@@ -162,6 +174,30 @@ func TestChannelParsing(t *testing.T) {
 	}
 }
 
+// TestMatchesChannel_MarketDataOptIn verifies quote and depth channels
+// reach only subscriptions that asked for them.
+func TestMatchesChannel_MarketDataOptIn(t *testing.T) {
+	c := &Client{subs: map[string]*ClientSubscription{
+		"NSE:2885:60": {Symbol: "NSE:2885", TF: 60, Quote: true},
+		"NSE:1594:60": {Symbol: "NSE:1594", TF: 60},
+	}}
+	tests := []struct {
+		channel string
+		want    bool
+	}{
+		{"pub:quote:NSE:2885", true},
+		{"pub:depth:NSE:2885", false},
+		{"pub:quote:NSE:1594", false},
+		{"pub:levels:NSE:1594", true},
+		{"pub:quote:NSE:11536", false},
+	}
+	for _, tt := range tests {
+		if got := c.matchesChannel(tt.channel); got != tt.want {
+			t.Errorf("matchesChannel(%q) = %v, want %v", tt.channel, got, tt.want)
+		}
+	}
+}
+
 // TestEnvelopeSeqMonotonic verifies sequence numbers are reflected correctly.
 func TestEnvelopeSeqMonotonic(t *testing.T) {
 	channel := "pub:candle:60s:NSE:99926000"
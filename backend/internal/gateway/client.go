@@ -4,10 +4,13 @@ import (
 	"context"
 	"encoding/json"
 	"log"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"trading-systemv1/internal/model"
+
 	"github.com/gorilla/websocket"
 )
 
@@ -176,15 +179,42 @@ func (c *Client) handleSubscribe(msg SubscribeMsg) {
 		SendError(c, msg.ReqID, "symbol and tf are required")
 		return
 	}
+	for _, spec := range msg.Indicators {
+		if err := ValidateIndicatorSpec(spec); err != nil {
+			SendError(c, msg.ReqID, "invalid indicator: "+err.Error())
+			return
+		}
+	}
+
+	var wantQuote, wantDepth bool
+	for _, md := range msg.MarketData {
+		switch strings.ToLower(md) {
+		case MarketDataQuote:
+			wantQuote = true
+		case MarketDataDepth:
+			wantDepth = true
+		default:
+			SendError(c, msg.ReqID, "unknown marketData "+strconv.Quote(md))
+			return
+		}
+	}
+
+	seriesTF, err := SeriesTF(msg.TF, msg.CandleType)
+	if err != nil {
+		SendError(c, msg.ReqID, err.Error())
+		return
+	}
 
 	// Resolve indicator entries with composite (name, tf) identity
-	indEntries := ResolveIndEntries(msg.Indicators, msg.TF)
+	indEntries := ResolveIndEntries(msg.Indicators, seriesTF)
 
 	sub := &ClientSubscription{
 		Symbol:     msg.Symbol,
-		TF:         msg.TF,
+		TF:         seriesTF,
 		Indicators: msg.Indicators,
 		IndEntries: indEntries,
+		Quote:      wantQuote,
+		Depth:      wantDepth,
 	}
 
 	// Store subscription
@@ -199,8 +229,8 @@ func (c *Client) handleSubscribe(msg SubscribeMsg) {
 	for i, e := range indEntries {
 		indNames[i] = e.Key()
 	}
-	log.Printf("[subscribe] client subscribed: symbol=%s tf=%d indicators=%v",
-		msg.Symbol, msg.TF, indNames)
+	log.Printf("[subscribe] client subscribed: symbol=%s tf=%s indicators=%v",
+		msg.Symbol, model.TFKey(seriesTF), indNames)
 
 	// Check if indengine needs new indicators
 	ctx := context.Background()
@@ -232,18 +262,22 @@ func (c *Client) handleSubscribe(msg SubscribeMsg) {
 	snap.ReqID = msg.ReqID
 
 	SendJSON(c, snap)
-	log.Printf("[subscribe] sent snapshot: symbol=%s tf=%d candles=%d indicators=%d",
-		msg.Symbol, msg.TF, len(snap.Candles), len(snap.Indicators))
+	log.Printf("[subscribe] sent snapshot: symbol=%s tf=%s candles=%d indicators=%d",
+		msg.Symbol, model.TFKey(seriesTF), len(snap.Candles), len(snap.Indicators))
 }
 
 // handleUnsubscribe removes a subscription.
 func (c *Client) handleUnsubscribe(msg UnsubscribeMsg) {
-	sub := &ClientSubscription{Symbol: msg.Symbol, TF: msg.TF}
+	tf, err := SeriesTF(msg.TF, msg.CandleType)
+	if err != nil {
+		return
+	}
+	sub := &ClientSubscription{Symbol: msg.Symbol, TF: tf}
 	c.subMu.Lock()
 	delete(c.subs, sub.SubKey())
 	c.subMu.Unlock()
 
-	log.Printf("[subscribe] client unsubscribed: symbol=%s tf=%d", msg.Symbol, msg.TF)
+	log.Printf("[subscribe] client unsubscribed: symbol=%s tf=%s", msg.Symbol, model.TFKey(tf))
 }
 
 // matchesChannel checks if a PubSub channel matches any of this client's subscriptions.
@@ -267,13 +301,21 @@ func (c *Client) matchesChannel(channel string) bool {
 		if sub.Symbol != symbol {
 			continue
 		}
-		// Candle channel — must match subscription's main TF
-		if parsed.chType == "candle" {
+		// Candle, amend, pattern, divergence and OI channels — must match subscription's main TF
+		if parsed.chType == "candle" || parsed.chType == "amend" || parsed.chType == "pattern" || parsed.chType == "divergence" || parsed.chType == "oi" {
 			if sub.TF == parsed.tf {
 				return true
 			}
 			continue
 		}
+		// Session levels apply to every TF of the symbol
+		if parsed.chType == "levels" {
+			return true
+		}
+		// Quote and depth are opt-in per subscription
+		if parsed.chType == "quote" && sub.Quote || parsed.chType == "depth" && sub.Depth {
+			return true
+		}
 		// Indicator channel — check against IndEntries by both name AND TF
 		if parsed.chType == "indicator" {
 			for _, entry := range sub.IndEntries {
@@ -288,15 +330,15 @@ func (c *Client) matchesChannel(channel string) bool {
 
 // parsedChannel holds the parsed components of a Redis PubSub channel name.
 type parsedChannel struct {
-	chType   string // "candle", "indicator", "tick"
-	indName  string // for indicator channels: "SMA_9", "EMA_4"
+	chType   string // "candle", "amend", "indicator", "pattern", "divergence", "oi", "levels", "quote", "depth", "tick"
+	indName  string // for indicator and pattern channels: "SMA_9", "DOJI"
 	tf       int    // timeframe in seconds
 	exchange string // "NSE"
 	token    string // "99926000"
 }
 
-// parseChannel parses a PubSub channel like "pub:candle:60s:NSE:99926000"
-// or "pub:ind:SMA_9:60s:NSE:99926000".
+// parseChannel parses a PubSub channel like "pub:candle:60s:NSE:99926000",
+// "pub:ind:SMA_9:60s:NSE:99926000" or "pub:pattern:DOJI:60s:NSE:99926000".
 func parseChannel(channel string) *parsedChannel {
 	parts := strings.Split(channel, ":")
 	if len(parts) < 4 {
@@ -314,6 +356,36 @@ func parseChannel(channel string) *parsedChannel {
 		}
 	}
 
+	// pub:amend:60s:NSE:99926000  (5 parts): a finalized candle revised by late ticks
+	if parts[0] == "pub" && parts[1] == "amend" && len(parts) >= 5 {
+		return &parsedChannel{
+			chType:   "amend",
+			tf:       parseTFStr(parts[2]),
+			exchange: parts[3],
+			token:    parts[4],
+		}
+	}
+
+	// pub:div:60s:NSE:99926000  (5 parts)
+	if parts[0] == "pub" && parts[1] == "div" && len(parts) >= 5 {
+		return &parsedChannel{
+			chType:   "divergence",
+			tf:       parseTFStr(parts[2]),
+			exchange: parts[3],
+			token:    parts[4],
+		}
+	}
+
+	// pub:oi:60s:NFO:43650  (5 parts)
+	if parts[0] == "pub" && parts[1] == "oi" && len(parts) >= 5 {
+		return &parsedChannel{
+			chType:   "oi",
+			tf:       parseTFStr(parts[2]),
+			exchange: parts[3],
+			token:    parts[4],
+		}
+	}
+
 	// pub:ind:SMA_9:60s:NSE:99926000  (6 parts)
 	if parts[0] == "pub" && parts[1] == "ind" && len(parts) >= 6 {
 		tf := parseTFStr(parts[3])
@@ -326,6 +398,36 @@ func parseChannel(channel string) *parsedChannel {
 		}
 	}
 
+	// pub:pattern:DOJI:60s:NSE:99926000  (6 parts)
+	if parts[0] == "pub" && parts[1] == "pattern" && len(parts) >= 6 {
+		tf := parseTFStr(parts[3])
+		return &parsedChannel{
+			chType:   "pattern",
+			indName:  parts[2],
+			tf:       tf,
+			exchange: parts[4],
+			token:    parts[5],
+		}
+	}
+
+	// pub:levels:NSE:99926000  (4 parts)
+	if parts[0] == "pub" && parts[1] == "levels" {
+		return &parsedChannel{
+			chType:   "levels",
+			exchange: parts[2],
+			token:    parts[3],
+		}
+	}
+
+	// pub:quote:NSE:99926000, pub:depth:NSE:99926000  (4 parts)
+	if parts[0] == "pub" && (parts[1] == "quote" || parts[1] == "depth") {
+		return &parsedChannel{
+			chType:   parts[1],
+			exchange: parts[2],
+			token:    parts[3],
+		}
+	}
+
 	// pub:tick:NSE:99926000  (4 parts)
 	if parts[0] == "pub" && parts[1] == "tick" && len(parts) >= 4 {
 		return &parsedChannel{
@@ -338,14 +440,8 @@ func parseChannel(channel string) *parsedChannel {
 	return nil
 }
 
-// parseTFStr parses "60s" → 60.
+// parseTFStr parses "60s" → 60, and bar keys like "tick500" to their TF.
 func parseTFStr(s string) int {
-	s = strings.TrimSuffix(s, "s")
-	n := 0
-	for _, ch := range s {
-		if ch >= '0' && ch <= '9' {
-			n = n*10 + int(ch-'0')
-		}
-	}
-	return n
+	tf, _ := model.ParseTFKey(s)
+	return tf
 }
@@ -22,8 +22,10 @@ type CandleOut struct {
 }
 
 // IndPoint is the REST response type for /api/indicators/history.
+// Components is set for multi-output indicators (e.g. MACD macd/signal/hist).
 type IndPoint struct {
-	Value float64 `json:"value"`
-	TS    string  `json:"ts"`
-	Ready bool    `json:"ready"`
+	Value      float64            `json:"value"`
+	TS         string             `json:"ts"`
+	Ready      bool               `json:"ready"`
+	Components map[string]float64 `json:"components,omitempty"`
 }
@@ -11,6 +11,9 @@ import (
 	"strings"
 	"time"
 
+	"trading-systemv1/internal/markethours"
+	"trading-systemv1/internal/model"
+
 	goredis "github.com/go-redis/redis/v8"
 	"github.com/gorilla/websocket"
 )
@@ -140,9 +143,7 @@ func RegisterRoutes(mux *http.ServeMux, hub *Hub, rdb *goredis.Client, ctx conte
 			seen := make(map[string]bool)
 			var specs []string
 			for _, entry := range req.Entries {
-				parts := strings.SplitN(entry.Name, "_", 2)
-				if len(parts) == 2 {
-					spec := parts[0] + ":" + parts[1]
+				if spec := IndicatorNameToConfig(entry.Name); spec != "" {
 					if !seen[spec] {
 						seen[spec] = true
 						specs = append(specs, spec)
@@ -190,7 +191,7 @@ func RegisterRoutes(mux *http.ServeMux, hub *Hub, rdb *goredis.Client, ctx conte
 		if tfStr == "" {
 			tfStr = "60"
 		}
-		tfVal, _ := strconv.Atoi(tfStr)
+		tfVal, _ := markethours.ParseTF(tfStr)
 		if tfVal <= 0 {
 			tfVal = 60
 		}
@@ -206,7 +207,7 @@ func RegisterRoutes(mux *http.ServeMux, hub *Hub, rdb *goredis.Client, ctx conte
 			token = tokenKeys[0]
 		}
 
-		streamKey := fmt.Sprintf("candle:%ds:%s", tfVal, token)
+		streamKey := "candle:" + model.TFKey(tfVal) + ":" + token
 
 		upperBound := "+"
 		if beforeStr != "" {
@@ -261,7 +262,7 @@ func RegisterRoutes(mux *http.ServeMux, hub *Hub, rdb *goredis.Client, ctx conte
 			json.NewEncoder(w).Encode([]interface{}{})
 			return
 		}
-		tfVal, _ := strconv.Atoi(tfStr)
+		tfVal, _ := markethours.ParseTF(tfStr)
 		if tfVal <= 0 {
 			tfVal = 60
 		}
@@ -275,7 +276,7 @@ func RegisterRoutes(mux *http.ServeMux, hub *Hub, rdb *goredis.Client, ctx conte
 			token = tokenKeys[0]
 		}
 
-		streamKey := fmt.Sprintf("ind:%s:%ds:%s", name, tfVal, token)
+		streamKey := "ind:" + name + ":" + model.TFKey(tfVal) + ":" + token
 
 		upperBound := "+"
 		if beforeStr := r.URL.Query().Get("before"); beforeStr != "" {
@@ -303,21 +304,170 @@ func RegisterRoutes(mux *http.ServeMux, hub *Hub, rdb *goredis.Client, ctx conte
 				continue
 			}
 			var p struct {
-				Value float64 `json:"value"`
-				TS    string  `json:"ts"`
-				Ready bool    `json:"ready"`
+				Value      float64            `json:"value"`
+				TS         string             `json:"ts"`
+				Ready      bool               `json:"ready"`
+				Components map[string]float64 `json:"components"`
 			}
 			if err := json.Unmarshal([]byte(dataStr), &p); err != nil {
 				continue
 			}
 			if p.Ready && p.TS != "" {
-				points = append(points, IndPoint{Value: p.Value, TS: p.TS, Ready: p.Ready})
+				points = append(points, IndPoint{Value: p.Value, TS: p.TS, Ready: p.Ready, Components: p.Components})
 			}
 		}
 
 		json.NewEncoder(w).Encode(points)
 	})
 
+	// REST: price/oscillator divergence events from Redis streams
+	mux.HandleFunc("/api/divergences", func(w http.ResponseWriter, r *http.Request) {
+		SetCORS(w)
+		w.Header().Set("Content-Type", "application/json")
+
+		q := r.URL.Query()
+		tfVal, _ := markethours.ParseTF(q.Get("tf"))
+		if tfVal <= 0 {
+			tfVal = 60
+		}
+		limit := 100
+		if l, err := strconv.Atoi(q.Get("limit")); err == nil && l > 0 && l <= 1000 {
+			limit = l
+		}
+		token := q.Get("token")
+		if token == "" && len(tokenKeys) > 0 {
+			token = tokenKeys[0]
+		}
+		kind, oscillator := q.Get("kind"), q.Get("oscillator")
+
+		streamKey := "div:" + model.TFKey(tfVal) + ":" + token
+		msgs, err := rdb.XRevRangeN(ctx, streamKey, "+", "-", int64(limit)).Result()
+		if err != nil {
+			json.NewEncoder(w).Encode([]interface{}{})
+			return
+		}
+
+		// Chronological order; kind/oscillator filters are optional
+		events := make([]model.DivergenceEvent, 0, len(msgs))
+		for i := len(msgs) - 1; i >= 0; i-- {
+			dataStr, ok := msgs[i].Values["data"].(string)
+			if !ok {
+				continue
+			}
+			var ev model.DivergenceEvent
+			if err := json.Unmarshal([]byte(dataStr), &ev); err != nil {
+				continue
+			}
+			if (kind != "" && ev.Kind != kind) || (oscillator != "" && ev.Oscillator != oscillator) {
+				continue
+			}
+			events = append(events, ev)
+		}
+
+		json.NewEncoder(w).Encode(events)
+	})
+
+	// REST: open interest analytics (F&O) from Redis Streams
+	mux.HandleFunc("/api/oi", func(w http.ResponseWriter, r *http.Request) {
+		SetCORS(w)
+		w.Header().Set("Content-Type", "application/json")
+
+		q := r.URL.Query()
+		tfVal, _ := markethours.ParseTF(q.Get("tf"))
+		if tfVal <= 0 {
+			tfVal = 60
+		}
+		limit := 100
+		if l, err := strconv.Atoi(q.Get("limit")); err == nil && l > 0 && l <= 1000 {
+			limit = l
+		}
+		token := q.Get("token")
+		if token == "" && len(tokenKeys) > 0 {
+			token = tokenKeys[0]
+		}
+		buildup := q.Get("buildup")
+
+		streamKey := "oi:" + model.TFKey(tfVal) + ":" + token
+		msgs, err := rdb.XRevRangeN(ctx, streamKey, "+", "-", int64(limit)).Result()
+		if err != nil {
+			json.NewEncoder(w).Encode([]interface{}{})
+			return
+		}
+
+		// Chronological order; the buildup filter is optional
+		bars := make([]model.OIBar, 0, len(msgs))
+		for i := len(msgs) - 1; i >= 0; i-- {
+			dataStr, ok := msgs[i].Values["data"].(string)
+			if !ok {
+				continue
+			}
+			var bar model.OIBar
+			if err := json.Unmarshal([]byte(dataStr), &bar); err != nil {
+				continue
+			}
+			if buildup != "" && bar.Buildup != buildup {
+				continue
+			}
+			bars = append(bars, bar)
+		}
+
+		json.NewEncoder(w).Encode(bars)
+	})
+
+	// REST: session levels (pivots, CPR, previous-session HLC) from Redis
+	mux.HandleFunc("/api/levels", func(w http.ResponseWriter, r *http.Request) {
+		SetCORS(w)
+		w.Header().Set("Content-Type", "application/json")
+
+		token := r.URL.Query().Get("token")
+		if token == "" && len(tokenKeys) > 0 {
+			token = tokenKeys[0]
+		}
+
+		lv, err := ReadLevels(ctx, rdb, token)
+		if err != nil || lv == nil {
+			http.Error(w, `{"error":"no levels computed for token"}`, http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(lv)
+	})
+
+	// REST: latest quote (Quote/SnapQuote ingest) from Redis
+	mux.HandleFunc("/api/quote", func(w http.ResponseWriter, r *http.Request) {
+		SetCORS(w)
+		w.Header().Set("Content-Type", "application/json")
+
+		token := r.URL.Query().Get("token")
+		if token == "" && len(tokenKeys) > 0 {
+			token = tokenKeys[0]
+		}
+
+		q, err := ReadQuote(ctx, rdb, token)
+		if err != nil || q == nil {
+			http.Error(w, `{"error":"no quote for token"}`, http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(q)
+	})
+
+	// REST: latest 20-level order book (Depth ingest) from Redis
+	mux.HandleFunc("/api/depth", func(w http.ResponseWriter, r *http.Request) {
+		SetCORS(w)
+		w.Header().Set("Content-Type", "application/json")
+
+		token := r.URL.Query().Get("token")
+		if token == "" && len(tokenKeys) > 0 {
+			token = tokenKeys[0]
+		}
+
+		d, err := ReadDepth(ctx, rdb, token)
+		if err != nil || d == nil {
+			http.Error(w, `{"error":"no depth for token"}`, http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(d)
+	})
+
 	// REST: gap backfill — returns buffered envelopes for a channel between from_seq and to_seq
 	mux.HandleFunc("/api/missed", func(w http.ResponseWriter, r *http.Request) {
 		SetCORS(w)
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"trading-systemv1/internal/markethours"
+	"trading-systemv1/internal/model"
 
 	goredis "github.com/go-redis/redis/v8"
 	"github.com/gorilla/websocket"
@@ -121,14 +122,14 @@ func (h *Hub) buildChannels() []string {
 	for _, ind := range h.Indicators {
 		for _, tf := range h.TFs {
 			for _, tok := range h.Tokens {
-				ch := fmt.Sprintf("pub:ind:%s:%ds:%s", ind, tf, tok)
+				ch := "pub:ind:" + ind + ":" + model.TFKey(tf) + ":" + tok
 				channels = append(channels, ch)
 			}
 		}
 	}
 	for _, tf := range h.TFs {
 		for _, tok := range h.Tokens {
-			ch := fmt.Sprintf("pub:candle:%ds:%s", tf, tok)
+			ch := "pub:candle:" + model.TFKey(tf) + ":" + tok
 			channels = append(channels, ch)
 		}
 	}
@@ -3,13 +3,14 @@ package gateway
 import (
 	"bufio"
 	"context"
-	"fmt"
 	"os"
 	"runtime"
 	"strconv"
 	"strings"
 	"time"
 
+	"trading-systemv1/internal/markethours"
+
 	goredis "github.com/go-redis/redis/v8"
 )
 
@@ -168,12 +169,7 @@ func ReadIndicatorLatency(ctx context.Context, rdb *goredis.Client) (float64, bo
 }
 
 // TFLabel returns a human-readable label for a timeframe in seconds.
+// Calendar TFs are labelled "D", "W" and "M".
 func TFLabel(tf int) string {
-	if tf < 60 {
-		return fmt.Sprintf("%ds", tf)
-	}
-	if tf < 3600 {
-		return fmt.Sprintf("%dm", tf/60)
-	}
-	return fmt.Sprintf("%dh", tf/3600)
+	return markethours.TFLabel(tf)
 }
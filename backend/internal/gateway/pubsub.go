@@ -44,10 +44,11 @@ func (r *PubSubRouter) RunExplicit(ctx context.Context) {
 	}
 }
 
-// RunPattern subscribes to wildcard patterns for dynamic indicator channels.
-// Blocks until ctx is cancelled.
+// RunPattern subscribes to wildcard patterns for dynamic indicator,
+// candlestick pattern, divergence, session level, candle amendment and
+// Heikin-Ashi candle channels. Blocks until ctx is cancelled.
 func (r *PubSubRouter) RunPattern(ctx context.Context) {
-	pubsub := r.hub.Rdb.PSubscribe(ctx, "pub:ind:*", "pub:tick:*")
+	pubsub := r.hub.Rdb.PSubscribe(ctx, "pub:ind:*", "pub:pattern:*", "pub:div:*", "pub:oi:*", "pub:levels:*", "pub:quote:*", "pub:depth:*", "pub:tick:*", "pub:amend:*", "pub:candle:ha*")
 	defer pubsub.Close()
 
 	ch := pubsub.Channel()
@@ -10,6 +10,9 @@ import (
 	"strings"
 	"time"
 
+	"trading-systemv1/internal/indicator"
+	"trading-systemv1/internal/model"
+
 	goredis "github.com/go-redis/redis/v8"
 )
 
@@ -21,8 +24,34 @@ type SubscribeMsg struct {
 	ReqID      string          `json:"reqId"`      // client-generated request ID
 	Symbol     string          `json:"symbol"`     // e.g. "NSE:99926000"
 	TF         int             `json:"tf"`         // timeframe in seconds
+	CandleType string          `json:"candleType"` // "raw" (default) or "ha" for Heikin-Ashi
 	History    HistoryRequest  `json:"history"`    // how many historical bars
 	Indicators []IndicatorSpec `json:"indicators"` // indicator profile
+	MarketData []string        `json:"marketData"` // opt-in live market data: "quote", "depth"
+}
+
+// Market data streams accepted in SubscribeMsg.MarketData.
+const (
+	MarketDataQuote = "quote"
+	MarketDataDepth = "depth"
+)
+
+// Candle types accepted in SubscribeMsg.CandleType.
+const (
+	CandleTypeRaw = "raw"
+	CandleTypeHA  = "ha"
+)
+
+// SeriesTF returns the TF identity of the series a subscription reads:
+// tf itself for raw candles, its Heikin-Ashi series (model.HATF) for "ha".
+func SeriesTF(tf int, candleType string) (int, error) {
+	switch strings.ToLower(candleType) {
+	case "", CandleTypeRaw:
+		return tf, nil
+	case CandleTypeHA:
+		return model.HATF(tf), nil
+	}
+	return 0, fmt.Errorf("unknown candleType %q (want %q or %q)", candleType, CandleTypeRaw, CandleTypeHA)
 }
 
 // HistoryRequest specifies how many historical candles to fetch.
@@ -32,18 +61,23 @@ type HistoryRequest struct {
 
 // IndicatorSpec describes a single indicator in the client's profile.
 type IndicatorSpec struct {
-	ID     string         `json:"id"`           // e.g. "smma", "ema", "sma", "rsi"
-	Source string         `json:"source"`       // e.g. "close", "high", "low"
-	Params map[string]int `json:"params"`       // e.g. {"length": 21}
-	TF     int            `json:"tf,omitempty"` // per-indicator TF override (seconds)
+	ID     string             `json:"id"`           // e.g. "smma", "ema", "sma", "rsi", "atr", "supertrend"
+	Source string             `json:"source"`       // e.g. "close", "high", "low", "hl2", "hlc3", "ohlc4", "volume"
+	Params map[string]float64 `json:"params"`       // e.g. {"length": 21} or {"length": 20, "mult": 2.5}
+	TF     int                `json:"tf,omitempty"` // per-indicator TF override (seconds)
+
+	// Input chains this indicator onto another one's output,
+	// e.g. {id:"ema", params:{length:9}, input:{id:"rsi", params:{length:14}}}.
+	Input *IndicatorSpec `json:"input,omitempty"`
 }
 
 // UnsubscribeMsg is the client → server UNSUBSCRIBE request.
 type UnsubscribeMsg struct {
-	Type   string `json:"type"` // "UNSUBSCRIBE"
-	ReqID  string `json:"reqId"`
-	Symbol string `json:"symbol"`
-	TF     int    `json:"tf"`
+	Type       string `json:"type"` // "UNSUBSCRIBE"
+	ReqID      string `json:"reqId"`
+	Symbol     string `json:"symbol"`
+	TF         int    `json:"tf"`
+	CandleType string `json:"candleType"`
 }
 
 // SnapshotResponse is the server → client SNAPSHOT with historical data.
@@ -51,9 +85,15 @@ type SnapshotResponse struct {
 	Type       string                        `json:"type"` // "SNAPSHOT"
 	ReqID      string                        `json:"reqId"`
 	Symbol     string                        `json:"symbol"`
-	TF         int                           `json:"tf"`
+	TF         int                           `json:"tf"`         // series TF: model.HATF(tf) for Heikin-Ashi
+	CandleType string                        `json:"candleType"` // "raw" or "ha"
 	Candles    []SnapshotCandle              `json:"candles"`
 	Indicators map[string][]SnapshotIndPoint `json:"indicators"`
+	Patterns   []SnapshotPattern             `json:"patterns"`
+	Levels     *model.SessionLevels          `json:"levels"`          // pivots/CPR for the current session, null if not computed yet
+	OI         []model.OIBar                 `json:"oi,omitempty"`    // OI analytics per bar (F&O only)
+	Quote      *model.Quote                  `json:"quote,omitempty"` // latest quote, when "quote" was requested
+	Depth      *model.Depth                  `json:"depth,omitempty"` // latest order book, when "depth" was requested
 }
 
 // SnapshotCandle is a single candle in the snapshot.
@@ -65,13 +105,29 @@ type SnapshotCandle struct {
 	Close  float64 `json:"close"`
 	Volume float64 `json:"volume"`
 	Count  float64 `json:"count"`
+
+	OIOpen  float64 `json:"oi_open,omitempty"` // F&O open interest at the bar's open/close
+	OIClose float64 `json:"oi_close,omitempty"`
+
+	Synthetic bool `json:"synthetic,omitempty"` // gap-fill bar: no trade, flat at the previous close
 }
 
 // SnapshotIndPoint is a single indicator point in the snapshot.
+// Components is set for multi-output indicators (e.g. BB upper/mid/lower).
 type SnapshotIndPoint struct {
-	TS    string  `json:"ts"`
-	Value float64 `json:"value"`
-	Ready bool    `json:"ready"`
+	TS         string             `json:"ts"`
+	Value      float64            `json:"value"`
+	Ready      bool               `json:"ready"`
+	Components map[string]float64 `json:"components,omitempty"`
+}
+
+// SnapshotPattern is a candlestick pattern marker in the snapshot.
+type SnapshotPattern struct {
+	Name    string `json:"name"`     // e.g. "BULLISH_ENGULFING"
+	TS      string `json:"ts"`       // candle that completed the pattern
+	StartTS string `json:"start_ts"` // first candle of the pattern
+	Bars    int    `json:"bars"`
+	Bias    string `json:"bias"` // "bullish", "bearish" or "neutral"
 }
 
 // LiveUpdate is the server → client LIVE message for a closed candle.
@@ -85,9 +141,10 @@ type LiveUpdate struct {
 
 // LiveIndValue is a live indicator value.
 type LiveIndValue struct {
-	Value float64 `json:"value"`
-	Ready bool    `json:"ready"`
-	Live  bool    `json:"live,omitempty"`
+	Value      float64            `json:"value"`
+	Ready      bool               `json:"ready"`
+	Live       bool               `json:"live,omitempty"`
+	Components map[string]float64 `json:"components,omitempty"`
 }
 
 // ErrorResponse is the server → client ERROR message.
@@ -116,6 +173,8 @@ type ClientSubscription struct {
 	TF         int
 	Indicators []IndicatorSpec
 	IndEntries []IndEntry // resolved (name, tf) pairs — no collisions
+	Quote      bool       // deliver pub:quote for the symbol
+	Depth      bool       // deliver pub:depth for the symbol
 }
 
 // SubKey returns the map key for this subscription.
@@ -125,24 +184,67 @@ func (s *ClientSubscription) SubKey() string {
 
 // ── Helpers ──
 
-// IndicatorSpecToName converts a spec like {id:"smma", params:{length:21}} → "SMMA_21"
+// IndicatorSpecToName converts a spec like {id:"smma", params:{length:21}} → "SMMA_21".
+// Every registered parameter is included, defaults filled in:
+// {id:"bb", params:{length:20, mult:2.5}} → "BB_20_2.5".
+// A non-close source is appended: {id:"ema", source:"hl2", ...} → "EMA_21_hl2",
+// and a chained input follows "@": EMA(9) of RSI(14) → "EMA_9@RSI_14".
 func IndicatorSpecToName(spec IndicatorSpec) string {
-	typ := strings.ToUpper(spec.ID)
-	length, ok := spec.Params["length"]
-	if !ok {
-		length = 14 // default
-	}
-	return typ + "_" + strconv.Itoa(length)
+	return specConfig(spec).Name()
 }
 
-// IndicatorSpecToConfig converts to the indengine format "TYPE:PERIOD"
+// IndicatorSpecToConfig converts to the indengine format, e.g. "SMA:20",
+// "MACD(12,26,9)" or "EMA:9@RSI:14".
 func IndicatorSpecToConfig(spec IndicatorSpec) string {
+	return specConfig(spec).Spec()
+}
+
+// IndicatorNameToConfig converts a resolved name like "EMA_21_hl2" or
+// "BB_20_2.5" to the indengine format ("EMA:21:hl2", "BB(20,2.5)").
+// Returns "" if the name does not parse.
+func IndicatorNameToConfig(name string) string {
+	cfg, err := indicator.ParseIndicatorName(name)
+	if err != nil {
+		return ""
+	}
+	return cfg.Spec()
+}
+
+// specConfig builds the indicator config for a client spec. Parameters are
+// matched by their registered names; "length" always addresses the first
+// parameter, so {length:21} works for every single-period indicator.
+func specConfig(spec IndicatorSpec) indicator.IndicatorConfig {
 	typ := strings.ToUpper(spec.ID)
-	length, ok := spec.Params["length"]
-	if !ok {
-		length = 14
+	cfg := indicator.IndicatorConfig{Type: typ, Source: indicator.Source(specSource(spec))}
+	if len(spec.Params) > 0 {
+		cfg.Params = make(indicator.Params, len(spec.Params))
+		for name, v := range spec.Params {
+			if reg, ok := indicator.Lookup(typ); ok && name == "length" {
+				name = reg.Params[0].Name
+			}
+			cfg.Params[name] = v
+		}
 	}
-	return typ + ":" + strconv.Itoa(length)
+	if spec.Input != nil {
+		in := specConfig(*spec.Input)
+		cfg.Input = &in
+	}
+	return cfg
+}
+
+// ValidateIndicatorSpec checks a client indicator spec against the indicator
+// registry, so unknown types and invalid parameters are rejected up front.
+func ValidateIndicatorSpec(spec IndicatorSpec) error {
+	return indicator.ValidateIndicator(specConfig(spec))
+}
+
+// specSource returns the normalized source of a spec, or "" for close (the default).
+func specSource(spec IndicatorSpec) string {
+	src := strings.ToLower(strings.TrimSpace(spec.Source))
+	if src == "close" {
+		return ""
+	}
+	return src
 }
 
 // ResolveIndicatorNames converts all specs to their resolved names.
@@ -156,18 +258,33 @@ func ResolveIndicatorNames(specs []IndicatorSpec) []string {
 
 // ResolveIndEntries builds a list of (name, tf) entries for MTF support.
 // Uses composite identity so SMA_20@60 and SMA_20@300 don't collide.
+// Per-indicator TF overrides follow the candle type of defaultTF, so an
+// HA subscription computes every indicator on HA candles.
 func ResolveIndEntries(specs []IndicatorSpec, defaultTF int) []IndEntry {
 	entries := make([]IndEntry, len(specs))
 	for i, spec := range specs {
 		tf := defaultTF
 		if spec.TF > 0 {
 			tf = spec.TF
+			if model.IsHATF(defaultTF) {
+				tf = model.HATF(tf)
+			}
 		}
 		entries[i] = IndEntry{Name: IndicatorSpecToName(spec), TF: tf}
 	}
 	return entries
 }
 
+// IsPriceOverlay reports whether an indicator result name (e.g. "SMA_20") is
+// plotted on the price scale, as opposed to an oscillator like RSI_14 or
+// MACD_12_26_9. The scale comes from the type's registration; a chain takes
+// the scale of its innermost input, so "EMA_9@RSI_14" is an oscillator.
+// Unknown names are treated as oscillators so their points are never dropped.
+func IsPriceOverlay(name string) bool {
+	cfg, err := indicator.ParseIndicatorName(name)
+	return err == nil && cfg.Overlay()
+}
+
 // ── Redis History Fetching ──
 
 // BuildSnapshotFromRedis reads historical candles + indicator data from Redis.
@@ -183,12 +300,17 @@ func BuildSnapshotFromRedis(ctx context.Context, rdb *goredis.Client, sub *Clien
 		Type:       "SNAPSHOT",
 		Symbol:     sub.Symbol,
 		TF:         sub.TF,
+		CandleType: CandleTypeRaw,
 		Candles:    make([]SnapshotCandle, 0, candleLimit),
 		Indicators: make(map[string][]SnapshotIndPoint, len(sub.IndEntries)),
+		Patterns:   []SnapshotPattern{},
+	}
+	if model.IsHATF(sub.TF) {
+		snap.CandleType = CandleTypeHA
 	}
 
 	// 1. Fetch candles from Redis stream
-	candleStreamKey := fmt.Sprintf("candle:%ds:%s", sub.TF, sub.Symbol)
+	candleStreamKey := "candle:" + model.TFKey(sub.TF) + ":" + sub.Symbol
 	candleMsgs, err := rdb.XRevRangeN(ctx, candleStreamKey, "+", "-", int64(candleLimit)).Result()
 	if err != nil {
 		log.Printf("[subscribe] candle stream read error for %s: %v", candleStreamKey, err)
@@ -198,6 +320,9 @@ func BuildSnapshotFromRedis(ctx context.Context, rdb *goredis.Client, sub *Clien
 		for i, j := 0, len(candleMsgs)-1; i < j; i, j = i+1, j-1 {
 			candleMsgs[i], candleMsgs[j] = candleMsgs[j], candleMsgs[i]
 		}
+		// A bar amended by late ticks appears once per revision: the
+		// latest replaces the earlier versions in place
+		seen := make(map[string]int, len(candleMsgs))
 		for _, msg := range candleMsgs {
 			dataStr, ok := msg.Values["data"].(string)
 			if !ok {
@@ -207,9 +332,15 @@ func BuildSnapshotFromRedis(ctx context.Context, rdb *goredis.Client, sub *Clien
 			if err := json.Unmarshal([]byte(dataStr), &c); err != nil {
 				continue
 			}
-			if c.TS != "" {
-				snap.Candles = append(snap.Candles, c)
+			if c.TS == "" {
+				continue
+			}
+			if idx, ok := seen[c.TS]; ok {
+				snap.Candles[idx] = c
+				continue
 			}
+			seen[c.TS] = len(snap.Candles)
+			snap.Candles = append(snap.Candles, c)
 		}
 	}
 
@@ -244,10 +375,10 @@ func BuildSnapshotFromRedis(ctx context.Context, rdb *goredis.Client, sub *Clien
 		}
 		// Add 1-candle margin on each side
 		if !candleTimeMin.IsZero() {
-			candleTimeMin = candleTimeMin.Add(-time.Duration(sub.TF) * time.Second)
+			candleTimeMin = candleTimeMin.Add(-tfMargin(sub.TF))
 		}
 		if !candleTimeMax.IsZero() {
-			candleTimeMax = candleTimeMax.Add(time.Duration(sub.TF) * time.Second)
+			candleTimeMax = candleTimeMax.Add(tfMargin(sub.TF))
 		}
 		log.Printf("[subscribe] candle time range: %s – %s", candleTimeMin, candleTimeMax)
 	}
@@ -256,7 +387,7 @@ func BuildSnapshotFromRedis(ctx context.Context, rdb *goredis.Client, sub *Clien
 	for _, entry := range sub.IndEntries {
 		// Key as "NAME:TF" so frontend knows the indicator's computation TF
 		snapKey := entry.Key()
-		indStreamKey := fmt.Sprintf("ind:%s:%ds:%s", entry.Name, entry.TF, sub.Symbol)
+		indStreamKey := "ind:" + entry.Name + ":" + model.TFKey(entry.TF) + ":" + sub.Symbol
 		indMsgs, err := rdb.XRevRangeN(ctx, indStreamKey, "+", "-", int64(candleLimit)).Result()
 		if err != nil {
 			log.Printf("[subscribe] indicator stream read error for %s: %v", indStreamKey, err)
@@ -276,9 +407,10 @@ func BuildSnapshotFromRedis(ctx context.Context, rdb *goredis.Client, sub *Clien
 				continue
 			}
 			var p struct {
-				Value float64 `json:"value"`
-				TS    string  `json:"ts"`
-				Ready bool    `json:"ready"`
+				Value      float64            `json:"value"`
+				TS         string             `json:"ts"`
+				Ready      bool               `json:"ready"`
+				Components map[string]float64 `json:"components"`
 			}
 			if err := json.Unmarshal([]byte(dataStr), &p); err != nil {
 				continue
@@ -288,8 +420,8 @@ func BuildSnapshotFromRedis(ctx context.Context, rdb *goredis.Client, sub *Clien
 				continue
 			}
 			// Skip warmup-phase values that fall outside the candle price band
-			// (only for price-overlay indicators like SMA/EMA/SMMA, not RSI)
-			if bandHi > 0 && !strings.HasPrefix(entry.Name, "RSI") {
+			// (only for price-overlay indicators like SMA/EMA/BB, not oscillators)
+			if bandHi > 0 && IsPriceOverlay(entry.Name) {
 				if p.Value < bandLo || p.Value > bandHi {
 					continue
 				}
@@ -303,9 +435,10 @@ func BuildSnapshotFromRedis(ctx context.Context, rdb *goredis.Client, sub *Clien
 				}
 			}
 			points = append(points, SnapshotIndPoint{
-				TS:    p.TS,
-				Value: p.Value,
-				Ready: p.Ready,
+				TS:         p.TS,
+				Value:      p.Value,
+				Ready:      p.Ready,
+				Components: p.Components,
 			})
 		}
 
@@ -331,9 +464,138 @@ func BuildSnapshotFromRedis(ctx context.Context, rdb *goredis.Client, sub *Clien
 		snap.Indicators[snapKey] = deduped
 	}
 
+	// 3. Session levels (pivots, CPR, previous-session HLC)
+	snap.Levels, err = ReadLevels(ctx, rdb, sub.Symbol)
+	if err != nil {
+		log.Printf("[subscribe] levels read error for %s: %v", sub.Symbol, err)
+	}
+
+	// Open interest analytics (F&O), clamped to the candle range like indicators
+	oiStreamKey := "oi:" + model.TFKey(sub.TF) + ":" + sub.Symbol
+	if oiMsgs, err := rdb.XRevRangeN(ctx, oiStreamKey, "+", "-", int64(candleLimit)).Result(); err != nil {
+		log.Printf("[subscribe] OI stream read error for %s: %v", oiStreamKey, err)
+	} else {
+		for i := len(oiMsgs) - 1; i >= 0; i-- {
+			dataStr, ok := oiMsgs[i].Values["data"].(string)
+			if !ok {
+				continue
+			}
+			var bar model.OIBar
+			if err := json.Unmarshal([]byte(dataStr), &bar); err != nil {
+				continue
+			}
+			if !candleTimeMin.IsZero() && !candleTimeMax.IsZero() &&
+				(bar.TS.Before(candleTimeMin) || bar.TS.After(candleTimeMax)) {
+				continue
+			}
+			snap.OI = append(snap.OI, bar)
+		}
+	}
+
+	// Latest quote and order book, when requested
+	if sub.Quote {
+		if snap.Quote, err = ReadQuote(ctx, rdb, sub.Symbol); err != nil {
+			log.Printf("[subscribe] quote read error for %s: %v", sub.Symbol, err)
+		}
+	}
+	if sub.Depth {
+		if snap.Depth, err = ReadDepth(ctx, rdb, sub.Symbol); err != nil {
+			log.Printf("[subscribe] depth read error for %s: %v", sub.Symbol, err)
+		}
+	}
+
+	// 4. Fetch candlestick pattern markers for the subscription's TF
+	patternStreamKey := "pattern:" + model.TFKey(sub.TF) + ":" + sub.Symbol
+	patternMsgs, err := rdb.XRevRangeN(ctx, patternStreamKey, "+", "-", int64(candleLimit)).Result()
+	if err != nil {
+		log.Printf("[subscribe] pattern stream read error for %s: %v", patternStreamKey, err)
+		return snap, nil
+	}
+	for i := len(patternMsgs) - 1; i >= 0; i-- {
+		dataStr, ok := patternMsgs[i].Values["data"].(string)
+		if !ok {
+			continue
+		}
+		var p SnapshotPattern
+		if err := json.Unmarshal([]byte(dataStr), &p); err != nil || p.TS == "" {
+			continue
+		}
+		// Clamp to candle time range, like indicator points
+		if !candleTimeMin.IsZero() && !candleTimeMax.IsZero() {
+			if pt, err := time.Parse(time.RFC3339, p.TS); err == nil {
+				if pt.Before(candleTimeMin) || pt.After(candleTimeMax) {
+					continue
+				}
+			}
+		}
+		snap.Patterns = append(snap.Patterns, p)
+	}
+
 	return snap, nil
 }
 
+// tfMargin is the one-candle margin around a snapshot's candle time range.
+// Bar series have no fixed duration, so they get none.
+func tfMargin(tf int) time.Duration {
+	if model.IsBarTF(tf) {
+		return 0
+	}
+	return time.Duration(model.BaseTF(tf)) * time.Second
+}
+
+// ReadLevels reads the current session levels for a symbol ("NSE:99926000").
+// Returns nil without error when none have been computed yet.
+func ReadLevels(ctx context.Context, rdb *goredis.Client, symbol string) (*model.SessionLevels, error) {
+	data, err := rdb.Get(ctx, "levels:"+symbol).Result()
+	if err == goredis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var lv model.SessionLevels
+	if err := json.Unmarshal([]byte(data), &lv); err != nil {
+		return nil, err
+	}
+	return &lv, nil
+}
+
+// ReadQuote reads the latest quote for a symbol ("NSE:99926000").
+// Returns nil without error when none has been ingested.
+func ReadQuote(ctx context.Context, rdb *goredis.Client, symbol string) (*model.Quote, error) {
+	var q model.Quote
+	if ok, err := readJSON(ctx, rdb, "quote:"+symbol, &q); !ok {
+		return nil, err
+	}
+	return &q, nil
+}
+
+// ReadDepth reads the latest order book for a symbol ("NSE:99926000").
+// Returns nil without error when none has been ingested.
+func ReadDepth(ctx context.Context, rdb *goredis.Client, symbol string) (*model.Depth, error) {
+	var d model.Depth
+	if ok, err := readJSON(ctx, rdb, "depth:"+symbol, &d); !ok {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// readJSON decodes the JSON string at key into v. It reports false, with a
+// nil error, when the key does not exist.
+func readJSON(ctx context.Context, rdb *goredis.Client, key string, v interface{}) (bool, error) {
+	data, err := rdb.Get(ctx, key).Result()
+	if err == goredis.Nil {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if err := json.Unmarshal([]byte(data), v); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
 // SendJSON marshals and sends a message to the client's send channel.
 func SendJSON(c *Client, v interface{}) {
 	data, err := json.Marshal(v)
@@ -372,9 +634,7 @@ func publishNewIndicators(ctx context.Context, rdb *goredis.Client, hub *Hub, ne
 
 	for _, ind := range indicators {
 		// Hub.Indicators stores names like "SMA_9" — convert to "SMA:9"
-		parts := strings.SplitN(ind, "_", 2)
-		if len(parts) == 2 {
-			config := parts[0] + ":" + parts[1]
+		if config := IndicatorNameToConfig(ind); config != "" {
 			known[config] = true
 			allConfigs = append(allConfigs, config)
 		}
@@ -427,7 +687,7 @@ func waitForIndicators(ctx context.Context, rdb *goredis.Client, sub *ClientSubs
 		case <-ticker.C:
 			allReady := true
 			for _, entry := range sub.IndEntries {
-				key := fmt.Sprintf("ind:%s:%ds:%s", entry.Name, entry.TF, sub.Symbol)
+				key := "ind:" + entry.Name + ":" + model.TFKey(entry.TF) + ":" + sub.Symbol
 				n, err := rdb.XLen(ctx, key).Result()
 				if err != nil || n == 0 {
 					allReady = false
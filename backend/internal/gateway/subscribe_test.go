@@ -0,0 +1,105 @@
+package gateway
+
+import (
+	"testing"
+
+	"trading-systemv1/internal/model"
+)
+
+func TestIndicatorSpecToName(t *testing.T) {
+	cases := []struct {
+		spec   IndicatorSpec
+		name   string
+		config string
+	}{
+		{IndicatorSpec{ID: "smma", Params: map[string]float64{"length": 21}}, "SMMA_21", "SMMA:21"},
+		{IndicatorSpec{ID: "ema", Source: "HL2", Params: map[string]float64{"length": 21}}, "EMA_21_hl2", "EMA:21:hl2"},
+		{IndicatorSpec{ID: "bb", Params: map[string]float64{"length": 20, "mult": 2.5}}, "BB_20_2.5", "BB(20,2.5)"},
+		{IndicatorSpec{ID: "macd"}, "MACD_12_26_9", "MACD(12,26,9)"},
+		{IndicatorSpec{ID: "supertrend", Params: map[string]float64{"mult": 2}}, "SUPERTREND_10_2", "SUPERTREND(10,2)"},
+		{IndicatorSpec{ID: "vwap", Params: map[string]float64{"anchor": 2}}, "VWAP_week", "VWAP:week"},
+		{
+			IndicatorSpec{ID: "ema", Params: map[string]float64{"length": 9},
+				Input: &IndicatorSpec{ID: "rsi", Params: map[string]float64{"length": 14}}},
+			"EMA_9@RSI_14", "EMA:9@RSI:14",
+		},
+	}
+	for _, tc := range cases {
+		if got := IndicatorSpecToName(tc.spec); got != tc.name {
+			t.Errorf("%s: name=%s, want %s", tc.spec.ID, got, tc.name)
+		}
+		if got := IndicatorSpecToConfig(tc.spec); got != tc.config {
+			t.Errorf("%s: config=%s, want %s", tc.spec.ID, got, tc.config)
+		}
+		if got := IndicatorNameToConfig(tc.name); got != tc.config {
+			t.Errorf("%s: IndicatorNameToConfig=%s, want %s", tc.name, got, tc.config)
+		}
+		if err := ValidateIndicatorSpec(tc.spec); err != nil {
+			t.Errorf("%s: unexpected validation error: %v", tc.name, err)
+		}
+	}
+}
+
+func TestValidateIndicatorSpec_Rejects(t *testing.T) {
+	bad := []IndicatorSpec{
+		{ID: "smaa", Params: map[string]float64{"length": 20}},
+		{ID: "bb", Params: map[string]float64{"length": 20, "width": 2}},
+		{ID: "bb", Params: map[string]float64{"length": 20.5}},
+		{ID: "atr", Source: "hl2", Params: map[string]float64{"length": 14}},
+	}
+	for _, spec := range bad {
+		if err := ValidateIndicatorSpec(spec); err == nil {
+			t.Errorf("expected %+v to be rejected", spec)
+		}
+	}
+}
+
+func TestSeriesTF_HeikinAshi(t *testing.T) {
+	for _, ct := range []string{"", "raw", "RAW"} {
+		if tf, err := SeriesTF(300, ct); err != nil || tf != 300 {
+			t.Errorf("SeriesTF(300, %q) = %d, %v; want 300", ct, tf, err)
+		}
+	}
+	ha, err := SeriesTF(300, "ha")
+	if err != nil || ha != model.HATF(300) {
+		t.Fatalf("SeriesTF(300, ha) = %d, %v; want %d", ha, err, model.HATF(300))
+	}
+	if _, err := SeriesTF(300, "renko"); err == nil {
+		t.Error("unknown candleType should fail")
+	}
+
+	specs := []IndicatorSpec{
+		{ID: "sma", Params: map[string]float64{"length": 20}},
+		{ID: "rsi", Params: map[string]float64{"length": 14}, TF: 60},
+	}
+	entries := ResolveIndEntries(specs, ha)
+	if entries[0].TF != model.HATF(300) || entries[1].TF != model.HATF(60) {
+		t.Errorf("HA subscriptions compute indicators on HA candles: %+v", entries)
+	}
+	if raw := ResolveIndEntries(specs, 300); raw[1].TF != 60 {
+		t.Errorf("raw TF override: %+v", raw)
+	}
+}
+
+func TestIsPriceOverlay(t *testing.T) {
+	cases := map[string]bool{
+		"SMA_20":        true,
+		"BB_20_2.5":     true,
+		"PSAR_0.02_0.2": true,
+		"EMA_21_hl2":    true,
+		"EMA_9@SMA_20":  true,
+		"SMA_20_volume": false,
+		"RSI_14":        false,
+		"MACD_12_26_9":  false,
+		"ATR_14":        false,
+		"EMA_9@RSI_14":  false,
+		"UNKNOWN_20":    false,
+		"SMA_20@ATR_14": false,
+		"STOCH_14_3_3":  false,
+	}
+	for name, want := range cases {
+		if got := IsPriceOverlay(name); got != want {
+			t.Errorf("IsPriceOverlay(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
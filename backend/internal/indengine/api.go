@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"strings"
 
 	"trading-systemv1/internal/indicator"
 	"trading-systemv1/internal/model"
@@ -41,7 +42,13 @@ func (svc *Service) handleReload(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "validation: "+err.Error(), http.StatusBadRequest)
 		return
 	}
-	preserved, created := svc.engine.ReloadConfigs(newConfigs)
+	preserved, created, err := svc.reload(r.Context(), func([]indicator.TFIndicatorConfig) ([]indicator.TFIndicatorConfig, error) {
+		return newConfigs, nil
+	})
+	if err != nil {
+		http.Error(w, "reload: "+err.Error(), http.StatusServiceUnavailable)
+		return
+	}
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"status":    "ok",
@@ -71,49 +78,188 @@ func (svc *Service) startConfigSubscriber(ctx context.Context) {
 					return
 				}
 				log.Printf("[indengine] received config update: %s", msg.Payload)
-				svc.reloadFromSpecs(ctx, ParseIndicatorSpecs(msg.Payload))
+				svc.handleConfigMessage(ctx, msg.Payload)
 			}
 		}
 	}()
 }
 
-// reloadFromSpecs rebuilds TF configs from indicator specs and reloads the engine.
-// If new indicators are created, backfills them from Redis candle streams.
-func (svc *Service) reloadFromSpecs(ctx context.Context, newSpecs []indicator.IndicatorConfig) {
-	newConfigs := make([]indicator.TFIndicatorConfig, len(svc.cfg.EnabledTFs))
-	for i, tf := range svc.cfg.EnabledTFs {
-		newConfigs[i] = indicator.TFIndicatorConfig{TF: tf, Indicators: newSpecs}
+// handleConfigMessage applies a config:indicators message. A plain spec list
+// ("SMA:20,EMA:9") replaces the default indicator set on every enabled TF;
+// a JSON profile or array of profiles (see Profile) adds, replaces or
+// removes just those profiles. Either way the rest of the config is kept.
+func (svc *Service) handleConfigMessage(ctx context.Context, payload string) {
+	var update func([]indicator.TFIndicatorConfig) ([]indicator.TFIndicatorConfig, error)
+	if p := strings.TrimSpace(payload); strings.HasPrefix(p, "{") || strings.HasPrefix(p, "[") {
+		profiles, err := ParseProfiles(p)
+		if err != nil {
+			log.Printf("[indengine] invalid profile update: %v", err)
+			return
+		}
+		update = func(configs []indicator.TFIndicatorConfig) ([]indicator.TFIndicatorConfig, error) {
+			for _, prof := range profiles {
+				var err error
+				if configs, err = withProfile(configs, prof, svc.cfg.EnabledTFs); err != nil {
+					return nil, fmt.Errorf("profile update: %w", err)
+				}
+			}
+			return configs, nil
+		}
+	} else {
+		specs := ParseIndicatorSpecs(payload)
+		update = func(configs []indicator.TFIndicatorConfig) ([]indicator.TFIndicatorConfig, error) {
+			return withDefaults(configs, specs, svc.cfg.EnabledTFs), nil
+		}
 	}
-	if err := indicator.ValidateConfigs(newConfigs); err != nil {
+	if _, _, err := svc.reload(ctx, update); err != nil {
 		log.Printf("[indengine] invalid config: %v", err)
-		return
 	}
-	preserved, created := svc.engine.ReloadConfigs(newConfigs)
-	log.Printf("[indengine] reloaded: preserved=%d, created=%d", preserved, created)
-
-	// Backfill new indicators from Redis candle streams
-	if created > 0 {
-		backfillCh := make(chan model.TFCandle, 5000)
-		go func() {
-			for _, stream := range svc.streams {
-				_, err := svc.redisReader.ReplayFromID(ctx, stream, "0", backfillCh)
-				if err != nil {
-					log.Printf("[indengine] reload backfill error on %s: %v", stream, err)
-				}
+}
+
+// onLoop runs fn on processLoop, between candles, and waits for it. The
+// engine is single-goroutine: everything that touches it after startup goes
+// through here or processLoop itself.
+func (svc *Service) onLoop(ctx context.Context, fn func()) error {
+	done := make(chan struct{})
+	select {
+	case svc.loopCh <- func() { fn(); close(done) }:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case <-done:
+		return nil
+	case <-svc.runCtx.Done():
+		return svc.runCtx.Err()
+	}
+}
+
+// reload derives new TF configs from the current ones with update and
+// applies them on processLoop. If the reload leaves new instances cold, it
+// warms them up in the background (see warmUpNewIndicators); reloads are
+// serialized, so the next one waits for that warm-up to end.
+func (svc *Service) reload(ctx context.Context, update func([]indicator.TFIndicatorConfig) ([]indicator.TFIndicatorConfig, error)) (preserved, created int, err error) {
+	svc.reloadMu.Lock()
+	var targets []indicator.WarmUpTarget
+	loopErr := svc.onLoop(ctx, func() {
+		var newConfigs []indicator.TFIndicatorConfig
+		if newConfigs, err = update(svc.engine.Configs()); err == nil {
+			err = indicator.ValidateConfigs(newConfigs)
+		}
+		if err != nil {
+			return
+		}
+		preserved, created = svc.engine.ReloadConfigs(newConfigs)
+		targets = svc.engine.WarmUpTargets()
+	})
+	if err == nil {
+		err = loopErr
+	}
+	if err != nil || len(targets) == 0 {
+		svc.reloadMu.Unlock()
+		if err == nil {
+			log.Printf("[indengine] reloaded: preserved=%d, created=%d", preserved, created)
+		}
+		return preserved, created, err
+	}
+
+	log.Printf("[indengine] reloaded: preserved=%d, created=%d, warming up %d token states", preserved, created, len(targets))
+	go func() {
+		defer svc.reloadMu.Unlock()
+		svc.warmUpNewIndicators(svc.runCtx, targets)
+	}()
+	return preserved, created, nil
+}
+
+// warmUpNewIndicators feeds history to the indicator instances the last
+// reload created cold, while processLoop keeps running: Process holds live
+// candles back from those instances until FinishWarmUp. The I/O runs here:
+// per target, the most recent candles from SQLite candles_tf, then from its
+// Redis candle stream (derived from the target, so TFs added by the reload
+// are covered and start being consumed too). processLoop then feeds them,
+// the backfilled series replaces the ind:* streams, and processLoop finally
+// feeds the held-back live candles and publishes their results.
+func (svc *Service) warmUpNewIndicators(ctx context.Context, targets []indicator.WarmUpTarget) {
+	svc.consumeNewStreams(ctx, targets)
+
+	history := make([][]model.TFCandle, len(targets))
+	fromSQLite, fromRedis := 0, 0
+	for i, t := range targets {
+		if svc.sqlReader != nil {
+			exchange, token, _ := strings.Cut(t.Key, ":")
+			candles, err := svc.sqlReader.ReadLatestTFCandles(exchange, token, t.TF, t.Bars)
+			if err != nil {
+				log.Printf("[indengine] reload warm-up: sqlite %s tf=%d: %v", t.Key, t.TF, err)
 			}
-			close(backfillCh)
-		}()
-
-		backfillCount := 0
-		for tfc := range backfillCh {
-			if !tfc.Forming {
-				results := svc.engine.Process(tfc)
-				if len(results) > 0 {
-					svc.redisWriter.WriteIndicatorBatch(ctx, results)
-				}
-				backfillCount++
+			history[i] = candles
+			fromSQLite += len(candles)
+		}
+		candles, err := svc.redisReader.ReadLatestTFCandles(ctx, tfStream(t.TF, t.Key), t.Bars)
+		if err != nil {
+			log.Printf("[indengine] reload warm-up: %v", err)
+		}
+		history[i] = append(history[i], candles...)
+		fromRedis += len(candles)
+	}
+
+	var results []model.IndicatorResult
+	if err := svc.onLoop(ctx, func() {
+		for _, candles := range history {
+			for _, tfc := range candles {
+				results = append(results, svc.engine.WarmUp(tfc)...)
 			}
 		}
-		log.Printf("[indengine] ✅ reload backfill: processed %d candles for new indicators", backfillCount)
+	}); err != nil {
+		return
 	}
+	svc.redisWriter.ReplaceIndicatorHistory(ctx, results)
+
+	var live []model.IndicatorResult
+	if err := svc.onLoop(ctx, func() {
+		live = svc.engine.FinishWarmUp()
+		if len(live) > 0 {
+			svc.redisWriter.WriteIndicatorBatch(ctx, live)
+		}
+	}); err != nil {
+		return
+	}
+	log.Printf("[indengine] ✅ reload backfill: warmed up new indicators with %d SQLite + %d Redis candles (%d results written, %d live candles caught up)",
+		fromSQLite, fromRedis, len(results), len(live))
+}
+
+// consumeNewStreams starts consuming the candle streams of warm-up targets
+// that are not consumed yet, i.e. of TFs a reload added. New groups start
+// at "$": the warm-up reads the history. Only reloads, which are
+// serialized, touch svc.streams after startup.
+func (svc *Service) consumeNewStreams(ctx context.Context, targets []indicator.WarmUpTarget) {
+	known := make(map[string]bool, len(svc.streams))
+	for _, stream := range svc.streams {
+		known[stream] = true
+	}
+	var added []string
+	for _, t := range targets {
+		if stream := tfStream(t.TF, t.Key); !known[stream] {
+			known[stream] = true
+			added = append(added, stream)
+		}
+	}
+	if len(added) == 0 {
+		return
+	}
+	if err := svc.redisReader.EnsureConsumerGroup(ctx, added); err != nil {
+		log.Printf("[indengine] WARNING: consumer group setup for new streams: %v", err)
+		return
+	}
+	svc.streams = append(svc.streams, added...)
+	go func() {
+		if err := svc.redisReader.ConsumeTFCandles(ctx, added, svc.tfCandleCh); err != nil {
+			log.Printf("[indengine] consumer error on new streams: %v", err)
+		}
+	}()
+	log.Printf("[indengine] consuming %d new streams after reload: %v", len(added), added)
+}
+
+// tfStream returns the candle stream of a token ("exchange:token") on a TF.
+func tfStream(tf int, key string) string {
+	return "candle:" + model.TFKey(tf) + ":" + key
 }
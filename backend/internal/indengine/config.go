@@ -5,8 +5,12 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
+	"trading-systemv1/config"
 	"trading-systemv1/internal/indicator"
+	"trading-systemv1/internal/markethours"
+	"trading-systemv1/internal/model"
 )
 
 // Config holds all env-parsed configuration for the indicator engine service.
@@ -24,6 +28,9 @@ type Config struct {
 	PELIntervalS       int
 	PELMinIdleMs       int64
 	IndicatorConfigs   []indicator.TFIndicatorConfig
+	DivOscillators     []string      // indicator names checked for divergences, e.g. "RSI_14"
+	HeikinAshi         bool          // also consume the Heikin-Ashi series of every TF
+	AmendWindow        time.Duration // recompute bars revised by late ticks up to this old (0 = off)
 }
 
 // LoadConfig reads all environment variables and returns a Config.
@@ -40,6 +47,9 @@ func LoadConfig() Config {
 	httpAddr := getEnv("INDENGINE_HTTP_ADDR", ":9095")
 	pelIntervalStr := getEnv("PEL_RECLAIM_INTERVAL_SEC", "30")
 	pelMinIdleStr := getEnv("PEL_MIN_IDLE_MS", "60000")
+	divOscillators := getEnv("DIVERGENCE_OSCILLATORS", "RSI_14")
+	heikinAshi := config.HeikinAshiEnabled()
+	amendWindow := config.ParseDuration(getEnv("CANDLE_AMEND_WINDOW", "0"))
 
 	pelInterval, _ := strconv.Atoi(pelIntervalStr)
 	if pelInterval <= 0 {
@@ -56,6 +66,9 @@ func LoadConfig() Config {
 	}
 
 	enabledTFs := parseTFs(enabledTFsStr)
+	if heikinAshi {
+		enabledTFs = withHeikinAshi(enabledTFs)
+	}
 	indConfigs := BuildIndicatorConfigs(enabledTFs)
 	tokenKeys := parseTokenKeys(subscribeTokens)
 
@@ -73,26 +86,46 @@ func LoadConfig() Config {
 		PELIntervalS:       pelInterval,
 		PELMinIdleMs:       pelMinIdle,
 		IndicatorConfigs:   indConfigs,
+		DivOscillators:     parseNames(divOscillators),
+		HeikinAshi:         heikinAshi,
+		AmendWindow:        amendWindow,
 	}
 }
 
 // BuildIndicatorConfigs creates indicator configurations per TF from the
 // INDICATOR_CONFIGS env var.  Format: "TYPE:PERIOD,TYPE:PERIOD,..."
-// Example: "SMA:9,SMA:20,SMA:50,SMA:200,EMA:9,EMA:21,RSI:14"
-// If the env var is empty, sensible defaults are used.
+// Example: "SMA:9,SMA:20,SMA:50,SMA:200,EMA:9,EMA:21,RSI:14,ATR:14,SUPERTREND(10,3),MACD(12,26,9)"
+// If the env var is empty, sensible defaults are used. Per-instrument
+// profiles from INDICATOR_PROFILES (see Profile) override the default for
+// their instruments; invalid profiles are skipped.
 func BuildIndicatorConfigs(tfs []int) []indicator.TFIndicatorConfig {
 	indSpecs := ParseIndicatorSpecs(getEnv("INDICATOR_CONFIGS", ""))
-	configs := make([]indicator.TFIndicatorConfig, len(tfs))
-	for i, tf := range tfs {
-		configs[i] = indicator.TFIndicatorConfig{
-			TF:         tf,
-			Indicators: indSpecs,
+	configs := withDefaults(nil, indSpecs, tfs)
+
+	profiles, err := ParseProfiles(getEnv("INDICATOR_PROFILES", ""))
+	if err != nil {
+		log.Printf("[indengine] WARNING: ignoring INDICATOR_PROFILES: %v", err)
+		return configs
+	}
+	for _, p := range profiles {
+		next, err := withProfile(configs, p, tfs)
+		if err == nil {
+			err = indicator.ValidateConfigs(next)
+		}
+		if err != nil {
+			log.Printf("[indengine] skipping indicator profile %q: %v", p.Name, err)
+			continue
 		}
+		configs = next
+		log.Printf("[indengine] loaded indicator profile %q for %d instruments", p.Name, len(p.Instruments))
 	}
 	return configs
 }
 
-// ParseIndicatorSpecs parses "TYPE:PERIOD,..." into []IndicatorConfig.
+// ParseIndicatorSpecs parses "TYPE:PERIOD[:SOURCE],..." into []IndicatorConfig.
+// Multi-parameter indicators use "TYPE(P1,P2,...)", e.g. "MACD(12,26,9)" or
+// "BB(20,2.5)". SOURCE is one of close, open, high, low, hl2, hlc3, ohlc4,
+// volume (default close). Specs chain with "@", e.g. "EMA:9@RSI:14" (EMA of RSI).
 // Returns defaults if input is empty.
 func ParseIndicatorSpecs(s string) []indicator.IndicatorConfig {
 	if s == "" {
@@ -108,19 +141,13 @@ func ParseIndicatorSpecs(s string) []indicator.IndicatorConfig {
 	}
 
 	var configs []indicator.IndicatorConfig
-	for _, part := range strings.Split(s, ",") {
-		part = strings.TrimSpace(part)
-		tokens := strings.SplitN(part, ":", 2)
-		if len(tokens) != 2 {
-			continue
-		}
-		typ := strings.ToUpper(strings.TrimSpace(tokens[0]))
-		period, err := strconv.Atoi(strings.TrimSpace(tokens[1]))
-		if err != nil || period <= 0 {
-			log.Printf("[indengine] skipping invalid indicator spec: %q", part)
+	for _, part := range indicator.SplitIndicatorSpecs(s) {
+		cfg, err := indicator.ParseIndicatorConfig(part)
+		if err != nil {
+			log.Printf("[indengine] skipping invalid indicator spec: %q: %v", part, err)
 			continue
 		}
-		configs = append(configs, indicator.IndicatorConfig{Type: typ, Period: period})
+		configs = append(configs, cfg)
 	}
 	if len(configs) == 0 {
 		log.Println("[indengine] WARNING: no valid indicators parsed, using defaults")
@@ -138,8 +165,8 @@ func parseTFs(s string) []int {
 		if p == "" {
 			continue
 		}
-		n, err := strconv.Atoi(p)
-		if err != nil || n <= 0 {
+		n, err := markethours.ParseTF(p)
+		if err != nil {
 			continue
 		}
 		tfs = append(tfs, n)
@@ -147,6 +174,30 @@ func parseTFs(s string) []int {
 	return tfs
 }
 
+// withHeikinAshi appends the Heikin-Ashi series of every TF, so indicators,
+// patterns and divergences also run on the "candle:ha{tf}" streams.
+func withHeikinAshi(tfs []int) []int {
+	out := append([]int(nil), tfs...)
+	for _, tf := range tfs {
+		if !model.IsHATF(tf) {
+			out = append(out, model.HATF(tf))
+		}
+	}
+	return out
+}
+
+// parseNames parses a comma-separated name list, dropping empty entries.
+// "none" disables the list.
+func parseNames(s string) []string {
+	var names []string
+	for _, n := range strings.Split(s, ",") {
+		if n = strings.TrimSpace(n); n != "" && n != "none" {
+			names = append(names, n)
+		}
+	}
+	return names
+}
+
 // parseTokenKeys parses "exchangeType:token,..." into "exchange:token" keys.
 // Since we use NSE convention: exchangeType 1 = "NSE"
 func parseTokenKeys(s string) []string {
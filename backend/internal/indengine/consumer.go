@@ -40,6 +40,11 @@ func (svc *Service) startPELReclaimer(ctx context.Context) {
 
 // processLoop consumes TF candles from the channel and computes indicators.
 // Uses Process() for finalized candles and ProcessPeek() for forming candles.
+// Finalized candles also run through the candlestick pattern and divergence
+// detectors. A revised bar (amended by late ticks) only recomputes the
+// indicators of the bars from it on; pattern and divergence events already
+// published stand. Config reloads and their warm-up steps (see onLoop) run
+// here too, between candles, so they never run concurrently with Process.
 func (svc *Service) processLoop(ctx context.Context) {
 	const (
 		indicatorLatencyKey           = "metrics:indengine:indicator_compute_ms"
@@ -56,6 +61,8 @@ func (svc *Service) processLoop(ctx context.Context) {
 		select {
 		case <-ctx.Done():
 			return
+		case fn := <-svc.loopCh:
+			fn()
 		case tfc, ok := <-svc.tfCandleCh:
 			if !ok {
 				return
@@ -99,6 +106,10 @@ func (svc *Service) processLoop(ctx context.Context) {
 			if len(results) > 0 {
 				svc.redisWriter.WriteIndicatorBatch(ctx, results)
 			}
+			if !tfc.Forming && tfc.Revision == 0 {
+				svc.redisWriter.WritePatternBatch(ctx, svc.patterns.Process(tfc))
+				svc.redisWriter.WriteDivergenceBatch(ctx, svc.divergences.Process(tfc, results))
+			}
 		}
 	}
 }
@@ -0,0 +1,78 @@
+package indengine
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"trading-systemv1/internal/levels"
+	"trading-systemv1/internal/markethours"
+	"trading-systemv1/internal/model"
+)
+
+// levelsFlushDelay gives mdengine's batched SQLite writer time to persist a
+// session's last candles before they are aggregated.
+const levelsFlushDelay = time.Minute
+
+// levelsLoop publishes pivots, CPR and previous-session levels for the
+// current (or next) session at startup, then again after every session close
+// for the session that follows. Blocks until ctx is cancelled.
+func (svc *Service) levelsLoop(ctx context.Context) {
+	if svc.sqlReader == nil {
+		log.Println("[indengine] session levels disabled: no SQLite reader")
+		return
+	}
+
+	for {
+		session := markethours.CurrentOrNextOpen(time.Now())
+		prev := markethours.PrevOpen(session)
+
+		// Started right after a close: let the session's candles land first
+		if !sleepUntil(ctx, markethours.TodayClose(prev).Add(levelsFlushDelay)) {
+			return
+		}
+		svc.computeLevels(ctx, session, prev)
+
+		if !sleepUntil(ctx, markethours.TodayClose(session).Add(levelsFlushDelay)) {
+			return
+		}
+	}
+}
+
+// computeLevels aggregates the prev session from SQLite and writes the
+// levels derived from it for session to Redis.
+func (svc *Service) computeLevels(ctx context.Context, session, prev time.Time) {
+	candles, err := svc.sqlReader.ReadSessionCandles(prev, markethours.TodayClose(prev))
+	if err != nil {
+		log.Printf("[indengine] session levels: %v", err)
+		return
+	}
+	if len(candles) == 0 {
+		log.Printf("[indengine] session levels: no candles in SQLite for the %s session", prev.Format("2006-01-02"))
+		return
+	}
+
+	out := make([]model.SessionLevels, len(candles))
+	for i, c := range candles {
+		out[i] = levels.Compute(c, session)
+	}
+	svc.redisWriter.WriteLevels(ctx, out)
+	log.Printf("[indengine] ✅ session levels for %s published for %d instruments (from %s)",
+		session.Format("2006-01-02"), len(out), prev.Format("2006-01-02"))
+}
+
+// sleepUntil blocks until t or ctx cancellation; it reports false on the latter.
+func sleepUntil(ctx context.Context, t time.Time) bool {
+	d := time.Until(t)
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,115 @@
+package indengine
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"trading-systemv1/internal/indicator"
+)
+
+// Profile is an indicator set for a group of instruments, e.g. a lean set
+// for stock options and a full one for the NIFTY index chart. Profiles come
+// from the INDICATOR_PROFILES env var (a JSON array) or from a JSON message
+// on config:indicators. Instruments without a profile get the
+// INDICATOR_CONFIGS default.
+//
+// Example:
+//
+//	[{"name":"index","instruments":["NSE:99926000"],"indicators":"SMA:20,EMA:9,MACD(12,26,9)"},
+//	 {"name":"options","instruments":["NFO:43650"],"tfs":[60],"indicators":"EMA:9"}]
+type Profile struct {
+	Name        string   `json:"name"`
+	Instruments []string `json:"instruments"`      // "exchange:token" keys
+	TFs         []int    `json:"tfs,omitempty"`    // empty = every enabled TF
+	Indicators  string   `json:"indicators"`       // spec list as in INDICATOR_CONFIGS; empty = none
+	Remove      bool     `json:"remove,omitempty"` // config:indicators only: delete the named profile
+}
+
+// ParseProfiles parses a JSON profile object or array of profiles.
+func ParseProfiles(s string) ([]Profile, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+	var profiles []Profile
+	if strings.HasPrefix(s, "{") {
+		var p Profile
+		if err := json.Unmarshal([]byte(s), &p); err != nil {
+			return nil, err
+		}
+		profiles = []Profile{p}
+	} else if err := json.Unmarshal([]byte(s), &profiles); err != nil {
+		return nil, err
+	}
+	for _, p := range profiles {
+		if p.Name == "" {
+			return nil, fmt.Errorf("profile without a name")
+		}
+	}
+	return profiles, nil
+}
+
+// Configs expands the profile into one TFIndicatorConfig per TF. Unlike
+// INDICATOR_CONFIGS, an invalid spec rejects the whole profile.
+func (p Profile) Configs(enabledTFs []int) ([]indicator.TFIndicatorConfig, error) {
+	if len(p.Instruments) == 0 {
+		return nil, fmt.Errorf("profile %q has no instruments", p.Name)
+	}
+	var specs []indicator.IndicatorConfig
+	for _, part := range indicator.SplitIndicatorSpecs(p.Indicators) {
+		cfg, err := indicator.ParseIndicatorConfig(part)
+		if err != nil {
+			return nil, fmt.Errorf("profile %q: %v", p.Name, err)
+		}
+		specs = append(specs, cfg)
+	}
+	tfs := p.TFs
+	if len(tfs) == 0 {
+		tfs = enabledTFs
+	}
+	configs := make([]indicator.TFIndicatorConfig, len(tfs))
+	for i, tf := range tfs {
+		configs[i] = indicator.TFIndicatorConfig{
+			TF:          tf,
+			Profile:     p.Name,
+			Instruments: p.Instruments,
+			Indicators:  specs,
+		}
+	}
+	return configs, nil
+}
+
+// withDefaults returns configs with the default (instrument-less) sets
+// replaced by specs on every enabled TF. Profiles are kept.
+func withDefaults(configs []indicator.TFIndicatorConfig, specs []indicator.IndicatorConfig, tfs []int) []indicator.TFIndicatorConfig {
+	out := make([]indicator.TFIndicatorConfig, 0, len(configs)+len(tfs))
+	for _, tf := range tfs {
+		out = append(out, indicator.TFIndicatorConfig{TF: tf, Indicators: specs})
+	}
+	for _, cfg := range configs {
+		if len(cfg.Instruments) > 0 {
+			out = append(out, cfg)
+		}
+	}
+	return out
+}
+
+// withProfile returns configs with the named profile replaced by p, or
+// removed if p.Remove is set. Other profiles and defaults are kept.
+func withProfile(configs []indicator.TFIndicatorConfig, p Profile, tfs []int) ([]indicator.TFIndicatorConfig, error) {
+	out := make([]indicator.TFIndicatorConfig, 0, len(configs)+len(tfs))
+	for _, cfg := range configs {
+		if len(cfg.Instruments) == 0 || cfg.Profile != p.Name {
+			out = append(out, cfg)
+		}
+	}
+	if p.Remove {
+		return out, nil
+	}
+	added, err := p.Configs(tfs)
+	if err != nil {
+		return nil, err
+	}
+	return append(out, added...), nil
+}
@@ -4,12 +4,14 @@ import (
 	"context"
 	"log"
 	"os"
-	"strconv"
+	"sync"
 	"time"
 
+	"trading-systemv1/internal/divergence"
 	"trading-systemv1/internal/indicator"
 	"trading-systemv1/internal/metrics"
 	"trading-systemv1/internal/model"
+	"trading-systemv1/internal/pattern"
 	redisstore "trading-systemv1/internal/store/redis"
 	sqlitestore "trading-systemv1/internal/store/sqlite"
 )
@@ -20,6 +22,8 @@ type Service struct {
 	cfg Config
 
 	engine      *indicator.Engine
+	patterns    *pattern.Detector
+	divergences *divergence.Detector
 	redisReader *redisstore.Reader
 	redisWriter *redisstore.Writer
 	sqlReader   *sqlitestore.Reader
@@ -28,15 +32,22 @@ type Service struct {
 
 	streams    []string
 	tfCandleCh chan model.TFCandle
+	loopCh     chan func() // work to run on processLoop (see onLoop)
+	reloadMu   sync.Mutex  // serializes reloads and their warm-up
+	runCtx     context.Context
 }
 
 // New creates a new Service from the given Config.
 // It connects to Redis and SQLite and restores the indicator engine.
 func New(cfg Config) (*Service, error) {
 	svc := &Service{
-		cfg:        cfg,
-		prom:       metrics.NewMetrics(),
-		tfCandleCh: make(chan model.TFCandle, 5000),
+		cfg:         cfg,
+		patterns:    pattern.NewDetector(),
+		divergences: divergence.NewDetector(divergence.Config{Oscillators: cfg.DivOscillators}),
+		prom:        metrics.NewMetrics(),
+		tfCandleCh:  make(chan model.TFCandle, 5000),
+		loopCh:      make(chan func()),
+		runCtx:      context.Background(),
 	}
 
 	// ---- Connect to Redis ----
@@ -78,12 +89,19 @@ func New(cfg Config) (*Service, error) {
 // Run starts all subsystems and blocks until ctx is cancelled.
 func (svc *Service) Run(ctx context.Context) error {
 	cfg := svc.cfg
+	svc.runCtx = ctx
 	log.Println("[indengine] starting Indicator Engine microservice...")
 
 	// ---- Restore engine from snapshot ----
 	if err := svc.restoreEngine(ctx); err != nil {
 		return err
 	}
+	if cfg.AmendWindow > 0 {
+		// After the SQLite warm-up, which holds final bars only, so that
+		// the Redis backfill already applies the revisions in the streams
+		svc.engine.EnableAmend(cfg.AmendWindow)
+		log.Printf("[indengine] candle amendments enabled: revised bars up to %v old are recomputed", cfg.AmendWindow)
+	}
 
 	// ---- Discover / build streams ----
 	svc.streams = svc.buildStreams(ctx)
@@ -115,6 +133,7 @@ func (svc *Service) Run(ctx context.Context) error {
 	svc.startConsumer(ctx)
 	go svc.peekLoop(ctx)
 	go svc.snapshotLoop(ctx)
+	go svc.levelsLoop(ctx)
 	svc.startHTTP(ctx)
 	svc.startConfigSubscriber(ctx)
 
@@ -209,7 +228,7 @@ func (svc *Service) buildStreams(ctx context.Context) []string {
 	for _, tf := range svc.cfg.EnabledTFs {
 		if len(svc.cfg.SubscribeTokenKeys) > 0 {
 			for _, tk := range svc.cfg.SubscribeTokenKeys {
-				streams = append(streams, "candle:"+strconv.Itoa(tf)+"s:"+tk)
+				streams = append(streams, "candle:"+model.TFKey(tf)+":"+tk)
 			}
 		} else {
 			discovered := svc.redisReader.DiscoverTFStreams(ctx, []int{tf}, svc.cfg.SubscribeTokenKeys)
@@ -239,6 +258,12 @@ func (svc *Service) backfillFromRedis(ctx context.Context) {
 			if len(results) > 0 {
 				svc.redisWriter.WriteIndicatorBatch(ctx, results)
 			}
+			// Prime pattern windows and divergence pivots only: their
+			// events were already published when these candles were live.
+			if tfc.Revision == 0 {
+				svc.patterns.Process(tfc)
+				svc.divergences.Process(tfc, results)
+			}
 			backfillCount++
 		}
 	}
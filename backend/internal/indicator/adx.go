@@ -0,0 +1,157 @@
+package indicator
+
+import (
+	"fmt"
+	"math"
+
+	"trading-systemv1/internal/model"
+)
+
+// ADX calculates the Average Directional Index together with the
+// Directional Movement Index lines (+DI / -DI), using Wilder's smoothing.
+// +DI/-DI are ready after period+1 candles, ADX after 2*period.
+// O(1) per update.
+type ADX struct {
+	period    int
+	count     int
+	prevHigh  float64
+	prevLow   float64
+	prevClose float64
+	tr        *SMMA // smoothed true range
+	plusDM    *SMMA // smoothed +DM
+	minusDM   *SMMA // smoothed -DM
+	adx       *SMMA // smoothed DX
+	plusDI    float64
+	minusDI   float64
+}
+
+var adxComponents = []string{"adx", "plus_di", "minus_di"}
+
+// NewADX creates a new ADX/DMI indicator with the given period (typically 14).
+func NewADX(period int) *ADX {
+	return &ADX{
+		period:  period,
+		tr:      NewSMMA(period),
+		plusDM:  NewSMMA(period),
+		minusDM: NewSMMA(period),
+		adx:     NewSMMA(period),
+	}
+}
+
+func init() {
+	Register(Registration{
+		Type:   "ADX",
+		Params: []ParamSpec{{Name: "length", Kind: ParamInt, Default: 14, Min: 1}},
+		New:    func(cfg IndicatorConfig) Indicator { return NewADX(cfg.Int("length")) },
+		WarmUp: func(cfg IndicatorConfig) int { return 2 * cfg.Int("length") },
+	})
+}
+
+func (a *ADX) Name() string { return "ADX" }
+
+func (a *ADX) Update(candle model.Candle) {
+	high, low, close := float64(candle.High), float64(candle.Low), float64(candle.Close)
+	a.count++
+	if a.count > 1 {
+		tr, pdm, mdm := a.movement(high, low)
+		a.tr.updatePrice(tr)
+		a.plusDM.updatePrice(pdm)
+		a.minusDM.updatePrice(mdm)
+		if a.tr.Ready() {
+			var dx float64
+			a.plusDI, a.minusDI, dx = directionalIndex(a.tr.Value(), a.plusDM.Value(), a.minusDM.Value())
+			a.adx.updatePrice(dx)
+		}
+	}
+	a.prevHigh, a.prevLow, a.prevClose = high, low, close
+}
+
+// movement returns the true range and +DM/-DM of a candle against the previous one.
+func (a *ADX) movement(high, low float64) (tr, plusDM, minusDM float64) {
+	up := high - a.prevHigh
+	down := a.prevLow - low
+	if up > down && up > 0 {
+		plusDM = up
+	}
+	if down > up && down > 0 {
+		minusDM = down
+	}
+	return trueRange(high, low, a.prevClose), plusDM, minusDM
+}
+
+// directionalIndex derives +DI, -DI and DX from smoothed TR/+DM/-DM.
+func directionalIndex(tr, plusDM, minusDM float64) (plusDI, minusDI, dx float64) {
+	if tr == 0 {
+		return 0, 0, 0
+	}
+	plusDI = 100.0 * plusDM / tr
+	minusDI = 100.0 * minusDM / tr
+	if sum := plusDI + minusDI; sum > 0 {
+		dx = 100.0 * math.Abs(plusDI-minusDI) / sum
+	}
+	return plusDI, minusDI, dx
+}
+
+func (a *ADX) Value() float64 { return a.adx.Value() }
+func (a *ADX) Ready() bool    { return a.adx.Ready() }
+
+// Components returns the ADX output names: adx, plus_di, minus_di.
+func (a *ADX) Components() []string { return adxComponents }
+
+// Values returns the current ADX, +DI and -DI.
+func (a *ADX) Values() []float64 {
+	return []float64{a.adx.Value(), a.plusDI, a.minusDI}
+}
+
+// Peek computes what ADX would be with the forming candle without mutating state.
+func (a *ADX) Peek(forming model.TFCandle) float64 {
+	return a.PeekValues(forming)[0]
+}
+
+// PeekValues computes ADX, +DI and -DI with the forming candle without mutating state.
+func (a *ADX) PeekValues(forming model.TFCandle) []float64 {
+	if a.count == 0 || a.tr.count+1 < a.period {
+		return a.Values()
+	}
+	tr, pdm, mdm := a.movement(float64(forming.High), float64(forming.Low))
+	plusDI, minusDI, dx := directionalIndex(a.tr.peekPrice(tr), a.plusDM.peekPrice(pdm), a.minusDM.peekPrice(mdm))
+	return []float64{a.adx.peekPrice(dx), plusDI, minusDI}
+}
+
+// Snapshot serializes the ADX state for checkpoint persistence.
+func (a *ADX) Snapshot() IndicatorSnapshot {
+	return IndicatorSnapshot{
+		Type:      "ADX",
+		Period:    a.period,
+		Count:     a.count,
+		PrevHigh:  a.prevHigh,
+		PrevLow:   a.prevLow,
+		PrevClose: a.prevClose,
+		Current:   a.adx.Value(),
+		Children: []IndicatorSnapshot{
+			a.tr.Snapshot(), a.plusDM.Snapshot(), a.minusDM.Snapshot(), a.adx.Snapshot(),
+		},
+	}
+}
+
+// RestoreFromSnapshot restores ADX state from a checkpoint.
+func (a *ADX) RestoreFromSnapshot(snap IndicatorSnapshot) error {
+	if len(snap.Children) != 4 {
+		return fmt.Errorf("ADX snapshot: expected 4 children, got %d", len(snap.Children))
+	}
+	for i, s := range []*SMMA{a.tr, a.plusDM, a.minusDM, a.adx} {
+		if err := s.RestoreFromSnapshot(snap.Children[i]); err != nil {
+			return err
+		}
+	}
+	a.period = snap.Period
+	a.count = snap.Count
+	a.prevHigh = snap.PrevHigh
+	a.prevLow = snap.PrevLow
+	a.prevClose = snap.PrevClose
+	a.plusDI, a.minusDI = 0, 0
+	if a.tr.Ready() {
+		a.plusDI, a.minusDI, _ = directionalIndex(a.tr.Value(), a.plusDM.Value(), a.minusDM.Value())
+	}
+	return nil
+}
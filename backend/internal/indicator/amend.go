@@ -0,0 +1,116 @@
+package indicator
+
+import (
+	"log"
+	"time"
+
+	"trading-systemv1/internal/model"
+)
+
+// amendKey identifies the amendment log of one token on one TF.
+type amendKey struct {
+	tf    int
+	token string // "exchange:token"
+}
+
+// amendEntry is a finalized bar in an amendment log, with the token's
+// indicator state from just before the bar was processed.
+type amendEntry struct {
+	candle model.TFCandle
+	pre    []IndicatorSnapshot
+}
+
+// EnableAmend makes Process recompute revised bars (TFCandle.Revision > 0).
+// The engine then keeps, per token and time TF, the bars that can still be
+// revised within window together with the indicator state before each one,
+// at the cost of snapshotting the indicators on every finalized bar.
+func (e *Engine) EnableAmend(window time.Duration) {
+	e.amendWindow = window
+	e.amends = make(map[amendKey][]amendEntry)
+}
+
+// logBar records tfc and the indicator state before it for amendments.
+// Bar series and Heikin-Ashi series are never revised and are not logged.
+func (e *Engine) logBar(tfc model.TFCandle, ti *tokenIndicators) {
+	if e.amends == nil || model.IsBarTF(tfc.TF) || model.IsHATF(tfc.TF) {
+		return
+	}
+	k := amendKey{tfc.TF, tfc.Key()}
+	pre, err := snapshotAll(ti)
+	if err != nil {
+		delete(e.amends, k) // cannot restore this token: revisions of it are skipped
+		return
+	}
+	entries := append(e.amends[k], amendEntry{candle: tfc, pre: pre})
+	if keep := int(e.amendWindow/(time.Duration(tfc.TF)*time.Second)) + 2; len(entries) > keep {
+		entries = append(entries[:0:0], entries[len(entries)-keep:]...)
+	}
+	e.amends[k] = entries
+}
+
+// amend restores the indicator state from before the revised bar, then
+// replays the bar and every later logged one. It returns the results of all
+// replayed bars, or nil if the bar is no longer logged.
+func (e *Engine) amend(tfIdx int, tfc model.TFCandle) []model.IndicatorResult {
+	k := amendKey{tfc.TF, tfc.Key()}
+	entries := e.amends[k]
+	at := -1
+	for i := range entries {
+		if entries[i].candle.TS.Equal(tfc.TS) {
+			at = i
+			break
+		}
+	}
+	old, exists := e.state[tfIdx][k.token]
+	if at < 0 || !exists {
+		return nil
+	}
+
+	ti := createTokenIndicators(old.configs)
+	for i, ind := range ti.indicators {
+		if err := decodeSnapshot(ind, entries[at].pre[i]); err != nil {
+			log.Printf("[indicator] amend %s tf=%d: restore %s: %v", k.token, tfc.TF, ti.configs[i].Name(), err)
+			return nil
+		}
+	}
+
+	pres := make([][]IndicatorSnapshot, len(entries))
+	var results []model.IndicatorResult
+	for j := at; j < len(entries); j++ {
+		bar := entries[j].candle
+		if j == at {
+			bar = tfc
+		} else {
+			pre, err := snapshotAll(ti)
+			if err != nil {
+				return nil
+			}
+			pres[j] = pre
+		}
+		candle := candleOf(bar)
+		for i, ind := range ti.indicators {
+			ind.Update(candle)
+			results = append(results, newResult(ti.configs[i], ind, bar))
+		}
+	}
+
+	entries[at].candle = tfc
+	for j := at + 1; j < len(entries); j++ {
+		entries[j].pre = pres[j]
+	}
+	e.state[tfIdx][k.token] = ti
+	return results
+}
+
+// snapshotAll encodes the state of every indicator of a token.
+func snapshotAll(ti *tokenIndicators) ([]IndicatorSnapshot, error) {
+	snaps := make([]IndicatorSnapshot, len(ti.indicators))
+	for i, ind := range ti.indicators {
+		s, err := encodeSnapshot(ind)
+		if err != nil {
+			return nil, err
+		}
+		snaps[i] = s
+	}
+	return snaps, nil
+}
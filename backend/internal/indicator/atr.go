@@ -0,0 +1,97 @@
+package indicator
+
+import (
+	"fmt"
+	"math"
+
+	"trading-systemv1/internal/model"
+)
+
+// ATR calculates Average True Range using Wilder's smoothing.
+// TR = max(high-low, |high-prevClose|, |low-prevClose|); the first candle's TR is high-low.
+// O(1) per update.
+type ATR struct {
+	period    int
+	count     int
+	prevClose float64
+	smma      *SMMA
+}
+
+// NewATR creates a new ATR indicator with the given period (typically 14).
+func NewATR(period int) *ATR {
+	return &ATR{period: period, smma: NewSMMA(period)}
+}
+
+func init() {
+	Register(Registration{
+		Type:   "ATR",
+		Params: []ParamSpec{{Name: "length", Kind: ParamInt, Default: 14, Min: 1}},
+		New:    func(cfg IndicatorConfig) Indicator { return NewATR(cfg.Int("length")) },
+	})
+}
+
+func (a *ATR) Name() string { return "ATR" }
+
+func (a *ATR) Update(candle model.Candle) {
+	a.updateHLC(float64(candle.High), float64(candle.Low), float64(candle.Close))
+}
+
+// updateHLC feeds raw high/low/close values. Used directly by indicators
+// built on ATR (e.g. SuperTrend).
+func (a *ATR) updateHLC(high, low, close float64) {
+	a.smma.updatePrice(a.trueRange(high, low))
+	a.prevClose = close
+	a.count++
+}
+
+// trueRange returns the true range of a candle against the previous close.
+func (a *ATR) trueRange(high, low float64) float64 {
+	if a.count == 0 {
+		return high - low
+	}
+	return trueRange(high, low, a.prevClose)
+}
+
+func (a *ATR) Value() float64 { return a.smma.Value() }
+func (a *ATR) Ready() bool    { return a.smma.Ready() }
+
+// Peek computes what ATR would be with the forming candle without mutating state.
+func (a *ATR) Peek(forming model.TFCandle) float64 {
+	return a.peekHL(float64(forming.High), float64(forming.Low))
+}
+
+// peekHL is the raw-value counterpart of Peek.
+func (a *ATR) peekHL(high, low float64) float64 {
+	return a.smma.peekPrice(a.trueRange(high, low))
+}
+
+// Snapshot serializes the ATR state for checkpoint persistence.
+func (a *ATR) Snapshot() IndicatorSnapshot {
+	return IndicatorSnapshot{
+		Type:      "ATR",
+		Period:    a.period,
+		Count:     a.count,
+		PrevClose: a.prevClose,
+		Current:   a.smma.Value(),
+		Children:  []IndicatorSnapshot{a.smma.Snapshot()},
+	}
+}
+
+// RestoreFromSnapshot restores ATR state from a checkpoint.
+func (a *ATR) RestoreFromSnapshot(snap IndicatorSnapshot) error {
+	if len(snap.Children) != 1 {
+		return fmt.Errorf("ATR snapshot: expected 1 child, got %d", len(snap.Children))
+	}
+	if err := a.smma.RestoreFromSnapshot(snap.Children[0]); err != nil {
+		return err
+	}
+	a.period = snap.Period
+	a.count = snap.Count
+	a.prevClose = snap.PrevClose
+	return nil
+}
+
+// trueRange returns max(high-low, |high-prevClose|, |low-prevClose|).
+func trueRange(high, low, prevClose float64) float64 {
+	return math.Max(high-low, math.Max(math.Abs(high-prevClose), math.Abs(low-prevClose)))
+}
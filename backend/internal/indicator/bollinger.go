@@ -0,0 +1,178 @@
+package indicator
+
+import (
+	"math"
+
+	"trading-systemv1/internal/model"
+)
+
+// Bollinger calculates Bollinger Bands: an SMA middle band with upper/lower
+// bands at ±mult population standard deviations.
+// Uses a preallocated circular buffer; deviation is recomputed from the
+// window on each update to avoid running sum-of-squares drift.
+type Bollinger struct {
+	source Source // input price (zero value = close)
+	period int
+	mult   float64
+	buf    []float64 // preallocated circular buffer
+	idx    int       // current write position
+	count  int       // total values received
+	sum    float64
+	mid    float64
+	upper  float64
+	lower  float64
+}
+
+var bollingerComponents = []string{"mid", "upper", "lower"}
+
+// NewBollinger creates a new Bollinger Bands indicator (typically 20, 2.0).
+func NewBollinger(period int, mult float64) *Bollinger {
+	return &Bollinger{
+		period: period,
+		mult:   mult,
+		buf:    make([]float64, period),
+	}
+}
+
+func init() {
+	Register(Registration{
+		Type:    "BB",
+		Overlay: true,
+		Params: []ParamSpec{
+			{Name: "length", Kind: ParamInt, Default: 20, Min: 1},
+			{Name: "mult", Kind: ParamFloat, Default: 2.0, Min: 0.1, Max: 10},
+		},
+		New: func(cfg IndicatorConfig) Indicator { return NewBollinger(cfg.Int("length"), cfg.Float("mult")) },
+	})
+}
+
+func (b *Bollinger) Name() string { return "BB" }
+
+func (b *Bollinger) Update(candle model.Candle) {
+	b.updatePrice(b.source.Price(candle))
+}
+
+// updatePrice feeds a single input value.
+func (b *Bollinger) updatePrice(price float64) {
+	if b.count >= b.period {
+		b.sum -= b.buf[b.idx]
+	}
+	b.buf[b.idx] = price
+	b.sum += price
+	b.idx = (b.idx + 1) % b.period
+	b.count++
+
+	if b.count >= b.period {
+		b.recompute()
+	}
+}
+
+// recompute derives mid/upper/lower from the full window.
+func (b *Bollinger) recompute() {
+	b.mid = b.sum / float64(b.period)
+	dev := b.mult * stddev(b.buf, b.mid)
+	b.upper = b.mid + dev
+	b.lower = b.mid - dev
+}
+
+func (b *Bollinger) Value() float64 { return b.mid }
+func (b *Bollinger) Ready() bool    { return b.count >= b.period }
+
+// Components returns the band names: mid, upper, lower.
+func (b *Bollinger) Components() []string { return bollingerComponents }
+
+// Values returns the current middle, upper and lower bands.
+func (b *Bollinger) Values() []float64 {
+	return []float64{b.mid, b.upper, b.lower}
+}
+
+// Peek computes what the middle band would be with an additional candle without mutating state.
+func (b *Bollinger) Peek(forming model.TFCandle) float64 {
+	return b.PeekValues(forming)[0]
+}
+
+// PeekValues computes all bands with an additional candle without mutating state.
+func (b *Bollinger) PeekValues(forming model.TFCandle) []float64 {
+	return b.peekPriceValues(b.source.FormingPrice(forming))
+}
+
+// peekPrice previews the middle band for a single input value without mutating state.
+func (b *Bollinger) peekPrice(price float64) float64 {
+	return b.peekPriceValues(price)[0]
+}
+
+// peekPriceValues previews all bands for a single input value without mutating state.
+// While warming up it returns the same not-ready zero bands as Values, since a
+// partial window would publish bands that jump once the window fills.
+func (b *Bollinger) peekPriceValues(price float64) []float64 {
+	if !b.Ready() {
+		return b.Values()
+	}
+	// Preview window: the current window with the oldest value (at idx)
+	// replaced by price.
+	sum := b.sum - b.buf[b.idx] + price
+	mid := sum / float64(b.period)
+
+	d := price - mid
+	sq := d * d
+	for i, v := range b.buf {
+		if i == b.idx {
+			continue
+		}
+		d := v - mid
+		sq += d * d
+	}
+	dev := b.mult * math.Sqrt(sq/float64(b.period))
+	return []float64{mid, mid + dev, mid - dev}
+}
+
+// Snapshot serializes the Bollinger state for checkpoint persistence.
+func (b *Bollinger) Snapshot() IndicatorSnapshot {
+	bufCopy := make([]float64, len(b.buf))
+	copy(bufCopy, b.buf)
+	return IndicatorSnapshot{
+		Type:       "BB",
+		Source:     string(b.source),
+		Period:     b.period,
+		Multiplier: b.mult,
+		Buf:        bufCopy,
+		Idx:        b.idx,
+		Count:      b.count,
+		Sum:        b.sum,
+		Current:    b.mid,
+	}
+}
+
+// RestoreFromSnapshot restores Bollinger state from a checkpoint.
+func (b *Bollinger) RestoreFromSnapshot(snap IndicatorSnapshot) error {
+	b.source = Source(snap.Source)
+	b.period = snap.Period
+	b.mult = snap.Multiplier
+	b.idx = snap.Idx
+	b.count = snap.Count
+	b.sum = snap.Sum
+	if len(snap.Buf) > 0 {
+		b.buf = make([]float64, len(snap.Buf))
+		copy(b.buf, snap.Buf)
+	} else {
+		b.buf = make([]float64, snap.Period)
+	}
+	b.mid, b.upper, b.lower = 0, 0, 0
+	if b.count >= b.period {
+		b.recompute()
+	}
+	return nil
+}
+
+// stddev returns the population standard deviation of vals around mean.
+func stddev(vals []float64, mean float64) float64 {
+	var sq float64
+	for _, v := range vals {
+		d := v - mean
+		sq += d * d
+	}
+	return math.Sqrt(sq / float64(len(vals)))
+}
+
+// setSource implements sourced.
+func (b *Bollinger) setSource(src Source) { b.source = src }
@@ -0,0 +1,103 @@
+package indicator
+
+import (
+	"fmt"
+
+	"trading-systemv1/internal/model"
+)
+
+// priceInput is implemented by indicators that can consume an arbitrary
+// value series instead of candle prices (SMA, EMA, SMMA, RSI, MACD, BB).
+// Only these can be the outer stage of a Chain.
+type priceInput interface {
+	Indicator
+	updatePrice(price float64)
+	peekPrice(price float64) float64
+}
+
+// multiPriceInput is the multi-output counterpart of priceInput.
+type multiPriceInput interface {
+	priceInput
+	MultiOutput
+	peekPriceValues(price float64) []float64
+}
+
+// Chain feeds the output of an inner indicator into an outer one,
+// e.g. EMA(9) of RSI(14). The outer indicator only sees inner values once
+// the inner indicator is ready, so the chain warms up in sequence.
+// Chains nest: the inner indicator may itself be a Chain.
+type Chain struct {
+	outer priceInput
+	inner Indicator
+}
+
+// NewChain creates outer-of-inner. If outer has several outputs (e.g. BB of
+// RSI) the returned indicator also implements MultiOutput.
+func NewChain(outer priceInput, inner Indicator) Indicator {
+	c := &Chain{outer: outer, inner: inner}
+	if mo, ok := outer.(multiPriceInput); ok {
+		return &multiChain{Chain: c, outer: mo}
+	}
+	return c
+}
+
+func (c *Chain) Name() string { return c.outer.Name() }
+
+func (c *Chain) Update(candle model.Candle) {
+	c.inner.Update(candle)
+	if c.inner.Ready() {
+		c.outer.updatePrice(c.inner.Value())
+	}
+}
+
+func (c *Chain) Value() float64 { return c.outer.Value() }
+func (c *Chain) Ready() bool    { return c.outer.Ready() }
+
+// Peek previews the inner indicator and feeds its preview into the outer
+// one, without mutating either.
+func (c *Chain) Peek(forming model.TFCandle) float64 {
+	if !c.inner.Ready() {
+		return c.outer.Value()
+	}
+	return c.outer.peekPrice(c.inner.Peek(forming))
+}
+
+// Snapshot serializes the chain as the outer state with the inner state
+// nested under Input. Both stages are built from registered types, so their
+// codecs always resolve.
+func (c *Chain) Snapshot() IndicatorSnapshot {
+	snap, _ := encodeSnapshot(c.outer)
+	inner, _ := encodeSnapshot(c.inner)
+	snap.Input = &inner
+	return snap
+}
+
+// RestoreFromSnapshot restores both stages of the chain from a checkpoint.
+func (c *Chain) RestoreFromSnapshot(snap IndicatorSnapshot) error {
+	if snap.Input == nil {
+		return fmt.Errorf("%s chain snapshot: missing input", snap.Type)
+	}
+	if err := decodeSnapshot(c.inner, *snap.Input); err != nil {
+		return err
+	}
+	outer := snap
+	outer.Input = nil
+	return decodeSnapshot(c.outer, outer)
+}
+
+// multiChain is a Chain whose outer indicator has several outputs.
+type multiChain struct {
+	*Chain
+	outer multiPriceInput
+}
+
+func (c *multiChain) Components() []string { return c.outer.Components() }
+func (c *multiChain) Values() []float64    { return c.outer.Values() }
+
+// PeekValues previews all outer components through the chain.
+func (c *multiChain) PeekValues(forming model.TFCandle) []float64 {
+	if !c.inner.Ready() {
+		return c.outer.Values()
+	}
+	return c.outer.peekPriceValues(c.inner.Peek(forming))
+}
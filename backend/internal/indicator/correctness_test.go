@@ -1,9 +1,12 @@
 package indicator
 
 import (
+	"fmt"
 	"math"
 	"testing"
+	"time"
 
+	"trading-systemv1/internal/markethours"
 	"trading-systemv1/internal/model"
 )
 
@@ -18,6 +21,15 @@ func candle(closePaise int64) model.Candle {
 	}
 }
 
+// forming builds a forming TF candle with the same shape as candle().
+func forming(closePaise int64) model.TFCandle {
+	return model.TFCandle{
+		Token: "TEST", Exchange: "NSE", TF: 60,
+		Open: closePaise, High: closePaise + 50, Low: closePaise - 50, Close: closePaise,
+		Forming: true,
+	}
+}
+
 func assertClose(t *testing.T, label string, got, want, tol float64) {
 	t.Helper()
 	if math.Abs(got-want) > tol {
@@ -82,7 +94,7 @@ func TestSMA_Peek_DoesNotMutate(t *testing.T) {
 	valueBefore := sma.Value()
 
 	// Peek with different price
-	peekVal := sma.Peek(20000) // 200 rupees
+	peekVal := sma.Peek(forming(20000)) // 200 rupees
 	_ = peekVal
 
 	// Value should be unchanged
@@ -96,7 +108,7 @@ func TestSMA_Peek_CorrectValue(t *testing.T) {
 		sma.Update(candle(p))
 	}
 	// Peek with 10600 → expected: (10200+10400+10600)/3 = 10400
-	peekVal := sma.Peek(10600)
+	peekVal := sma.Peek(forming(10600))
 	assertClose(t, "SMA Peek", peekVal, 10400.0, 0.01)
 }
 
@@ -168,7 +180,7 @@ func TestEMA_Peek_DoesNotMutate(t *testing.T) {
 	}
 	valueBefore := ema.Value()
 
-	ema.Peek(20000)
+	ema.Peek(forming(20000))
 
 	assertClose(t, "EMA after Peek", ema.Value(), valueBefore, 0.0001)
 }
@@ -180,10 +192,18 @@ func TestEMA_Peek_CorrectValue(t *testing.T) {
 		ema.Update(candle(p))
 	}
 	// Peek with 10600: EMA = 10600*0.5 + 10200*0.5 = 10400.0
-	peekVal := ema.Peek(10600)
+	peekVal := ema.Peek(forming(10600))
 	assertClose(t, "EMA Peek", peekVal, 10400.0, 0.01)
 }
 
+func TestEMA_Peek_SeedsAtBoundary(t *testing.T) {
+	ema := NewEMA(3)
+	ema.Update(candle(10000))
+	ema.Update(candle(10200))
+	// The third value completes the window, so Peek previews the SMA seed.
+	assertClose(t, "EMA seed Peek", ema.Peek(forming(10600)), 10266.6667, 0.001)
+}
+
 // ────────────────────────────────────────────────────────────
 // SMMA Correctness (Wilder's Smoothing)
 // ────────────────────────────────────────────────────────────
@@ -219,7 +239,7 @@ func TestSMMA_Peek_DoesNotMutate(t *testing.T) {
 	}
 	valueBefore := smma.Value()
 
-	smma.Peek(20000)
+	smma.Peek(forming(20000))
 
 	assertClose(t, "SMMA after Peek", smma.Value(), valueBefore, 0.0001)
 }
@@ -231,7 +251,7 @@ func TestSMMA_Peek_CorrectValue(t *testing.T) {
 		smma.Update(candle(p))
 	}
 	// Peek with 10600: SMMA = (10200.0 * 2 + 10600) / 3 = 31000/3 = 10333.3333
-	peekVal := smma.Peek(10600)
+	peekVal := smma.Peek(forming(10600))
 	assertClose(t, "SMMA Peek", peekVal, 10333.3333, 0.1)
 }
 
@@ -339,7 +359,7 @@ func TestRSI_Peek_DoesNotMutate(t *testing.T) {
 	}
 	valueBefore := rsi.Value()
 
-	rsi.Peek(5000)
+	rsi.Peek(forming(5000))
 
 	assertClose(t, "RSI after Peek", rsi.Value(), valueBefore, 0.0001)
 }
@@ -353,12 +373,404 @@ func TestRSI_Peek_CorrectDirection(t *testing.T) {
 	// RSI is high (100 = all gains)
 
 	// Peek with a lower price → RSI should decrease
-	peekDown := rsi.Peek(8000) // significant drop
+	peekDown := rsi.Peek(forming(8000)) // significant drop
 	if peekDown >= rsi.Value() {
 		t.Errorf("RSI Peek with lower price should decrease: peek=%.2f, current=%.2f", peekDown, rsi.Value())
 	}
 }
 
+// ────────────────────────────────────────────────────────────
+// MACD Correctness
+// ────────────────────────────────────────────────────────────
+
+func TestMACD_Correctness_MatchesEMAs(t *testing.T) {
+	// MACD(2,3,2): macd = EMA(2) - EMA(3) once EMA(3) is seeded,
+	// signal = EMA(2) of the macd series (SMA-seeded), hist = macd - signal.
+	macd := NewMACD(2, 3, 2)
+	fast, slow := NewEMA(2), NewEMA(3)
+	prices := []int64{10000, 10200, 10400, 10300, 10500, 10800, 10600}
+
+	var macdSeries []float64
+	signal := 0.0
+	for i, p := range prices {
+		macd.Update(candle(p))
+		fast.Update(candle(p))
+		slow.Update(candle(p))
+		if !slow.Ready() {
+			continue
+		}
+		m := fast.Value() - slow.Value()
+		macdSeries = append(macdSeries, m)
+		switch {
+		case len(macdSeries) == 2:
+			signal = (macdSeries[0] + macdSeries[1]) / 2
+		case len(macdSeries) > 2:
+			signal = m*(2.0/3.0) + signal*(1.0/3.0)
+		}
+
+		assertClose(t, "MACD line", macd.Value(), m, 1e-9)
+		if len(macdSeries) >= 2 {
+			if !macd.Ready() {
+				t.Fatalf("candle %d: expected MACD ready", i)
+			}
+			vals := macd.Values()
+			assertClose(t, "MACD signal", vals[1], signal, 1e-9)
+			assertClose(t, "MACD hist", vals[2], m-signal, 1e-9)
+		} else if macd.Ready() {
+			t.Errorf("candle %d: MACD ready before signal seeded", i)
+		}
+	}
+}
+
+func TestMACD_PeekValues_MatchesUpdate(t *testing.T) {
+	macd := NewMACD(3, 5, 3)
+	for i := 0; i < 12; i++ {
+		macd.Update(candle(int64(10000 + (i%4)*150)))
+	}
+	before := macd.Values()
+	peek := macd.PeekValues(forming(10900))
+	for i, v := range macd.Values() {
+		assertClose(t, "MACD after PeekValues", v, before[i], 0)
+	}
+
+	macd.Update(candle(10900))
+	for i, v := range macd.Values() {
+		assertClose(t, "MACD peek vs update "+macd.Components()[i], peek[i], v, 1e-9)
+	}
+}
+
+func TestMACD_PeekValues_MatchesUpdateDuringWarmUp(t *testing.T) {
+	// Every step from the first candle on, including the ones that seed
+	// the slow EMA and the signal line.
+	macd := NewMACD(2, 4, 3)
+	for i := 0; i < 10; i++ {
+		price := int64(10000 + (i%3)*120 + i*40)
+		peek := macd.PeekValues(forming(price))
+		macd.Update(candle(price))
+		for j, v := range macd.Values() {
+			assertClose(t, fmt.Sprintf("candle %d %s", i, macd.Components()[j]), peek[j], v, 1e-9)
+		}
+	}
+}
+
+// ────────────────────────────────────────────────────────────
+// Bollinger Bands Correctness
+// ────────────────────────────────────────────────────────────
+
+func TestBollinger_Correctness_Period3(t *testing.T) {
+	// Prices: 10000, 10200, 10400 → mid = 10200
+	// Population variance = (200² + 0 + 200²)/3 = 26666.67, σ = 163.2993
+	// upper = 10200 + 2σ = 10526.5986, lower = 9873.4014
+	bb := NewBollinger(3, 2.0)
+	for _, p := range []int64{10000, 10200, 10400} {
+		bb.Update(candle(p))
+	}
+	if !bb.Ready() {
+		t.Fatal("expected BB ready after 3 candles")
+	}
+	vals := bb.Values()
+	assertClose(t, "BB mid", vals[0], 10200.0, 0.001)
+	assertClose(t, "BB upper", vals[1], 10526.5986, 0.001)
+	assertClose(t, "BB lower", vals[2], 9873.4014, 0.001)
+}
+
+func TestBollinger_PeekValues_MatchesUpdate(t *testing.T) {
+	bb := NewBollinger(3, 2.0)
+	for _, p := range []int64{10000, 10200, 10400} {
+		bb.Update(candle(p))
+	}
+	// Peek with 10600 → window 10200, 10400, 10600: mid 10400, σ 163.2993
+	peek := bb.PeekValues(forming(10600))
+	assertClose(t, "BB peek mid", peek[0], 10400.0, 0.001)
+	assertClose(t, "BB peek upper", peek[1], 10726.5986, 0.001)
+	assertClose(t, "BB after PeekValues", bb.Value(), 10200.0, 0)
+
+	bb.Update(candle(10600))
+	for i, v := range bb.Values() {
+		assertClose(t, "BB peek vs update", peek[i], v, 1e-9)
+	}
+}
+
+func TestBollinger_PeekNotReadyDuringWarmUp(t *testing.T) {
+	bb := NewBollinger(3, 2.0)
+	for _, p := range []int64{10000, 10200} {
+		bb.Update(candle(p))
+		for i, v := range bb.PeekValues(forming(10600)) {
+			assertClose(t, "BB warm-up peek", v, bb.Values()[i], 0)
+			assertClose(t, "BB warm-up peek is zero", v, 0, 0)
+		}
+		assertClose(t, "BB warm-up Peek", bb.Peek(forming(10600)), 0, 0)
+	}
+}
+
+// ────────────────────────────────────────────────────────────
+// Stochastic Correctness
+// ────────────────────────────────────────────────────────────
+
+func TestStochastic_Correctness_Period3(t *testing.T) {
+	// STOCH(3,1,2) on (H, L, C):
+	//   (110, 90, 100), (120, 95, 115), (118, 100, 105), (125, 105, 120)
+	// candle 3: HH=120, LL=90, %K = 100*(105-90)/30 = 50
+	// candle 4: HH=125, LL=95, %K = 100*(120-95)/30 = 83.3333
+	// %D(2) after candle 4 = (50 + 83.3333)/2 = 66.6667
+	st := NewStochastic(3, 1, 2)
+	bars := [][3]int64{{110, 90, 100}, {120, 95, 115}, {118, 100, 105}, {125, 105, 120}}
+	for _, b := range bars {
+		st.Update(model.Candle{High: b[0], Low: b[1], Close: b[2]})
+	}
+	if !st.Ready() {
+		t.Fatal("expected STOCH ready after 4 candles")
+	}
+	vals := st.Values()
+	assertClose(t, "STOCH %K", vals[0], 83.3333, 0.001)
+	assertClose(t, "STOCH %D", vals[1], 66.6667, 0.001)
+}
+
+func TestStochastic_PeekValues_UsesFormingRange(t *testing.T) {
+	// The forming candle's high/low must widen the lookback range exactly as
+	// Update() would: peek on (H, L, C) == values after Update with the same bar.
+	st := NewStochastic(3, 2, 2)
+	bars := [][3]int64{{110, 90, 100}, {120, 95, 115}, {118, 100, 105}, {125, 105, 120}, {122, 108, 110}}
+	for _, b := range bars {
+		st.Update(model.Candle{High: b[0], Low: b[1], Close: b[2]})
+	}
+
+	next := model.TFCandle{High: 140, Low: 101, Close: 130, Forming: true}
+	peek := st.PeekValues(next)
+
+	st.Update(model.Candle{High: next.High, Low: next.Low, Close: next.Close})
+	for i, v := range st.Values() {
+		assertClose(t, "STOCH peek vs update "+st.Components()[i], peek[i], v, 1e-9)
+	}
+}
+
+func TestStochastic_Peek_DoesNotMutate(t *testing.T) {
+	st := NewStochastic(5, 3, 3)
+	for i := 0; i < 15; i++ {
+		st.Update(candle(int64(10000 + (i%5)*100)))
+	}
+	before := st.Values()
+	st.PeekValues(forming(5000))
+	for i, v := range st.Values() {
+		assertClose(t, "STOCH after PeekValues", v, before[i], 0)
+	}
+}
+
+// ────────────────────────────────────────────────────────────
+// ATR / ADX / SuperTrend / Parabolic SAR Correctness
+// ────────────────────────────────────────────────────────────
+
+// hlc builds a candle from explicit high/low/close (paise).
+func hlc(high, low, close int64) model.Candle {
+	return model.Candle{Token: "TEST", Exchange: "NSE", Open: close, High: high, Low: low, Close: close}
+}
+
+func TestATR_Correctness_Period3(t *testing.T) {
+	// (H, L, C): (110, 90, 100), (120, 95, 115), (118, 100, 105), (125, 105, 120)
+	// TR: 20 (first = H-L), 25, max(18, 3, 15) = 18, max(20, 20, 0) = 20
+	// ATR(3) after candle 3 = (20+25+18)/3 = 21
+	// ATR(3) after candle 4 = (21*2 + 20)/3 = 20.6667
+	atr := NewATR(3)
+	bars := []model.Candle{hlc(110, 90, 100), hlc(120, 95, 115), hlc(118, 100, 105), hlc(125, 105, 120)}
+	expected := []float64{0, 0, 21.0, 20.6667}
+	for i, c := range bars {
+		atr.Update(c)
+		if atr.Ready() != (i >= 2) {
+			t.Errorf("candle %d: Ready()=%v", i, atr.Ready())
+		}
+		if i >= 2 {
+			assertClose(t, "ATR(3)", atr.Value(), expected[i], 0.001)
+		}
+	}
+}
+
+func TestATR_Peek_UsesFormingRange(t *testing.T) {
+	atr := NewATR(3)
+	for _, c := range []model.Candle{hlc(110, 90, 100), hlc(120, 95, 115), hlc(118, 100, 105)} {
+		atr.Update(c)
+	}
+	// Forming (125, 105, 120) → TR 20 → 20.6667; same close with a wider range must be larger
+	assertClose(t, "ATR peek", atr.Peek(model.TFCandle{High: 125, Low: 105, Close: 120}), 20.6667, 0.001)
+	if wide := atr.Peek(model.TFCandle{High: 160, Low: 80, Close: 120}); wide <= 20.6667 {
+		t.Errorf("ATR peek should grow with forming range, got %.4f", wide)
+	}
+	assertClose(t, "ATR after Peek", atr.Value(), 21.0, 0)
+}
+
+func TestADX_Uptrend(t *testing.T) {
+	adx := NewADX(3)
+	for i := 0; i < 12; i++ {
+		base := int64(10000 + i*100)
+		adx.Update(hlc(base+60, base-40, base+40))
+		// +DI/-DI need period+1 candles, ADX needs 2*period
+		if adx.Ready() != (i >= 5) {
+			t.Errorf("candle %d: Ready()=%v", i, adx.Ready())
+		}
+	}
+	vals := adx.Values()
+	if vals[1] <= vals[2] {
+		t.Errorf("+DI should exceed -DI in uptrend: +DI=%.2f -DI=%.2f", vals[1], vals[2])
+	}
+	// Every candle is pure up-movement → DX = 100 → ADX = 100
+	assertClose(t, "ADX strong trend", vals[0], 100.0, 0.001)
+}
+
+func TestADX_PeekValues_MatchesUpdate(t *testing.T) {
+	adx := NewADX(4)
+	for i := 0; i < 15; i++ {
+		base := int64(10000 + (i%5)*80 - (i%3)*60)
+		adx.Update(hlc(base+70, base-50, base))
+	}
+	next := model.TFCandle{High: 10400, Low: 10050, Close: 10300, Forming: true}
+	peek := adx.PeekValues(next)
+
+	adx.Update(hlc(next.High, next.Low, next.Close))
+	for i, v := range adx.Values() {
+		assertClose(t, "ADX peek vs update "+adx.Components()[i], peek[i], v, 1e-9)
+	}
+}
+
+func TestSuperTrend_Direction(t *testing.T) {
+	st := NewSuperTrend(3, 1.0)
+	// Steady uptrend: starts down, flips up once close clears the upper band
+	for i := 0; i < 10; i++ {
+		base := int64(10000 + i*200)
+		st.Update(hlc(base+50, base-50, base+40))
+	}
+	vals := st.Values()
+	if vals[1] != 1 {
+		t.Fatalf("expected up direction in uptrend, got %.0f", vals[1])
+	}
+	if vals[0] >= 10000+9*200+40 {
+		t.Errorf("SuperTrend line should be below close in uptrend, got %.2f", vals[0])
+	}
+
+	// Sharp drop below the lower band flips the trend down
+	st.Update(hlc(11000, 10000, 10100))
+	vals = st.Values()
+	if vals[1] != -1 {
+		t.Fatalf("expected down direction after crash, got %.0f", vals[1])
+	}
+	if vals[0] <= 10100 {
+		t.Errorf("SuperTrend line should be above close in downtrend, got %.2f", vals[0])
+	}
+}
+
+func TestSuperTrend_PeekValues_MatchesUpdate(t *testing.T) {
+	st := NewSuperTrend(3, 2.0)
+	for i := 0; i < 10; i++ {
+		base := int64(10000 + (i%4)*150)
+		st.Update(hlc(base+80, base-80, base))
+	}
+	next := model.TFCandle{High: 10900, Low: 10600, Close: 10850, Forming: true}
+	peek := st.PeekValues(next)
+
+	st.Update(hlc(next.High, next.Low, next.Close))
+	for i, v := range st.Values() {
+		assertClose(t, "SuperTrend peek vs update", peek[i], v, 1e-9)
+	}
+}
+
+func TestPSAR_Correctness(t *testing.T) {
+	// step 0.02, max 0.2
+	// c2: close up → rising, SAR = L1 = 800
+	// c3: 800 + .02*(1100-800) = 806 → capped by min(L2, L1) = 800; EP → 1200, AF → .04
+	// c4: 800 + .04*(1200-800) = 816 → min(816, 1000, 900) = 816; EP → 1300, AF → .06
+	// c5: 816 + .06*(1300-816) = 845.04 > low 700 → reverse: SAR = EP 1300
+	psar := NewPSAR(0.02, 0.2)
+	bars := []model.Candle{hlc(1000, 800, 900), hlc(1100, 900, 1050), hlc(1200, 1000, 1150), hlc(1300, 1150, 1250), hlc(900, 700, 750)}
+	expected := []float64{0, 800, 800, 816, 1300}
+	for i, c := range bars {
+		psar.Update(c)
+		if psar.Ready() != (i >= 1) {
+			t.Errorf("candle %d: Ready()=%v", i, psar.Ready())
+		}
+		if i >= 1 {
+			assertClose(t, "PSAR", psar.Value(), expected[i], 0.001)
+		}
+	}
+}
+
+func TestPSAR_Peek_MatchesUpdate(t *testing.T) {
+	psar := NewPSAR(0.02, 0.2)
+	for _, c := range []model.Candle{hlc(1000, 800, 900), hlc(1100, 900, 1050), hlc(1200, 1000, 1150)} {
+		psar.Update(c)
+	}
+	next := model.TFCandle{High: 1300, Low: 1150, Close: 1250}
+	peek := psar.Peek(next)
+	assertClose(t, "PSAR after Peek", psar.Value(), 800, 0)
+
+	psar.Update(hlc(next.High, next.Low, next.Close))
+	assertClose(t, "PSAR peek vs update", peek, psar.Value(), 1e-9)
+}
+
+// ────────────────────────────────────────────────────────────
+// VWAP Correctness
+// ────────────────────────────────────────────────────────────
+
+// sessionCandle builds a candle at hh:mm IST on the given 2026 March day.
+func sessionCandle(day, hh, mm int, high, low, close, volume int64) model.Candle {
+	return model.Candle{
+		TS:   time.Date(2026, time.March, day, hh, mm, 0, 0, markethours.IST).UTC(),
+		High: high, Low: low, Close: close, Volume: volume,
+	}
+}
+
+func TestVWAP_Correctness_Session(t *testing.T) {
+	// Typical prices 100 (vol 100) and 120 (vol 300):
+	// VWAP = (100*100 + 120*300)/400 = 115
+	// σ² = (100²*100 + 120²*300)/400 - 115² = 75 → σ = 8.6603
+	vwap := NewVWAP(VWAPAnchorSession)
+	vwap.Update(sessionCandle(2, 9, 15, 110, 90, 100, 100))
+	vwap.Update(sessionCandle(2, 9, 16, 130, 110, 120, 300))
+
+	vals := vwap.Values()
+	assertClose(t, "VWAP", vals[0], 115.0, 0.0001)
+	assertClose(t, "VWAP +1σ", vals[1], 123.6603, 0.0001)
+	assertClose(t, "VWAP -1σ", vals[2], 106.3397, 0.0001)
+	assertClose(t, "VWAP +2σ", vals[3], 132.3205, 0.0001)
+	assertClose(t, "VWAP -2σ", vals[4], 97.6795, 0.0001)
+
+	// Next session's first candle resets the accumulation
+	vwap.Update(sessionCandle(3, 9, 15, 210, 190, 200, 50))
+	vals = vwap.Values()
+	assertClose(t, "VWAP after session reset", vals[0], 200.0, 0.0001)
+	assertClose(t, "VWAP band after session reset", vals[1], 200.0, 0.0001)
+}
+
+func TestVWAP_WeekAnchor_SpansSessions(t *testing.T) {
+	// 2026-03-02 is a Monday, 2026-03-09 the following Monday
+	vwap := NewVWAP(VWAPAnchorWeek)
+	vwap.Update(sessionCandle(2, 10, 0, 110, 90, 100, 100))
+	vwap.Update(sessionCandle(4, 10, 0, 130, 110, 120, 300))
+	assertClose(t, "weekly VWAP across sessions", vwap.Value(), 115.0, 0.0001)
+
+	vwap.Update(sessionCandle(9, 9, 15, 210, 190, 200, 50))
+	assertClose(t, "weekly VWAP after week reset", vwap.Value(), 200.0, 0.0001)
+}
+
+func TestVWAP_NoVolume_EqualWeighted(t *testing.T) {
+	vwap := NewVWAP(VWAPAnchorSession)
+	vwap.Update(sessionCandle(2, 9, 15, 110, 90, 100, 0))
+	vwap.Update(sessionCandle(2, 9, 16, 130, 110, 120, 0))
+	assertClose(t, "VWAP without volume", vwap.Value(), 110.0, 0.0001)
+}
+
+func TestVWAP_PeekValues_MatchesUpdate(t *testing.T) {
+	vwap := NewVWAP(VWAPAnchorSession)
+	vwap.Update(sessionCandle(2, 9, 15, 110, 90, 100, 100))
+	vwap.Update(sessionCandle(2, 9, 16, 130, 110, 120, 300))
+
+	next := sessionCandle(2, 9, 17, 140, 120, 125, 200)
+	peek := vwap.PeekValues(model.TFCandle{TS: next.TS, High: next.High, Low: next.Low, Close: next.Close, Volume: next.Volume})
+	assertClose(t, "VWAP after PeekValues", vwap.Value(), 115.0, 0)
+
+	vwap.Update(next)
+	for i, v := range vwap.Values() {
+		assertClose(t, "VWAP peek vs update "+vwap.Components()[i], peek[i], v, 1e-9)
+	}
+}
+
 // ────────────────────────────────────────────────────────────
 // Cross-indicator: same data → correct ordering
 // ────────────────────────────────────────────────────────────
@@ -426,6 +838,90 @@ func TestEMA_MoreResponsiveThanSMA(t *testing.T) {
 	}
 }
 
+// ────────────────────────────────────────────────────────────
+// Indicator chains
+// ────────────────────────────────────────────────────────────
+
+func TestChain_EMAofRSI_MatchesManual(t *testing.T) {
+	chain := NewChain(NewEMA(3), NewRSI(4))
+	rsi := NewRSI(4)
+	ema := NewEMA(3)
+
+	prices := []int64{10000, 10100, 10050, 10200, 10150, 10300, 10250, 10400, 10350, 10100, 10000, 10200}
+	for i, p := range prices {
+		chain.Update(candle(p))
+		rsi.Update(candle(p))
+		if rsi.Ready() {
+			ema.updatePrice(rsi.Value())
+		}
+		if chain.Ready() != ema.Ready() {
+			t.Fatalf("candle %d: chain Ready()=%v, want %v", i, chain.Ready(), ema.Ready())
+		}
+		assertClose(t, "EMA(3) of RSI(4)", chain.Value(), ema.Value(), 1e-9)
+	}
+	if !chain.Ready() {
+		t.Fatal("expected chain to be ready")
+	}
+
+	// RSI needs 5 candles, then EMA(3) needs 3 RSI values
+	late := NewChain(NewEMA(3), NewRSI(4))
+	for i, p := range prices[:7] {
+		late.Update(candle(p))
+		if late.Ready() != (i >= 6) {
+			t.Errorf("candle %d: Ready()=%v during sequential warm-up", i, late.Ready())
+		}
+	}
+}
+
+func TestChain_PeekFlowsThroughChain(t *testing.T) {
+	chain := NewChain(NewSMA(3), NewRSI(4))
+	rsi := NewRSI(4)
+	sma := NewSMA(3)
+	for _, p := range []int64{10000, 10100, 10050, 10200, 10150, 10300, 10250, 10400} {
+		chain.Update(candle(p))
+		rsi.Update(candle(p))
+		if rsi.Ready() {
+			sma.updatePrice(rsi.Value())
+		}
+	}
+
+	before := chain.Value()
+	want := sma.peekPrice(rsi.Peek(forming(10600)))
+	assertClose(t, "chain peek", chain.Peek(forming(10600)), want, 1e-9)
+	assertClose(t, "chain unchanged after peek", chain.Value(), before, 0)
+}
+
+func TestChain_MultiOutputOuter(t *testing.T) {
+	chain := NewChain(NewBollinger(3, 2.0), NewRSI(4))
+	mo, ok := chain.(MultiOutput)
+	if !ok {
+		t.Fatal("expected BB of RSI to implement MultiOutput")
+	}
+	for _, p := range []int64{10000, 10100, 10050, 10200, 10150, 10300, 10250, 10400} {
+		chain.Update(candle(p))
+	}
+	if !chain.Ready() {
+		t.Fatal("expected chain to be ready")
+	}
+	vals := mo.Values()
+	if !(vals[1] > vals[0] && vals[0] > vals[2]) || vals[0] <= 0 || vals[0] >= 100 {
+		t.Errorf("expected RSI-scaled bands upper > mid > lower, got %v", vals)
+	}
+	peek := mo.PeekValues(forming(10600))
+	assertClose(t, "BB of RSI peek mid", chain.Peek(forming(10600)), peek[0], 1e-9)
+}
+
+func TestSourceVolume(t *testing.T) {
+	sma := NewSMA(2)
+	sma.setSource(SourceVol)
+	for _, v := range []int64{100, 300, 500} {
+		c := candle(10000)
+		c.Volume = v
+		sma.Update(c)
+	}
+	assertClose(t, "SMA(2) of volume", sma.Value(), 400, 1e-9)
+}
+
 // ────────────────────────────────────────────────────────────
 // Snapshot round-trip correctness
 // ────────────────────────────────────────────────────────────
@@ -507,3 +1003,144 @@ func TestSMMA_SnapshotRoundTrip(t *testing.T) {
 	smma2.Update(candle(10700))
 	assertClose(t, "SMMA after restoration + update", smma2.Value(), smma.Value(), 0.0001)
 }
+
+func TestMACD_SnapshotRoundTrip(t *testing.T) {
+	macd := NewMACD(3, 6, 4)
+	for i := 0; i < 15; i++ {
+		macd.Update(candle(int64(10000 + i*37)))
+	}
+	snap := macd.Snapshot()
+
+	macd2 := NewMACD(3, 6, 4)
+	if err := macd2.RestoreFromSnapshot(snap); err != nil {
+		t.Fatal(err)
+	}
+
+	macd.Update(candle(10700))
+	macd2.Update(candle(10700))
+	for i, v := range macd.Values() {
+		assertClose(t, "MACD after restoration + update", macd2.Values()[i], v, 0.0001)
+	}
+}
+
+func TestBollinger_SnapshotRoundTrip(t *testing.T) {
+	bb := NewBollinger(4, 2.0)
+	for _, p := range []int64{10000, 10200, 10400, 10300, 10500, 10100} {
+		bb.Update(candle(p))
+	}
+	snap := bb.Snapshot()
+
+	bb2 := NewBollinger(4, 2.0)
+	if err := bb2.RestoreFromSnapshot(snap); err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range bb.Values() {
+		assertClose(t, "BB snapshot round-trip", bb2.Values()[i], v, 0.0001)
+	}
+
+	bb.Update(candle(10700))
+	bb2.Update(candle(10700))
+	for i, v := range bb.Values() {
+		assertClose(t, "BB after restoration + update", bb2.Values()[i], v, 0.0001)
+	}
+}
+
+func TestStochastic_SnapshotRoundTrip(t *testing.T) {
+	st := NewStochastic(5, 3, 3)
+	for i := 0; i < 12; i++ {
+		st.Update(candle(int64(10000 + (i%4)*120)))
+	}
+	snap := st.Snapshot()
+
+	st2 := NewStochastic(5, 3, 3)
+	if err := st2.RestoreFromSnapshot(snap); err != nil {
+		t.Fatal(err)
+	}
+
+	st.Update(candle(10700))
+	st2.Update(candle(10700))
+	for i, v := range st.Values() {
+		assertClose(t, "STOCH after restoration + update", st2.Values()[i], v, 0.0001)
+	}
+}
+
+func TestPSAR_SnapshotConfig(t *testing.T) {
+	snap := NewPSAR(0.03, 0.25).Snapshot()
+	if snap.Period != 0 {
+		t.Errorf("PSAR snapshot Period=%d, want 0 (no period)", snap.Period)
+	}
+	if got := snap.config().Name(); got != "PSAR_0.03_0.25" {
+		t.Errorf("PSAR snapshot config=%s, want PSAR_0.03_0.25", got)
+	}
+}
+
+func TestATR_ADX_SuperTrend_PSAR_SnapshotRoundTrip(t *testing.T) {
+	newAll := func() []Snapshottable {
+		return []Snapshottable{NewATR(4), NewADX(4), NewSuperTrend(4, 2.0), NewPSAR(0.02, 0.2)}
+	}
+	orig := newAll()
+	for i := 0; i < 14; i++ {
+		base := int64(10000 + (i%5)*90 + i*20)
+		for _, ind := range orig {
+			ind.Update(hlc(base+70, base-60, base+10))
+		}
+	}
+
+	restored := newAll()
+	for i, ind := range orig {
+		if err := restored[i].RestoreFromSnapshot(ind.Snapshot()); err != nil {
+			t.Fatalf("%s: restore failed: %v", ind.Name(), err)
+		}
+		assertClose(t, ind.Name()+" snapshot round-trip", restored[i].Value(), ind.Value(), 0.0001)
+	}
+
+	next := hlc(10800, 10500, 10750)
+	for i, ind := range orig {
+		ind.Update(next)
+		restored[i].Update(next)
+		assertClose(t, ind.Name()+" after restoration + update", restored[i].Value(), ind.Value(), 0.0001)
+	}
+}
+
+func TestVWAP_SnapshotRoundTrip(t *testing.T) {
+	vwap := NewVWAP(VWAPAnchorSession)
+	vwap.Update(sessionCandle(2, 9, 15, 110, 90, 100, 100))
+	vwap.Update(sessionCandle(2, 9, 16, 130, 110, 120, 300))
+	snap := vwap.Snapshot()
+
+	vwap2 := NewVWAP(VWAPAnchorSession)
+	if err := vwap2.RestoreFromSnapshot(snap); err != nil {
+		t.Fatal(err)
+	}
+	assertClose(t, "VWAP snapshot round-trip", vwap2.Value(), vwap.Value(), 0.0001)
+
+	// Same session continues accumulating after restore
+	next := sessionCandle(2, 9, 17, 140, 120, 125, 200)
+	vwap.Update(next)
+	vwap2.Update(next)
+	for i, v := range vwap.Values() {
+		assertClose(t, "VWAP after restoration + update", vwap2.Values()[i], v, 0.0001)
+	}
+}
+
+func TestChain_SnapshotRoundTrip(t *testing.T) {
+	newChain := func() Indicator { return NewChain(NewEMA(3), NewChain(NewSMA(2), NewRSI(4))) }
+	chain := newChain()
+	for _, p := range []int64{10000, 10100, 10050, 10200, 10150, 10300, 10250, 10400} {
+		chain.Update(candle(p))
+	}
+	snap := chain.(Snapshottable).Snapshot()
+	if snap.Input == nil || snap.Input.Input == nil || snap.Input.Input.Type != "RSI" {
+		t.Fatalf("expected nested chain snapshot, got %+v", snap)
+	}
+
+	chain2 := newChain()
+	if err := chain2.(Snapshottable).RestoreFromSnapshot(snap); err != nil {
+		t.Fatal(err)
+	}
+	assertClose(t, "chain snapshot round-trip", chain2.Value(), chain.Value(), 0.0001)
+
+	chain.Update(candle(10700))
+	chain2.Update(candle(10700))
+	assertClose(t, "chain after restoration + update", chain2.Value(), chain.Value(), 0.0001)
+}
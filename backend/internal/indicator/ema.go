@@ -5,6 +5,7 @@ import "trading-systemv1/internal/model"
 // EMA calculates Exponential Moving Average.
 // O(1) per update — no window storage needed.
 type EMA struct {
+	source     Source // input price (zero value = close)
 	period     int
 	multiplier float64
 	current    float64
@@ -20,10 +21,24 @@ func NewEMA(period int) *EMA {
 	}
 }
 
+func init() {
+	Register(Registration{
+		Type:    "EMA",
+		Overlay: true,
+		Params:  []ParamSpec{{Name: "length", Kind: ParamInt, Default: 20, Min: 1}},
+		New:     func(cfg IndicatorConfig) Indicator { return NewEMA(cfg.Int("length")) },
+	})
+}
+
 func (e *EMA) Name() string { return "EMA" }
 
 func (e *EMA) Update(candle model.Candle) {
-	price := float64(candle.Close)
+	e.updatePrice(e.source.Price(candle))
+}
+
+// updatePrice feeds a raw value. Used directly by indicators that smooth
+// derived series (e.g. the MACD signal line) rather than candle closes.
+func (e *EMA) updatePrice(price float64) {
 	e.count++
 
 	if e.count <= e.period {
@@ -43,8 +58,16 @@ func (e *EMA) Value() float64 { return e.current }
 func (e *EMA) Ready() bool    { return e.count >= e.period }
 
 // Peek computes what Value() would be with an additional candle without mutating state.
-func (e *EMA) Peek(closePaise int64) float64 {
-	price := float64(closePaise)
+func (e *EMA) Peek(forming model.TFCandle) float64 {
+	return e.peekPrice(e.source.FormingPrice(forming))
+}
+
+// peekPrice is the raw-value counterpart of Peek.
+func (e *EMA) peekPrice(price float64) float64 {
+	if e.count+1 == e.period {
+		// This value completes the window — preview the SMA seed.
+		return (e.sum + price) / float64(e.period)
+	}
 	if e.count < e.period {
 		// Not fully ready — return partial estimate using the price
 		return price
@@ -63,6 +86,7 @@ func (e *EMA) Reset() {
 func (e *EMA) Snapshot() IndicatorSnapshot {
 	return IndicatorSnapshot{
 		Type:       "EMA",
+		Source:     string(e.source),
 		Period:     e.period,
 		Multiplier: e.multiplier,
 		Current:    e.current,
@@ -73,6 +97,7 @@ func (e *EMA) Snapshot() IndicatorSnapshot {
 
 // RestoreFromSnapshot restores EMA state from a checkpoint.
 func (e *EMA) RestoreFromSnapshot(snap IndicatorSnapshot) error {
+	e.source = Source(snap.Source)
 	e.period = snap.Period
 	e.multiplier = snap.Multiplier
 	e.current = snap.Current
@@ -80,3 +105,6 @@ func (e *EMA) RestoreFromSnapshot(snap IndicatorSnapshot) error {
 	e.sum = snap.Sum
 	return nil
 }
+
+// setSource implements sourced.
+func (e *EMA) setSource(src Source) { e.source = src }
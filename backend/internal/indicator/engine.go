@@ -2,20 +2,30 @@ package indicator
 
 import (
 	"context"
+	"log"
+	"time"
 
 	"trading-systemv1/internal/model"
 )
 
 // IndicatorConfig specifies a single indicator to compute.
 type IndicatorConfig struct {
-	Type   string // "SMA", "EMA", "SMMA", "RSI"
-	Period int
+	Type   string           // a registered type: "SMA", "EMA", "RSI", "MACD", ... (see Types)
+	Period int              // legacy shorthand for the first parameter (e.g. SMA length)
+	Params Params           `json:",omitempty"` // named parameters; override Period and defaults
+	Source Source           // input price for price-based indicators; empty = close
+	Input  *IndicatorConfig // optional inner indicator whose output feeds this one
 }
 
 // TFIndicatorConfig groups indicator configs for a specific timeframe.
+// A config with Instruments is a profile: it applies only to those
+// instruments and overrides the TF's default (instrument-less) config.
+// Instruments without a profile or default on a TF get no indicators there.
 type TFIndicatorConfig struct {
-	TF         int // timeframe in seconds
-	Indicators []IndicatorConfig
+	TF          int      // timeframe in seconds
+	Profile     string   `json:",omitempty"` // optional label, e.g. "nifty-index"
+	Instruments []string `json:",omitempty"` // "exchange:token" keys; empty = TF default
+	Indicators  []IndicatorConfig
 }
 
 // tokenIndicators holds live indicator instances for one token within a TF.
@@ -29,47 +39,101 @@ type tokenIndicators struct {
 type Engine struct {
 	configs []TFIndicatorConfig
 
+	// tfs lists the configured TFs in config order
+	tfs []int
+
 	// state[tfIdx][tokenKey] → *tokenIndicators
 	state []map[string]*tokenIndicators
 
-	// tfIndex maps TF seconds → index in configs/state for O(1) lookup
+	// profiles[tfIdx] resolves a token's indicator set on that TF
+	profiles []tfProfiles
+
+	// tfIndex maps TF seconds → index in tfs/state/profiles for O(1) lookup
 	tfIndex map[int]int
+
+	// warming holds the cold instances of the last reload (see WarmUp)
+	warming map[warmKey]*warmState
+
+	// amends holds the recent bars replayed on revisions (see EnableAmend);
+	// nil while amendments are disabled
+	amends      map[amendKey][]amendEntry
+	amendWindow time.Duration
 }
 
 // NewEngine creates an indicator engine with the given per-TF indicator configs.
 func NewEngine(configs []TFIndicatorConfig) *Engine {
-	state := make([]map[string]*tokenIndicators, len(configs))
-	tfIndex := make(map[int]int, len(configs))
-	for i := range state {
-		state[i] = make(map[string]*tokenIndicators, 64)
-		tfIndex[configs[i].TF] = i
-	}
-	return &Engine{
-		configs: configs,
-		state:   state,
-		tfIndex: tfIndex,
+	e := &Engine{}
+	e.setConfigs(validConfigs(configs))
+	e.state = make([]map[string]*tokenIndicators, len(e.tfs))
+	for i := range e.state {
+		e.state[i] = make(map[string]*tokenIndicators, 64)
 	}
+	return e
+}
+
+// Configs returns the engine's current (validated) configs.
+func (e *Engine) Configs() []TFIndicatorConfig {
+	return e.configs
 }
 
 // Process takes a finalized TF candle and computes all indicators for that TF + token.
 // Returns indicator results (may include not-ready indicators with Ready=false).
+// A revised candle (Revision > 0) replaces its bar: with EnableAmend the bar
+// and the ones after it are recomputed and all their results returned;
+// otherwise it is ignored.
 func (e *Engine) Process(tfc model.TFCandle) []model.IndicatorResult {
 	// O(1) TF lookup via index map
 	tfIdx, ok := e.tfIndex[tfc.TF]
 	if !ok {
 		return nil // TF not configured for indicators
 	}
+	if tfc.Revision > 0 {
+		if e.amends == nil {
+			return nil
+		}
+		return e.amend(tfIdx, tfc)
+	}
 
 	key := tfc.Key()
 	ti, exists := e.state[tfIdx][key]
 	if !exists {
 		// First candle for this token + TF — create indicator instances
-		ti = e.createTokenIndicators(tfIdx)
+		configs, ok := e.profiles[tfIdx].resolve(key)
+		if !ok {
+			return nil // no profile or default covers this token
+		}
+		ti = createTokenIndicators(configs)
 		e.state[tfIdx][key] = ti
 	}
 
-	// Create a model.Candle from the TFCandle for indicator Update()
-	candle := model.Candle{
+	e.logBar(tfc, ti)
+
+	// Instances still waiting for their warm-up history get this candle
+	// after it (see FinishWarmUp)
+	var cold []int
+	if ws, ok := e.warming[warmKey{tfc.TF, key}]; ok && ws.ti == ti {
+		cold = ws.fresh
+		ws.live = append(ws.live, tfc)
+	}
+
+	// Update all indicators and collect results (one pass)
+	candle := candleOf(tfc)
+	results := make([]model.IndicatorResult, 0, len(ti.indicators))
+	for i, ind := range ti.indicators {
+		if len(cold) > 0 && cold[0] == i {
+			cold = cold[1:]
+			continue
+		}
+		ind.Update(candle)
+		results = append(results, newResult(ti.configs[i], ind, tfc))
+	}
+
+	return results
+}
+
+// candleOf creates a model.Candle from a TFCandle for indicator Update().
+func candleOf(tfc model.TFCandle) model.Candle {
+	return model.Candle{
 		Token:    tfc.Token,
 		Exchange: tfc.Exchange,
 		TS:       tfc.TS,
@@ -79,27 +143,27 @@ func (e *Engine) Process(tfc model.TFCandle) []model.IndicatorResult {
 		Close:    tfc.Close,
 		Volume:   tfc.Volume,
 	}
+}
 
-	// Update all indicators and collect results (one pass)
-	results := make([]model.IndicatorResult, 0, len(ti.indicators))
-	for i, ind := range ti.indicators {
-		ind.Update(candle)
-		cfg := ti.configs[i]
-		results = append(results, model.IndicatorResult{
-			Name:     ind.Name() + "_" + model.Itoa(cfg.Period),
-			Token:    tfc.Token,
-			Exchange: tfc.Exchange,
-			TF:       tfc.TF,
-			Value:    ind.Value(),
-			TS:       tfc.TS,
-			Ready:    ind.Ready(),
-		})
+// newResult builds the result of an updated indicator for a completed candle.
+func newResult(cfg IndicatorConfig, ind Indicator, tfc model.TFCandle) model.IndicatorResult {
+	r := model.IndicatorResult{
+		Name:     cfg.Name(),
+		Token:    tfc.Token,
+		Exchange: tfc.Exchange,
+		TF:       tfc.TF,
+		Value:    ind.Value(),
+		TS:       tfc.TS,
+		Ready:    ind.Ready(),
 	}
-
-	return results
+	if mo, ok := ind.(MultiOutput); ok {
+		r.Components = components(mo.Components(), mo.Values())
+	}
+	return r
 }
 
 // ProcessPeek computes live indicator values for a forming TF candle using Peek().
+// Each indicator receives the full forming OHLCV candle.
 // Does NOT mutate indicator state — safe for streaming updates every second.
 // Returns nil if token hasn't been seen before (need at least one Process first).
 func (e *Engine) ProcessPeek(tfc model.TFCandle) []model.IndicatorResult {
@@ -120,16 +184,22 @@ func (e *Engine) ProcessPeek(tfc model.TFCandle) []model.IndicatorResult {
 	results := make([]model.IndicatorResult, 0, len(ti.indicators))
 	for i, ind := range ti.indicators {
 		cfg := ti.configs[i]
-		results = append(results, model.IndicatorResult{
-			Name:     ind.Name() + "_" + model.Itoa(cfg.Period),
+		r := model.IndicatorResult{
+			Name:     cfg.Name(),
 			Token:    tfc.Token,
 			Exchange: tfc.Exchange,
 			TF:       tfc.TF,
-			Value:    ind.Peek(tfc.Close),
 			TS:       tfc.TS,
 			Ready:    ind.Ready(),
 			Live:     true,
-		})
+		}
+		if mo, ok := ind.(MultiOutput); ok {
+			r.Components = components(mo.Components(), mo.PeekValues(tfc))
+			r.Value = r.Components[0].Value
+		} else {
+			r.Value = ind.Peek(tfc)
+		}
+		results = append(results, r)
 	}
 	return results
 }
@@ -159,26 +229,68 @@ func (e *Engine) Run(ctx context.Context, tfCandleCh <-chan model.TFCandle, resu
 	}
 }
 
-// createTokenIndicators creates fresh indicator instances for a TF config.
-func (e *Engine) createTokenIndicators(tfIdx int) *tokenIndicators {
-	cfg := e.configs[tfIdx]
-	inds := make([]Indicator, len(cfg.Indicators))
-	for i, ic := range cfg.Indicators {
-		switch ic.Type {
-		case "SMA":
-			inds[i] = NewSMA(ic.Period)
-		case "EMA":
-			inds[i] = NewEMA(ic.Period)
-		case "SMMA":
-			inds[i] = NewSMMA(ic.Period)
-		case "RSI":
-			inds[i] = NewRSI(ic.Period)
-		default:
-			inds[i] = NewSMA(ic.Period) // fallback
-		}
+// createTokenIndicators creates fresh indicator instances for a token's
+// resolved indicator set.
+func createTokenIndicators(configs []IndicatorConfig) *tokenIndicators {
+	inds := make([]Indicator, len(configs))
+	for i, ic := range configs {
+		inds[i] = newIndicator(ic)
 	}
 	return &tokenIndicators{
 		indicators: inds,
-		configs:    cfg.Indicators,
+		configs:    configs,
+	}
+}
+
+// newIndicator creates a fresh indicator instance for a validated config.
+// A config with an Input becomes a Chain fed by the inner indicator.
+func newIndicator(ic IndicatorConfig) Indicator {
+	ind := newIndicatorOfType(ic)
+	if ic.Input != nil {
+		if outer, ok := ind.(priceInput); ok {
+			return NewChain(outer, newIndicator(*ic.Input))
+		}
+	}
+	if s, ok := ind.(sourced); ok && !ic.Source.IsClose() {
+		s.setSource(ic.Source)
+	}
+	return ind
+}
+
+// newIndicatorOfType creates an indicator instance through the registry.
+// Returns nil for unregistered types.
+func newIndicatorOfType(ic IndicatorConfig) Indicator {
+	r, ok := Lookup(ic.Type)
+	if !ok {
+		return nil
+	}
+	return r.New(ic)
+}
+
+// validConfigs returns a copy of configs with invalid indicators (unknown
+// types, out-of-range periods) removed, logging each one dropped.
+func validConfigs(configs []TFIndicatorConfig) []TFIndicatorConfig {
+	out := make([]TFIndicatorConfig, len(configs))
+	for i, cfg := range configs {
+		kept := make([]IndicatorConfig, 0, len(cfg.Indicators))
+		for _, ic := range cfg.Indicators {
+			if err := ValidateIndicator(ic); err != nil {
+				log.Printf("[indicator] WARNING: dropping %s on TF=%d: %v", ic.Name(), cfg.TF, err)
+				continue
+			}
+			kept = append(kept, ic)
+		}
+		cfg.Indicators = kept
+		out[i] = cfg
+	}
+	return out
+}
+
+// components pairs component names with their values.
+func components(names []string, values []float64) model.IndicatorComponents {
+	out := make(model.IndicatorComponents, len(names))
+	for i, name := range names {
+		out[i] = model.IndicatorComponent{Name: name, Value: values[i]}
 	}
+	return out
 }
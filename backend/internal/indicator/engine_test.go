@@ -209,3 +209,272 @@ func TestProcessPeek_DoesNotMutateState(t *testing.T) {
 		t.Errorf("ProcessPeek mutated state! before=%.4f after=%.4f", valueBefore, after[0].Value)
 	}
 }
+
+func TestEngine_MultiOutputComponents(t *testing.T) {
+	engine := NewEngine([]TFIndicatorConfig{
+		{TF: 60, Indicators: []IndicatorConfig{
+			{Type: "SMA", Period: 5},
+			{Type: "BB", Period: 5},
+		}},
+	})
+
+	var results []model.IndicatorResult
+	for i := 0; i < 6; i++ {
+		results = engine.Process(makeTFCandle("C1", 60, int64(10000+i*100)))
+	}
+
+	if results[0].Components != nil {
+		t.Errorf("expected no components for SMA, got %v", results[0].Components)
+	}
+	bb := results[1]
+	if bb.Name != "BB_5_2" {
+		t.Errorf("expected name=BB_5_2, got %s", bb.Name)
+	}
+	if len(bb.Components) != 3 {
+		t.Fatalf("expected 3 BB components, got %d", len(bb.Components))
+	}
+	mid, _ := bb.Components.Get("mid")
+	upper, _ := bb.Components.Get("upper")
+	lower, _ := bb.Components.Get("lower")
+	if bb.Value != mid || mid != results[0].Value {
+		t.Errorf("expected Value == mid == SMA_5: value=%.4f mid=%.4f sma=%.4f", bb.Value, mid, results[0].Value)
+	}
+	if !(upper > mid && mid > lower) {
+		t.Errorf("expected upper > mid > lower, got %.4f / %.4f / %.4f", upper, mid, lower)
+	}
+
+	forming := makeTFCandle("C1", 60, 12000)
+	forming.Forming = true
+	peek := engine.ProcessPeek(forming)
+	if len(peek[1].Components) != 3 || peek[1].Value != peek[1].Components[0].Value {
+		t.Errorf("expected live BB components with Value == mid, got %+v", peek[1])
+	}
+}
+
+func TestEngine_VolatilityTrendIndicators(t *testing.T) {
+	engine := NewEngine([]TFIndicatorConfig{
+		{TF: 60, Indicators: []IndicatorConfig{
+			{Type: "ATR", Period: 14},
+			{Type: "ADX", Period: 14},
+			{Type: "SUPERTREND", Period: 10},
+			{Type: "PSAR"},
+		}},
+	})
+
+	var results []model.IndicatorResult
+	for i := 0; i < 40; i++ {
+		results = engine.Process(makeTFCandle("V1", 60, int64(10000+i*50)))
+	}
+
+	want := []string{"ATR_14", "ADX_14", "SUPERTREND_10_3", "PSAR_0.02_0.2"}
+	for i, r := range results {
+		if r.Name != want[i] {
+			t.Errorf("result %d: expected name=%s, got %s", i, want[i], r.Name)
+		}
+		if !r.Ready {
+			t.Errorf("%s: expected Ready=true after 40 candles", r.Name)
+		}
+	}
+	// makeTFCandle uses a fixed 200-paise range and flat closes within the bar
+	if math.Abs(results[0].Value-200) > 0.01 {
+		t.Errorf("expected ATR_14=200, got %.4f", results[0].Value)
+	}
+	if len(results[1].Components) != 3 || len(results[2].Components) != 2 {
+		t.Errorf("expected ADX/SUPERTREND components, got %v / %v", results[1].Components, results[2].Components)
+	}
+}
+
+func TestEngine_SourceSideBySide(t *testing.T) {
+	engine := NewEngine([]TFIndicatorConfig{
+		{TF: 60, Indicators: []IndicatorConfig{
+			{Type: "SMA", Period: 3},
+			{Type: "SMA", Period: 3, Source: SourceHigh},
+			{Type: "SMA", Period: 3, Source: SourceHL2},
+		}},
+	})
+
+	var results []model.IndicatorResult
+	for i := 0; i < 5; i++ {
+		results = engine.Process(makeTFCandle("S1", 60, int64(10000+i*100)))
+	}
+
+	want := []string{"SMA_3", "SMA_3_high", "SMA_3_hl2"}
+	for i, r := range results {
+		if r.Name != want[i] {
+			t.Errorf("result %d: expected name=%s, got %s", i, want[i], r.Name)
+		}
+	}
+	// makeTFCandle sets high = close+100 and low = close-100
+	if math.Abs(results[1].Value-(results[0].Value+100)) > 0.01 {
+		t.Errorf("expected SMA_3_high = SMA_3 + 100, got %.4f vs %.4f", results[1].Value, results[0].Value)
+	}
+	if math.Abs(results[2].Value-results[0].Value) > 0.01 {
+		t.Errorf("expected SMA_3_hl2 = SMA_3, got %.4f vs %.4f", results[2].Value, results[0].Value)
+	}
+
+	forming := makeTFCandle("S1", 60, 12000)
+	forming.Forming = true
+	peek := engine.ProcessPeek(forming)
+	if math.Abs(peek[1].Value-(peek[0].Value+100)) > 0.01 {
+		t.Errorf("expected live SMA_3_high = SMA_3 + 100, got %.4f vs %.4f", peek[1].Value, peek[0].Value)
+	}
+}
+
+func TestValidateConfigs_Source(t *testing.T) {
+	ok := []TFIndicatorConfig{{TF: 60, Indicators: []IndicatorConfig{{Type: "EMA", Period: 21, Source: SourceHLC3}}}}
+	if err := ValidateConfigs(ok); err != nil {
+		t.Errorf("expected EMA on hlc3 to be valid, got %v", err)
+	}
+
+	unknown := []TFIndicatorConfig{{TF: 60, Indicators: []IndicatorConfig{{Type: "EMA", Period: 21, Source: "vwap"}}}}
+	if err := ValidateConfigs(unknown); err == nil {
+		t.Error("expected error for unknown source")
+	}
+
+	unsupported := []TFIndicatorConfig{{TF: 60, Indicators: []IndicatorConfig{{Type: "ATR", Period: 14, Source: SourceHL2}}}}
+	if err := ValidateConfigs(unsupported); err == nil {
+		t.Error("expected error for ATR with a non-close source")
+	}
+}
+
+func TestParseIndicatorConfig_Chain(t *testing.T) {
+	cfg, err := ParseIndicatorConfig("ema:9@RSI:14:hl2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Type != "EMA" || cfg.Int("length") != 9 || cfg.Input == nil {
+		t.Fatalf("unexpected config %+v", cfg)
+	}
+	if cfg.Input.Type != "RSI" || cfg.Input.Int("length") != 14 || cfg.Input.Source != SourceHL2 {
+		t.Errorf("unexpected input %+v", *cfg.Input)
+	}
+	if cfg.Name() != "EMA_9@RSI_14_hl2" {
+		t.Errorf("expected name=EMA_9@RSI_14_hl2, got %s", cfg.Name())
+	}
+
+	for _, bad := range []string{"EMA:9@", "EMA@RSI:14", "EMA:9@RSI:x", "SMA:20:vwap"} {
+		if _, err := ParseIndicatorConfig(bad); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestEngine_Chains(t *testing.T) {
+	emaOfRSI, _ := ParseIndicatorConfig("EMA:3@RSI:4")
+	smaOfVol, _ := ParseIndicatorConfig("SMA:3:volume")
+	configs := []TFIndicatorConfig{
+		{TF: 60, Indicators: []IndicatorConfig{{Type: "RSI", Period: 4}, emaOfRSI, smaOfVol}},
+	}
+	if err := ValidateConfigs(configs); err != nil {
+		t.Fatal(err)
+	}
+	engine := NewEngine(configs)
+
+	var results []model.IndicatorResult
+	for i := 0; i < 10; i++ {
+		results = engine.Process(makeTFCandle("CH", 60, int64(10000+(i%3)*150)))
+	}
+
+	want := []string{"RSI_4", "EMA_3@RSI_4", "SMA_3_volume"}
+	for i, r := range results {
+		if r.Name != want[i] {
+			t.Errorf("result %d: expected name=%s, got %s", i, want[i], r.Name)
+		}
+		if !r.Ready {
+			t.Errorf("%s: expected Ready=true", r.Name)
+		}
+	}
+	if results[1].Value <= 0 || results[1].Value >= 100 {
+		t.Errorf("expected EMA of RSI on the RSI scale, got %.4f", results[1].Value)
+	}
+	assertClose(t, "SMA_3_volume", results[2].Value, 100, 1e-9)
+
+	forming := makeTFCandle("CH", 60, 11000)
+	forming.Forming = true
+	peek := engine.ProcessPeek(forming)
+	if peek[1].Value <= results[1].Value {
+		t.Errorf("expected a rally to lift live EMA of RSI: %.4f -> %.4f", results[1].Value, peek[1].Value)
+	}
+}
+
+func TestValidateConfigs_Chain(t *testing.T) {
+	bad := []IndicatorConfig{
+		{Type: "ATR", Period: 14, Input: &IndicatorConfig{Type: "RSI", Period: 14}},                    // ATR needs candles
+		{Type: "EMA", Period: 9, Source: SourceHigh, Input: &IndicatorConfig{Type: "RSI", Period: 14}}, // source on outer
+		{Type: "EMA", Period: 9, Input: &IndicatorConfig{Type: "FOO", Period: 14}},                     // bad inner
+	}
+	for _, ind := range bad {
+		if err := ValidateConfigs([]TFIndicatorConfig{{TF: 60, Indicators: []IndicatorConfig{ind}}}); err == nil {
+			t.Errorf("expected error for %s", ind.Name())
+		}
+	}
+}
+
+func TestEngine_Amend(t *testing.T) {
+	configs := []TFIndicatorConfig{{TF: 60, Indicators: []IndicatorConfig{
+		{Type: "SMA", Period: 3},
+		{Type: "EMA", Period: 3},
+	}}}
+	base := time.Date(2026, 1, 5, 3, 45, 0, 0, time.UTC)
+	bar := func(i int, close int64) model.TFCandle {
+		c := makeTFCandle("SBIN", 60, close)
+		c.TS = base.Add(time.Duration(i) * time.Minute)
+		return c
+	}
+	closes := []int64{10000, 10100, 10200, 10300, 10400, 10500, 10600, 10700}
+
+	amended := NewEngine(configs)
+	amended.EnableAmend(3 * time.Minute) // keeps the last 5 bars
+	for i, c := range closes {
+		amended.Process(bar(i, c))
+	}
+
+	// Bar 5 revised: its results and those of bars 6 and 7 are recomputed
+	rev := bar(5, 9900)
+	rev.Revision = 1
+	results := amended.Process(rev)
+	if len(results) != 3*2 {
+		t.Fatalf("expected results for 3 bars × 2 indicators, got %d", len(results))
+	}
+	if !results[0].TS.Equal(rev.TS) || !results[5].TS.Equal(bar(7, 0).TS) {
+		t.Errorf("results span %v..%v, want bars 5..7", results[0].TS, results[5].TS)
+	}
+
+	want := NewEngine(configs)
+	var last []model.IndicatorResult
+	for i, c := range closes {
+		if i == 5 {
+			c = 9900
+		}
+		last = want.Process(bar(i, c))
+	}
+	for i, r := range last {
+		if got := results[4+i]; math.Abs(got.Value-r.Value) > 1e-9 {
+			t.Errorf("%s after replay: got %.4f, want %.4f", r.Name, got.Value, r.Value)
+		}
+	}
+
+	// The next bar continues from the replayed state
+	got, exp := amended.Process(bar(8, 10800)), want.Process(bar(8, 10800))
+	for i := range exp {
+		if math.Abs(got[i].Value-exp[i].Value) > 1e-9 {
+			t.Errorf("%s after amend: got %.4f, want %.4f", exp[i].Name, got[i].Value, exp[i].Value)
+		}
+	}
+
+	// A bar no longer logged is not recomputed
+	old := bar(1, 9000)
+	old.Revision = 1
+	if r := amended.Process(old); r != nil {
+		t.Errorf("expected nil for a bar outside the window, got %d results", len(r))
+	}
+
+	// Without EnableAmend, revisions are ignored
+	plain := NewEngine(configs)
+	plain.Process(bar(0, 10000))
+	rev = bar(0, 9000)
+	rev.Revision = 1
+	if r := plain.Process(rev); r != nil {
+		t.Errorf("expected revisions to be ignored, got %d results", len(r))
+	}
+}
@@ -1,14 +1,22 @@
 // Package indicator provides technical indicator calculations over candle data.
 //
 // All indicators implement the Indicator interface, receiving candles and
-// producing float64 values. Indicators are designed to be composable.
+// producing float64 values. Indicators with several outputs (MACD, Bollinger
+// Bands, Stochastic) additionally implement MultiOutput. Indicators are
+// designed to be composable: a Chain feeds one indicator's output into another.
+//
+// Each indicator type registers its factory, parameter schema and snapshot
+// codec from init() (see Register); the engine, snapshots and config parsers
+// only go through the registry.
 package indicator
 
 import "trading-systemv1/internal/model"
 
 // Indicator is the interface for all technical indicators.
 type Indicator interface {
-	// Name returns the indicator name (e.g., "SMA_20", "EMA_9").
+	// Name returns the registered indicator type (e.g., "SMA", "EMA"); it
+	// must equal Registration.Type. Result names with parameters, such as
+	// "SMA_20", come from IndicatorConfig.Name.
 	Name() string
 
 	// Update feeds a new candle and recalculates.
@@ -20,8 +28,28 @@ type Indicator interface {
 	// Ready returns true when enough data has been accumulated.
 	Ready() bool
 
-	// Peek computes what Value() would be if a candle with this close price
-	// (in paise) were added next, WITHOUT mutating internal state.
-	// Used for live/streaming updates from forming candles.
-	Peek(closePaise int64) float64
+	// Peek computes what Value() would be if the forming candle were added
+	// next, WITHOUT mutating internal state. The full OHLCV of the forming
+	// candle is available so range- and volume-based indicators preview
+	// correctly. Used for live/streaming updates from forming candles.
+	Peek(forming model.TFCandle) float64
+}
+
+// MultiOutput is implemented by indicators that produce several named
+// components per candle (e.g. MACD line/signal/histogram). Value() returns
+// the primary component, which is always the first entry of Components().
+type MultiOutput interface {
+	Indicator
+
+	// Components returns the component names in output order
+	// (e.g. "macd", "signal", "hist").
+	Components() []string
+
+	// Values returns the current component values in Components() order.
+	Values() []float64
+
+	// PeekValues is the multi-output counterpart of Peek. It returns what
+	// Values() would be if the forming candle were added next, WITHOUT
+	// mutating internal state.
+	PeekValues(forming model.TFCandle) []float64
 }
@@ -0,0 +1,140 @@
+package indicator
+
+import (
+	"fmt"
+
+	"trading-systemv1/internal/model"
+)
+
+// MACD calculates Moving Average Convergence Divergence.
+// macd = EMA(fast) - EMA(slow), signal = EMA(signal) of macd, hist = macd - signal.
+// O(1) per update — built from three EMAs.
+type MACD struct {
+	source Source // input price (zero value = close)
+	fast   *EMA
+	slow   *EMA
+	signal *EMA
+	macd   float64
+}
+
+var macdComponents = []string{"macd", "signal", "hist"}
+
+// NewMACD creates a new MACD indicator (typically 12, 26, 9).
+func NewMACD(fastPeriod, slowPeriod, signalPeriod int) *MACD {
+	return &MACD{
+		fast:   NewEMA(fastPeriod),
+		slow:   NewEMA(slowPeriod),
+		signal: NewEMA(signalPeriod),
+	}
+}
+
+func init() {
+	Register(Registration{
+		Type: "MACD",
+		Params: []ParamSpec{
+			{Name: "fast", Kind: ParamInt, Default: 12, Min: 1},
+			{Name: "slow", Kind: ParamInt, Default: 26, Min: 1},
+			{Name: "signal", Kind: ParamInt, Default: 9, Min: 1},
+		},
+		New: func(cfg IndicatorConfig) Indicator {
+			return NewMACD(cfg.Int("fast"), cfg.Int("slow"), cfg.Int("signal"))
+		},
+		WarmUp: func(cfg IndicatorConfig) int { return cfg.Int("slow") + cfg.Int("signal") - 1 },
+		Validate: func(cfg IndicatorConfig) error {
+			if cfg.Int("fast") >= cfg.Int("slow") {
+				return fmt.Errorf("fast=%d must be below slow=%d", cfg.Int("fast"), cfg.Int("slow"))
+			}
+			return nil
+		},
+	})
+}
+
+func (m *MACD) Name() string { return "MACD" }
+
+func (m *MACD) Update(candle model.Candle) {
+	m.updatePrice(m.source.Price(candle))
+}
+
+// updatePrice feeds a single input value.
+func (m *MACD) updatePrice(price float64) {
+	m.fast.updatePrice(price)
+	m.slow.updatePrice(price)
+	if !m.slow.Ready() {
+		return
+	}
+	m.macd = m.fast.Value() - m.slow.Value()
+	m.signal.updatePrice(m.macd)
+}
+
+func (m *MACD) Value() float64 { return m.macd }
+func (m *MACD) Ready() bool    { return m.signal.Ready() }
+
+// Components returns the MACD output names: macd, signal, hist.
+func (m *MACD) Components() []string { return macdComponents }
+
+// Values returns the current macd, signal and histogram values.
+func (m *MACD) Values() []float64 {
+	if !m.signal.Ready() {
+		return []float64{m.macd, 0, 0}
+	}
+	sig := m.signal.Value()
+	return []float64{m.macd, sig, m.macd - sig}
+}
+
+// Peek computes what the MACD line would be with an additional candle without mutating state.
+func (m *MACD) Peek(forming model.TFCandle) float64 {
+	return m.PeekValues(forming)[0]
+}
+
+// PeekValues computes all MACD components with an additional candle without mutating state.
+func (m *MACD) PeekValues(forming model.TFCandle) []float64 {
+	return m.peekPriceValues(m.source.FormingPrice(forming))
+}
+
+// peekPrice previews the MACD line for a single input value without mutating state.
+func (m *MACD) peekPrice(price float64) float64 {
+	return m.peekPriceValues(price)[0]
+}
+
+// peekPriceValues previews all MACD components for a single input value.
+func (m *MACD) peekPriceValues(price float64) []float64 {
+	if m.slow.count+1 < m.slow.period {
+		return m.Values()
+	}
+	macd := m.fast.peekPrice(price) - m.slow.peekPrice(price)
+	if m.signal.count+1 < m.signal.period {
+		return []float64{macd, 0, 0}
+	}
+	sig := m.signal.peekPrice(macd)
+	return []float64{macd, sig, macd - sig}
+}
+
+// Snapshot serializes the MACD state for checkpoint persistence.
+func (m *MACD) Snapshot() IndicatorSnapshot {
+	return IndicatorSnapshot{
+		Type:     "MACD",
+		Source:   string(m.source),
+		Period:   m.signal.period,
+		Current:  m.macd,
+		Count:    m.slow.count,
+		Children: []IndicatorSnapshot{m.fast.Snapshot(), m.slow.Snapshot(), m.signal.Snapshot()},
+	}
+}
+
+// RestoreFromSnapshot restores MACD state from a checkpoint.
+func (m *MACD) RestoreFromSnapshot(snap IndicatorSnapshot) error {
+	m.source = Source(snap.Source)
+	if len(snap.Children) != 3 {
+		return fmt.Errorf("MACD snapshot: expected 3 children, got %d", len(snap.Children))
+	}
+	for i, ema := range []*EMA{m.fast, m.slow, m.signal} {
+		if err := ema.RestoreFromSnapshot(snap.Children[i]); err != nil {
+			return err
+		}
+	}
+	m.macd = snap.Current
+	return nil
+}
+
+// setSource implements sourced.
+func (m *MACD) setSource(src Source) { m.source = src }
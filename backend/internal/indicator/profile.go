@@ -0,0 +1,72 @@
+package indicator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// tfProfiles resolves which indicators a token gets on one TF: its
+// instrument profile if it has one, else the TF default.
+type tfProfiles struct {
+	byInstrument map[string][]IndicatorConfig
+	fallback     []IndicatorConfig
+	hasFallback  bool
+}
+
+// resolve returns the indicator set for a token key ("exchange:token").
+// ok is false when neither a profile nor a default covers the token.
+func (p *tfProfiles) resolve(key string) (configs []IndicatorConfig, ok bool) {
+	if configs, ok = p.byInstrument[key]; ok {
+		return configs, true
+	}
+	return p.fallback, p.hasFallback
+}
+
+// setConfigs installs configs and rebuilds the TF index and profile
+// lookups. Token state is left to the caller. When configs overlap (which
+// ValidateConfigs rejects), the first one wins.
+func (e *Engine) setConfigs(configs []TFIndicatorConfig) {
+	e.configs = configs
+	e.tfs = e.tfs[:0]
+	e.profiles = e.profiles[:0]
+	e.tfIndex = make(map[int]int, len(configs))
+	for _, cfg := range configs {
+		tfIdx, ok := e.tfIndex[cfg.TF]
+		if !ok {
+			tfIdx = len(e.tfs)
+			e.tfIndex[cfg.TF] = tfIdx
+			e.tfs = append(e.tfs, cfg.TF)
+			e.profiles = append(e.profiles, tfProfiles{byInstrument: make(map[string][]IndicatorConfig)})
+		}
+		p := &e.profiles[tfIdx]
+		if len(cfg.Instruments) == 0 {
+			if !p.hasFallback {
+				p.fallback, p.hasFallback = cfg.Indicators, true
+			}
+			continue
+		}
+		for _, key := range cfg.Instruments {
+			if _, dup := p.byInstrument[key]; !dup {
+				p.byInstrument[key] = cfg.Indicators
+			}
+		}
+	}
+}
+
+// profileLabel names a config in error messages.
+func profileLabel(cfg TFIndicatorConfig) string {
+	switch {
+	case cfg.Profile != "":
+		return fmt.Sprintf("profile %q", cfg.Profile)
+	case len(cfg.Instruments) == 0:
+		return "default"
+	default:
+		return "profile " + strings.Join(cfg.Instruments, ",")
+	}
+}
+
+// validInstrumentKey reports whether key has the "exchange:token" form.
+func validInstrumentKey(key string) bool {
+	ex, token, ok := strings.Cut(key, ":")
+	return ok && ex != "" && token != ""
+}
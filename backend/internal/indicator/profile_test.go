@@ -0,0 +1,160 @@
+package indicator
+
+import (
+	"testing"
+
+	"trading-systemv1/internal/model"
+)
+
+func resultNames(results []model.IndicatorResult) []string {
+	names := make([]string, len(results))
+	for i, r := range results {
+		names[i] = r.Name
+	}
+	return names
+}
+
+func profileConfigs() []TFIndicatorConfig {
+	return []TFIndicatorConfig{
+		{TF: 60, Indicators: []IndicatorConfig{{Type: "SMA", Period: 5}}},
+		{
+			TF:          60,
+			Profile:     "nifty",
+			Instruments: []string{"NSE:NIFTY"},
+			Indicators:  []IndicatorConfig{{Type: "EMA", Period: 9}, {Type: "RSI", Period: 14}},
+		},
+		{
+			TF:          300,
+			Profile:     "nifty",
+			Instruments: []string{"NSE:NIFTY"},
+			Indicators:  []IndicatorConfig{{Type: "SMA", Period: 20}},
+		},
+	}
+}
+
+func TestEngine_Profiles(t *testing.T) {
+	engine := NewEngine(profileConfigs())
+
+	if got := resultNames(engine.Process(makeTFCandle("SBIN", 60, 10000))); len(got) != 1 || got[0] != "SMA_5" {
+		t.Errorf("SBIN TF=60: expected default [SMA_5], got %v", got)
+	}
+	if got := resultNames(engine.Process(makeTFCandle("NIFTY", 60, 10000))); len(got) != 2 || got[0] != "EMA_9" || got[1] != "RSI_14" {
+		t.Errorf("NIFTY TF=60: expected profile [EMA_9 RSI_14], got %v", got)
+	}
+	if got := engine.Process(makeTFCandle("SBIN", 300, 10000)); got != nil {
+		t.Errorf("SBIN TF=300: no default, expected nil, got %v", resultNames(got))
+	}
+	if got := resultNames(engine.Process(makeTFCandle("NIFTY", 300, 10000))); len(got) != 1 || got[0] != "SMA_20" {
+		t.Errorf("NIFTY TF=300: expected [SMA_20], got %v", got)
+	}
+}
+
+func TestReloadConfigs_Profiles(t *testing.T) {
+	engine := NewEngine([]TFIndicatorConfig{
+		{TF: 60, Indicators: []IndicatorConfig{{Type: "SMA", Period: 5}}},
+	})
+	for i := 0; i < 10; i++ {
+		engine.Process(makeTFCandle("SBIN", 60, int64(10000+i*100)))
+		engine.Process(makeTFCandle("INFY", 60, int64(20000+i*100)))
+	}
+	sbin := engine.state[0]["NSE:SBIN"]
+	infySMA := engine.state[0]["NSE:INFY"].indicators[0]
+
+	// Give INFY its own profile; SBIN stays on the default.
+	preserved, created := engine.ReloadConfigs([]TFIndicatorConfig{
+		{TF: 60, Indicators: []IndicatorConfig{{Type: "SMA", Period: 5}}},
+		{
+			TF:          60,
+			Instruments: []string{"NSE:INFY"},
+			Indicators:  []IndicatorConfig{{Type: "SMA", Period: 5}, {Type: "EMA", Period: 9}},
+		},
+	})
+	if preserved != 2 || created != 1 {
+		t.Errorf("expected preserved=2 created=1, got %d/%d", preserved, created)
+	}
+	if engine.state[0]["NSE:SBIN"] != sbin {
+		t.Error("SBIN state should be untouched by an INFY profile change")
+	}
+	infy := engine.state[0]["NSE:INFY"]
+	if infy.indicators[0] != infySMA || !infy.indicators[0].Ready() {
+		t.Error("INFY SMA_5 should keep its warmed-up instance")
+	}
+	if infy.indicators[1].Ready() {
+		t.Error("INFY EMA_9 should start cold")
+	}
+
+	// Dropping the default stops computing for instruments without a profile.
+	engine.ReloadConfigs([]TFIndicatorConfig{
+		{TF: 60, Instruments: []string{"NSE:INFY"}, Indicators: []IndicatorConfig{{Type: "SMA", Period: 5}, {Type: "EMA", Period: 9}}},
+	})
+	if _, ok := engine.state[0]["NSE:SBIN"]; ok {
+		t.Error("SBIN state should be dropped once no config covers it")
+	}
+	if engine.state[0]["NSE:INFY"] != infy {
+		t.Error("INFY state should be preserved when its profile is unchanged")
+	}
+	if got := engine.Process(makeTFCandle("SBIN", 60, 10000)); got != nil {
+		t.Errorf("SBIN: expected nil, got %v", resultNames(got))
+	}
+}
+
+func TestValidateConfigs_Profiles(t *testing.T) {
+	if err := ValidateConfigs(profileConfigs()); err != nil {
+		t.Fatalf("expected valid profiles, got %v", err)
+	}
+
+	sma := []IndicatorConfig{{Type: "SMA", Period: 5}}
+	bad := map[string][]TFIndicatorConfig{
+		"two defaults": {{TF: 60, Indicators: sma}, {TF: 60, Indicators: sma}},
+		"overlap": {
+			{TF: 60, Profile: "a", Instruments: []string{"NSE:SBIN"}, Indicators: sma},
+			{TF: 60, Profile: "b", Instruments: []string{"NSE:INFY", "NSE:SBIN"}, Indicators: sma},
+		},
+		"bad key": {{TF: 60, Instruments: []string{"SBIN"}, Indicators: sma}},
+	}
+	for name, configs := range bad {
+		if err := ValidateConfigs(configs); err == nil {
+			t.Errorf("%s: expected validation error", name)
+		}
+	}
+
+	// The same instrument may have different profiles on different TFs.
+	ok := []TFIndicatorConfig{
+		{TF: 60, Instruments: []string{"NSE:SBIN"}, Indicators: sma},
+		{TF: 300, Instruments: []string{"NSE:SBIN"}, Indicators: sma},
+	}
+	if err := ValidateConfigs(ok); err != nil {
+		t.Errorf("expected per-TF profiles to be valid, got %v", err)
+	}
+}
+
+func TestSnapshot_Engine_Profiles_RoundTrip(t *testing.T) {
+	engine := NewEngine(profileConfigs())
+	for i := 0; i < 30; i++ {
+		engine.Process(makeTFCandle("SBIN", 60, int64(10000+i*10)))
+		engine.Process(makeTFCandle("NIFTY", 60, int64(20000+i*10)))
+	}
+	snap, err := SnapshotEngine(engine, "0-0")
+	if err != nil {
+		t.Fatalf("snapshot: %v", err)
+	}
+	restored, err := RestoreEngine(profileConfigs(), snap)
+	if err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+
+	for _, token := range []string{"SBIN", "NIFTY"} {
+		next := makeTFCandle(token, 60, 30000)
+		want := engine.Process(next)
+		got := restored.Process(next)
+		if len(want) != len(got) {
+			t.Fatalf("%s: expected %d results, got %d", token, len(want), len(got))
+		}
+		for i := range want {
+			if want[i].Name != got[i].Name || want[i].Value != got[i].Value || !got[i].Ready {
+				t.Errorf("%s: %s=%.4f (ready=%v), want %s=%.4f",
+					token, got[i].Name, got[i].Value, got[i].Ready, want[i].Name, want[i].Value)
+			}
+		}
+	}
+}
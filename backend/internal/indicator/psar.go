@@ -0,0 +1,167 @@
+package indicator
+
+import (
+	"math"
+
+	"trading-systemv1/internal/model"
+)
+
+// PSAR calculates Wilder's Parabolic Stop and Reverse.
+// The SAR trails price by an acceleration factor that grows by step each time
+// a new extreme point is made (capped at maxAF), and flips to the other side
+// of price when penetrated. The initial trend comes from the second candle's
+// close versus the first. O(1) per update.
+type PSAR struct {
+	step  float64
+	maxAF float64
+	count int
+
+	// Trend state
+	rising bool    // true when SAR is below price
+	sar    float64 // current SAR value
+	ep     float64 // extreme point of the current trend
+	af     float64 // acceleration factor
+
+	// Previous candles (SAR may not penetrate the last two bars' range)
+	prevHigh, prevLow, prevClose float64
+	prev2High, prev2Low          float64
+}
+
+// psarState is the mutable trend state advanced by one candle.
+type psarState struct {
+	rising bool
+	sar    float64
+	ep     float64
+	af     float64
+}
+
+// NewPSAR creates a new Parabolic SAR indicator (typically 0.02, 0.2).
+func NewPSAR(step, maxAF float64) *PSAR {
+	return &PSAR{step: step, maxAF: maxAF}
+}
+
+func init() {
+	Register(Registration{
+		Type:    "PSAR",
+		Overlay: true,
+		Params: []ParamSpec{
+			{Name: "step", Kind: ParamFloat, Default: 0.02, Min: 0.001, Max: 1},
+			{Name: "max", Kind: ParamFloat, Default: 0.2, Min: 0.001, Max: 1},
+		},
+		New:    func(cfg IndicatorConfig) Indicator { return NewPSAR(cfg.Float("step"), cfg.Float("max")) },
+		WarmUp: func(cfg IndicatorConfig) int { return 2 },
+	})
+}
+
+func (p *PSAR) Name() string { return "PSAR" }
+
+func (p *PSAR) Update(candle model.Candle) {
+	high, low, close := float64(candle.High), float64(candle.Low), float64(candle.Close)
+	if p.count > 0 {
+		st := p.next(high, low, close)
+		p.rising, p.sar, p.ep, p.af = st.rising, st.sar, st.ep, st.af
+	}
+	p.prev2High, p.prev2Low = p.prevHigh, p.prevLow
+	p.prevHigh, p.prevLow, p.prevClose = high, low, close
+	p.count++
+}
+
+// next advances the SAR by one candle without mutating state.
+// Must only be called once at least one candle has been seen.
+func (p *PSAR) next(high, low, close float64) psarState {
+	st := psarState{rising: p.rising, sar: p.sar, ep: p.ep, af: p.af}
+	if p.count == 1 {
+		// Second candle: seed trend from close direction
+		if close > p.prevClose {
+			st = psarState{rising: true, sar: p.prevLow, ep: high, af: p.step}
+		} else {
+			st = psarState{rising: false, sar: p.prevHigh, ep: low, af: p.step}
+		}
+	}
+
+	st.sar += st.af * (st.ep - st.sar)
+
+	reversed := false
+	if st.rising && st.sar > low {
+		reversed = true
+		st = psarState{rising: false, sar: math.Max(high, st.ep), ep: low, af: p.step}
+	} else if !st.rising && st.sar < high {
+		reversed = true
+		st = psarState{rising: true, sar: math.Min(low, st.ep), ep: high, af: p.step}
+	}
+
+	if !reversed {
+		if st.rising && high > st.ep {
+			st.ep = high
+			st.af = math.Min(st.af+p.step, p.maxAF)
+		} else if !st.rising && low < st.ep {
+			st.ep = low
+			st.af = math.Min(st.af+p.step, p.maxAF)
+		}
+	}
+
+	if st.rising {
+		st.sar = math.Min(st.sar, p.prevLow)
+		if p.count > 1 {
+			st.sar = math.Min(st.sar, p.prev2Low)
+		}
+	} else {
+		st.sar = math.Max(st.sar, p.prevHigh)
+		if p.count > 1 {
+			st.sar = math.Max(st.sar, p.prev2High)
+		}
+	}
+	return st
+}
+
+func (p *PSAR) Value() float64 { return p.sar }
+func (p *PSAR) Ready() bool    { return p.count >= 2 }
+
+// Peek computes what the SAR would be with the forming candle without mutating state.
+func (p *PSAR) Peek(forming model.TFCandle) float64 {
+	if p.count == 0 {
+		return p.sar
+	}
+	return p.next(float64(forming.High), float64(forming.Low), float64(forming.Close)).sar
+}
+
+// Snapshot serializes the PSAR state for checkpoint persistence.
+func (p *PSAR) Snapshot() IndicatorSnapshot {
+	direction := -1
+	if p.rising {
+		direction = 1
+	}
+	return IndicatorSnapshot{
+		Type:       "PSAR",
+		Params:     Params{"step": p.step, "max": p.maxAF},
+		Multiplier: p.step,
+		MaxAF:      p.maxAF,
+		Count:      p.count,
+		Current:    p.sar,
+		EP:         p.ep,
+		AF:         p.af,
+		Direction:  direction,
+		PrevHigh:   p.prevHigh,
+		PrevLow:    p.prevLow,
+		PrevClose:  p.prevClose,
+		Prev2High:  p.prev2High,
+		Prev2Low:   p.prev2Low,
+	}
+}
+
+// RestoreFromSnapshot restores PSAR state from a checkpoint.
+func (p *PSAR) RestoreFromSnapshot(snap IndicatorSnapshot) error {
+	p.step = snap.Multiplier
+	p.maxAF = snap.MaxAF
+	p.count = snap.Count
+	p.sar = snap.Current
+	p.ep = snap.EP
+	p.af = snap.AF
+	p.rising = snap.Direction > 0
+	p.prevHigh = snap.PrevHigh
+	p.prevLow = snap.PrevLow
+	p.prevClose = snap.PrevClose
+	p.prev2High = snap.Prev2High
+	p.prev2Low = snap.Prev2Low
+	return nil
+}
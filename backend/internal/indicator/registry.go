@@ -0,0 +1,211 @@
+package indicator
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ParamKind is the value type of an indicator parameter.
+type ParamKind int
+
+const (
+	ParamInt ParamKind = iota
+	ParamFloat
+	ParamEnum // an int whose values Min, Min+1, ... are named by ParamSpec.Values
+)
+
+// ParamSpec describes one parameter of a registered indicator type.
+type ParamSpec struct {
+	Name    string    // e.g. "length", "anchor"
+	Kind    ParamKind // int, float or enum
+	Default float64   // used when a client omits the parameter
+	Min     float64   // inclusive lower bound
+	Max     float64   // inclusive upper bound; 0 = unbounded
+	Values  []string  // enum: value names, in order from Min
+}
+
+// check validates v against the spec's kind and bounds.
+func (p ParamSpec) check(v float64) error {
+	if p.Kind != ParamFloat && v != math.Trunc(v) {
+		return fmt.Errorf("%s=%g must be an integer", p.Name, v)
+	}
+	if v < p.Min || (p.Max > 0 && v > p.Max) {
+		if p.Max > 0 {
+			return fmt.Errorf("%s=%g out of range [%g, %g]", p.Name, v, p.Min, p.Max)
+		}
+		return fmt.Errorf("%s=%g must be >= %g", p.Name, v, p.Min)
+	}
+	return nil
+}
+
+// SnapshotCodec converts an indicator's state to and from an IndicatorSnapshot.
+type SnapshotCodec interface {
+	Encode(ind Indicator) IndicatorSnapshot
+	Decode(ind Indicator, snap IndicatorSnapshot) error
+}
+
+// snapshottableCodec is the default codec for indicators implementing Snapshottable.
+type snapshottableCodec struct{}
+
+func (snapshottableCodec) Encode(ind Indicator) IndicatorSnapshot {
+	return ind.(Snapshottable).Snapshot()
+}
+
+func (snapshottableCodec) Decode(ind Indicator, snap IndicatorSnapshot) error {
+	return ind.(Snapshottable).RestoreFromSnapshot(snap)
+}
+
+// Registration describes an indicator type: how to build it, what
+// parameters it takes and how its state is checkpointed. Each indicator
+// file registers itself from init(), so adding an indicator means adding
+// one file.
+type Registration struct {
+	// Type is the config type name, e.g. "SMA". It must equal Indicator.Name().
+	Type string
+
+	// Params is the parameter schema, in positional order. The first
+	// parameter may also be given through the legacy IndicatorConfig.Period.
+	Params []ParamSpec
+
+	// New creates a fresh instance for a validated config.
+	New func(cfg IndicatorConfig) Indicator
+
+	// WarmUp returns the number of candles needed before the indicator is
+	// ready. Optional; defaults to the first parameter.
+	WarmUp func(cfg IndicatorConfig) int
+
+	// Validate checks constraints between parameters, after each one has
+	// passed its ParamSpec bounds. Optional.
+	Validate func(cfg IndicatorConfig) error
+
+	// Codec checkpoints indicator state. Optional; defaults to the
+	// indicator's own Snapshottable implementation.
+	Codec SnapshotCodec
+
+	// Overlay marks types whose values are on the price scale (moving
+	// averages, bands, stops) and plot over the candles. Oscillators such
+	// as RSI or MACD leave it false.
+	Overlay bool
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]*Registration)
+)
+
+// Register adds an indicator type to the registry. It panics if the type is
+// empty, already registered, or has no factory or parameters — these are
+// programming errors caught at init time.
+func Register(r Registration) {
+	if r.Type == "" || r.New == nil || len(r.Params) == 0 {
+		panic("indicator: Register requires Type, New and Params")
+	}
+	if r.Codec == nil {
+		r.Codec = snapshottableCodec{}
+	}
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, dup := registry[r.Type]; dup {
+		panic("indicator: Register called twice for " + r.Type)
+	}
+	registry[r.Type] = &r
+}
+
+// Lookup returns the registration for an indicator type (case-sensitive, upper-case).
+func Lookup(typ string) (*Registration, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	r, ok := registry[typ]
+	return r, ok
+}
+
+// Types returns the registered indicator types in sorted order.
+func Types() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	types := make([]string, 0, len(registry))
+	for t := range registry {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// hasParam reports whether the schema declares a parameter name.
+func (r *Registration) hasParam(name string) bool {
+	for _, p := range r.Params {
+		if p.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// lookupConfig returns the registration for a config's type, or a
+// descriptive error listing the known types.
+func lookupConfig(typ string) (*Registration, error) {
+	r, ok := Lookup(typ)
+	if !ok {
+		return nil, fmt.Errorf("unknown indicator type %q (known: %s)", typ, strings.Join(Types(), ", "))
+	}
+	return r, nil
+}
+
+// warmUp returns the number of candles a config needs to become ready,
+// following chained inputs.
+func (r *Registration) warmUp(cfg IndicatorConfig) int {
+	n := int(cfg.values(r)[0])
+	if r.WarmUp != nil {
+		n = r.WarmUp(cfg)
+	}
+	if cfg.Input != nil {
+		if in, ok := Lookup(cfg.Input.Type); ok {
+			n += in.warmUp(*cfg.Input)
+		}
+	}
+	return n
+}
+
+// WarmUpBars returns the number of candles needed to warm up a config,
+// including chained inputs. Unknown types return the period.
+func WarmUpBars(cfg IndicatorConfig) int {
+	r, ok := Lookup(cfg.Type)
+	if !ok {
+		return cfg.Period
+	}
+	return r.warmUp(cfg)
+}
+
+// encodeSnapshot checkpoints an indicator through its registered codec.
+// Chains encode their stages themselves.
+func encodeSnapshot(ind Indicator) (IndicatorSnapshot, error) {
+	switch c := ind.(type) {
+	case *Chain:
+		return c.Snapshot(), nil
+	case *multiChain:
+		return c.Snapshot(), nil
+	}
+	r, err := lookupConfig(ind.Name())
+	if err != nil {
+		return IndicatorSnapshot{}, err
+	}
+	return r.Codec.Encode(ind), nil
+}
+
+// decodeSnapshot restores an indicator through its registered codec.
+func decodeSnapshot(ind Indicator, snap IndicatorSnapshot) error {
+	switch c := ind.(type) {
+	case *Chain:
+		return c.RestoreFromSnapshot(snap)
+	case *multiChain:
+		return c.RestoreFromSnapshot(snap)
+	}
+	r, err := lookupConfig(ind.Name())
+	if err != nil {
+		return err
+	}
+	return r.Codec.Decode(ind, snap)
+}
@@ -0,0 +1,143 @@
+package indicator
+
+import (
+	"strings"
+	"testing"
+
+	"trading-systemv1/internal/model"
+)
+
+// doubleClose is a minimal custom indicator used to exercise registration.
+type doubleClose struct {
+	count int
+	last  float64
+}
+
+func (d *doubleClose) Name() string { return "TESTDOUBLE" }
+func (d *doubleClose) Update(c model.Candle) {
+	d.count++
+	d.last = 2 * float64(c.Close)
+}
+func (d *doubleClose) Value() float64 { return d.last }
+func (d *doubleClose) Ready() bool    { return d.count > 0 }
+func (d *doubleClose) Peek(f model.TFCandle) float64 {
+	return 2 * float64(f.Close)
+}
+
+// doubleCodec stores the state in the flat snapshot without Snapshottable.
+type doubleCodec struct{}
+
+func (doubleCodec) Encode(ind Indicator) IndicatorSnapshot {
+	d := ind.(*doubleClose)
+	return IndicatorSnapshot{Type: "TESTDOUBLE", Period: 1, Count: d.count, Current: d.last}
+}
+
+func (doubleCodec) Decode(ind Indicator, snap IndicatorSnapshot) error {
+	d := ind.(*doubleClose)
+	d.count, d.last = snap.Count, snap.Current
+	return nil
+}
+
+func init() {
+	Register(Registration{
+		Type:   "TESTDOUBLE",
+		Params: []ParamSpec{{Name: "length", Kind: ParamInt, Default: 1, Min: 1, Max: 1}},
+		New:    func(cfg IndicatorConfig) Indicator { return &doubleClose{} },
+		Codec:  doubleCodec{},
+	})
+}
+
+func TestRegistry_CustomIndicatorEndToEnd(t *testing.T) {
+	cfg, err := ParseIndicatorConfig("testdouble:1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	configs := []TFIndicatorConfig{{TF: 60, Indicators: []IndicatorConfig{cfg}}}
+	if err := ValidateConfigs(configs); err != nil {
+		t.Fatal(err)
+	}
+
+	engine := NewEngine(configs)
+	r := engine.Process(makeTFCandle("R1", 60, 10000))
+	if len(r) != 1 || r[0].Name != "TESTDOUBLE_1" || r[0].Value != 20000 {
+		t.Fatalf("unexpected results %+v", r)
+	}
+
+	snap, err := SnapshotEngine(engine, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine2, err := RestoreEngine(configs, snap)
+	if err != nil {
+		t.Fatal(err)
+	}
+	forming := makeTFCandle("R1", 60, 12000)
+	forming.Forming = true
+	if p := engine2.ProcessPeek(forming); len(p) != 1 || p[0].Value != 24000 || !p[0].Ready {
+		t.Errorf("expected restored custom indicator to peek 24000, got %+v", p)
+	}
+}
+
+func TestRegistry_UnknownTypeRejected(t *testing.T) {
+	_, err := ParseIndicatorConfig("SMAA:20")
+	if err == nil || !strings.Contains(err.Error(), `unknown indicator type "SMAA"`) {
+		t.Errorf("expected unknown type error, got %v", err)
+	}
+
+	bad := []TFIndicatorConfig{{TF: 60, Indicators: []IndicatorConfig{{Type: "SMAA", Period: 20}}}}
+	if err := ValidateConfigs(bad); err == nil {
+		t.Error("expected ValidateConfigs to reject unknown type")
+	}
+
+	// The engine drops unknown types instead of silently computing an SMA
+	engine := NewEngine([]TFIndicatorConfig{{TF: 60, Indicators: []IndicatorConfig{
+		{Type: "SMAA", Period: 20},
+		{Type: "EMA", Period: 5},
+	}}})
+	r := engine.Process(makeTFCandle("U1", 60, 10000))
+	if len(r) != 1 || r[0].Name != "EMA_5" {
+		t.Errorf("expected only EMA_5, got %+v", r)
+	}
+}
+
+func TestRegistry_ParamBounds(t *testing.T) {
+	for _, ic := range []IndicatorConfig{
+		{Type: "VWAP", Period: 4},
+		{Type: "PSAR", Period: 25},
+		{Type: "SMA", Period: -1},
+		{Type: "MACD", Params: Params{"fast": 26, "slow": 12}}, // fast must be below slow
+		{Type: "MACD", Params: Params{"fast": 12, "slow": 12}},
+	} {
+		if err := ValidateIndicator(ic); err == nil {
+			t.Errorf("expected %s to be rejected", ic.Name())
+		}
+	}
+}
+
+func TestRegistry_WarmUpBars(t *testing.T) {
+	cases := map[string]int{
+		"SMA:20":       20,
+		"RSI:14":       15,
+		"MACD:9":       34,
+		"EMA:9@RSI:14": 24,
+	}
+	for spec, want := range cases {
+		cfg, err := ParseIndicatorConfig(spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := WarmUpBars(cfg); got != want {
+			t.Errorf("%s: WarmUpBars=%d, want %d", spec, got, want)
+		}
+	}
+}
+
+func TestRegistry_BuiltinTypes(t *testing.T) {
+	want := []string{"ADX", "ATR", "BB", "EMA", "MACD", "PSAR", "RSI", "SMA", "SMMA", "STOCH", "SUPERTREND", "VWAP"}
+	types := strings.Join(Types(), ",")
+	for _, typ := range want {
+		if !strings.Contains(","+types+",", ","+typ+",") {
+			t.Errorf("expected %s to be registered, got %s", typ, types)
+		}
+	}
+}
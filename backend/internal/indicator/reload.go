@@ -3,67 +3,84 @@ package indicator
 import (
 	"fmt"
 	"log"
-	"trading-systemv1/internal/model"
 )
 
 // ReloadConfigs updates the indicator engine with new configurations.
 // It preserves state for indicators that already exist and only creates
 // new instances for genuinely new indicators. This prevents losing
 // accumulated state (warmup history) when adding a new indicator.
-// Returns the number of preserved and new indicator instances.
+// Resolution is per token: instruments whose profile is unchanged keep
+// their state untouched, changed ones are migrated by result name, and
+// tokens no longer covered by any profile or default are dropped. Tokens
+// already known on another TF get state on newly covering TFs right away.
+// The new instances start cold and are recorded for WarmUp.
+// Returns the number of preserved token states and of token states or
+// timeframes with new instances.
 func (e *Engine) ReloadConfigs(newConfigs []TFIndicatorConfig) (preserved, created int) {
-	// Build lookup of old configs + state by TF
-	oldCfgByTF := make(map[int]TFIndicatorConfig)
-	oldStateByTF := make(map[int]map[string]*tokenIndicators)
-	for i, cfg := range e.configs {
-		oldCfgByTF[cfg.TF] = cfg
-		oldStateByTF[cfg.TF] = e.state[i]
-	}
-
-	// Build new state array
-	newState := make([]map[string]*tokenIndicators, len(newConfigs))
-	for i, newCfg := range newConfigs {
-		oldCfg, tfExists := oldCfgByTF[newCfg.TF]
-		oldTFState := oldStateByTF[newCfg.TF]
-
-		if !tfExists || oldTFState == nil {
-			// Brand-new TF — cold-start
-			newState[i] = make(map[string]*tokenIndicators, 64)
-			created++
-			log.Printf("[reload] TF=%d: new timeframe, cold-starting", newCfg.TF)
-			continue
+	newConfigs = validConfigs(newConfigs)
+
+	oldState := make(map[int]map[string]*tokenIndicators, len(e.tfs))
+	known := make(map[string]bool)
+	for i, tf := range e.tfs {
+		oldState[tf] = e.state[i]
+		for tokenKey := range e.state[i] {
+			known[tokenKey] = true
 		}
+	}
 
-		// TF exists — check if indicators are identical (fast path)
-		if indicatorSetsEqual(oldCfg.Indicators, newCfg.Indicators) {
-			newState[i] = oldTFState
-			preserved += len(oldTFState)
-			log.Printf("[reload] TF=%d: unchanged, preserved %d token states", newCfg.TF, len(oldTFState))
-			continue
+	e.setConfigs(newConfigs)
+	e.warming = nil
+	if e.amends != nil {
+		e.amends = make(map[amendKey][]amendEntry) // logged states no longer match the instances
+	}
+	newState := make([]map[string]*tokenIndicators, len(e.tfs))
+	for tfIdx, tf := range e.tfs {
+		newState[tfIdx] = make(map[string]*tokenIndicators, 64)
+		oldTFState, tfExists := oldState[tf]
+		if !tfExists {
+			created++
+			log.Printf("[reload] TF=%d: new timeframe, cold-starting", tf)
 		}
 
-		// Indicator set changed — migrate per-token state
-		// Build index: which old indicators map to which new positions
-		migrated := make(map[string]*tokenIndicators, len(oldTFState))
+		kept, migrated, dropped, seeded := 0, 0, 0, 0
 		for tokenKey, oldTI := range oldTFState {
-			newTI := migrateTokenIndicators(oldTI, oldCfg.Indicators, newCfg.Indicators)
-			migrated[tokenKey] = newTI
-			preserved++
+			configs, ok := e.profiles[tfIdx].resolve(tokenKey)
+			switch {
+			case !ok:
+				dropped++
+			case indicatorSetsEqual(oldTI.configs, configs):
+				// Unchanged for this token (fast path)
+				newState[tfIdx][tokenKey] = oldTI
+				kept++
+			default:
+				ti, fresh := migrateTokenIndicators(oldTI, configs)
+				newState[tfIdx][tokenKey] = ti
+				e.markWarming(tf, tokenKey, ti, fresh)
+				migrated++
+			}
 		}
-		newState[i] = migrated
-		created++ // mark that new indicators need backfill
-		log.Printf("[reload] TF=%d: migrated %d token states (added new indicators)", newCfg.TF, len(migrated))
+		for tokenKey := range known {
+			if _, ok := oldTFState[tokenKey]; ok {
+				continue
+			}
+			configs, ok := e.profiles[tfIdx].resolve(tokenKey)
+			if !ok || len(configs) == 0 {
+				continue
+			}
+			ti := createTokenIndicators(configs)
+			newState[tfIdx][tokenKey] = ti
+			e.markWarming(tf, tokenKey, ti, allPositions(len(configs)))
+			seeded++
+		}
+		preserved += kept + migrated
+		if tfExists && migrated+seeded > 0 {
+			created++ // mark that new indicators need backfill
+		}
+		log.Printf("[reload] TF=%d: preserved %d token states, migrated %d, seeded %d, dropped %d",
+			tf, kept, migrated, seeded, dropped)
 	}
-
-	e.configs = newConfigs
 	e.state = newState
 
-	// Rebuild TF index for O(1) lookup
-	e.tfIndex = make(map[int]int, len(newConfigs))
-	for i, cfg := range newConfigs {
-		e.tfIndex[cfg.TF] = i
-	}
-
 	log.Printf("[reload] ✅ config reloaded: %d configs, %d preserved, %d new",
 		len(newConfigs), preserved, created)
 
@@ -71,52 +88,52 @@ func (e *Engine) ReloadConfigs(newConfigs []TFIndicatorConfig) (preserved, creat
 }
 
 // migrateTokenIndicators creates a new tokenIndicators for the new config,
-// preserving state from existing indicators that match by Type+Period.
-func migrateTokenIndicators(oldTI *tokenIndicators, oldConfigs, newConfigs []IndicatorConfig) *tokenIndicators {
-	// Build lookup of old indicators by "TYPE_PERIOD"
+// preserving state from existing indicators that match by result name
+// (type, parameters, source and chained input). It also returns the
+// positions of the instances it had to create fresh.
+func migrateTokenIndicators(oldTI *tokenIndicators, newConfigs []IndicatorConfig) (*tokenIndicators, []int) {
+	// Build lookup of old indicators by result name ("TYPE_PERIOD[_source]")
 	oldByKey := make(map[string]Indicator, len(oldTI.indicators))
 	for i, cfg := range oldTI.configs {
-		key := cfg.Type + "_" + model.Itoa(cfg.Period)
-		oldByKey[key] = oldTI.indicators[i]
+		oldByKey[cfg.Name()] = oldTI.indicators[i]
 	}
 
 	// Build new indicator instances, reusing old ones where possible
 	newInds := make([]Indicator, len(newConfigs))
+	var fresh []int
 	for i, cfg := range newConfigs {
-		key := cfg.Type + "_" + model.Itoa(cfg.Period)
-		if existing, ok := oldByKey[key]; ok {
+		if existing, ok := oldByKey[cfg.Name()]; ok {
 			newInds[i] = existing // preserve accumulated state
 		} else {
-			// New indicator — create fresh instance
-			switch cfg.Type {
-			case "SMA":
-				newInds[i] = NewSMA(cfg.Period)
-			case "EMA":
-				newInds[i] = NewEMA(cfg.Period)
-			case "SMMA":
-				newInds[i] = NewSMMA(cfg.Period)
-			case "RSI":
-				newInds[i] = NewRSI(cfg.Period)
-			default:
-				newInds[i] = NewSMA(cfg.Period)
-			}
+			newInds[i] = newIndicator(cfg) // new indicator — create fresh instance
+			fresh = append(fresh, i)
 		}
 	}
 
 	return &tokenIndicators{
 		indicators: newInds,
 		configs:    newConfigs,
+	}, fresh
+}
+
+// allPositions returns 0..n-1.
+func allPositions(n int) []int {
+	out := make([]int, n)
+	for i := range out {
+		out[i] = i
 	}
+	return out
 }
 
-// findConfig returns the TFIndicatorConfig for the given TF, or empty if not found.
-func (e *Engine) findConfig(tf int) TFIndicatorConfig {
-	for _, cfg := range e.configs {
-		if cfg.TF == tf {
-			return cfg
-		}
+// findConfig returns the indicator set a token gets on the given TF, or nil
+// if the TF is not configured or nothing covers the token.
+func (e *Engine) findConfig(tf int, key string) []IndicatorConfig {
+	tfIdx, ok := e.tfIndex[tf]
+	if !ok {
+		return nil
 	}
-	return TFIndicatorConfig{}
+	configs, _ := e.profiles[tfIdx].resolve(key)
+	return configs
 }
 
 // indicatorSetsEqual checks if two indicator config slices have the exact same
@@ -127,39 +144,94 @@ func indicatorSetsEqual(a, b []IndicatorConfig) bool {
 	}
 	setA := make(map[string]bool, len(a))
 	for _, ic := range a {
-		setA[ic.Type+"_"+model.Itoa(ic.Period)] = true
+		setA[ic.Name()] = true
 	}
 	for _, ic := range b {
-		if !setA[ic.Type+"_"+model.Itoa(ic.Period)] {
+		if !setA[ic.Name()] {
 			return false
 		}
 	}
 	return true
 }
 
-// ValidateConfigs checks a set of TFIndicatorConfigs for errors.
+// ValidateConfigs checks a set of TFIndicatorConfigs for errors. Each TF may
+// have one default config and any number of instrument profiles, but an
+// instrument may appear in only one profile per TF.
 func ValidateConfigs(configs []TFIndicatorConfig) error {
 	seen := make(map[int]bool)
+	type scope struct {
+		tf  int
+		key string
+	}
+	owners := make(map[scope]TFIndicatorConfig)
 	for _, cfg := range configs {
 		if cfg.TF <= 0 {
 			return fmt.Errorf("invalid TF=%d: must be positive", cfg.TF)
 		}
-		if seen[cfg.TF] {
-			return fmt.Errorf("duplicate TF=%d", cfg.TF)
+		if len(cfg.Instruments) == 0 {
+			if seen[cfg.TF] {
+				return fmt.Errorf("duplicate TF=%d", cfg.TF)
+			}
+			seen[cfg.TF] = true
+		}
+		for _, key := range cfg.Instruments {
+			if !validInstrumentKey(key) {
+				return fmt.Errorf("invalid instrument %q in %s: want exchange:token", key, profileLabel(cfg))
+			}
+			if prev, dup := owners[scope{cfg.TF, key}]; dup {
+				return fmt.Errorf("instrument %s is in both %s and %s on TF=%d",
+					key, profileLabel(prev), profileLabel(cfg), cfg.TF)
+			}
+			owners[scope{cfg.TF, key}] = cfg
 		}
-		seen[cfg.TF] = true
 
 		for _, ind := range cfg.Indicators {
-			switch ind.Type {
-			case "SMA", "EMA", "SMMA", "RSI":
-				// valid
-			default:
-				return fmt.Errorf("unknown indicator type %q for TF=%d", ind.Type, cfg.TF)
-			}
-			if ind.Period <= 0 {
-				return fmt.Errorf("invalid period=%d for %s on TF=%d", ind.Period, ind.Type, cfg.TF)
+			if err := ValidateIndicator(ind); err != nil {
+				return fmt.Errorf("%v on TF=%d", err, cfg.TF)
 			}
 		}
 	}
 	return nil
 }
+
+// ValidateIndicator checks a single indicator config against the registry,
+// including any chained inputs.
+func ValidateIndicator(ind IndicatorConfig) error {
+	r, err := lookupConfig(ind.Type)
+	if err != nil {
+		return err
+	}
+	for name := range ind.Params {
+		if !r.hasParam(name) {
+			return fmt.Errorf("%s: unknown parameter %q", ind.Type, name)
+		}
+	}
+	for i, v := range ind.values(r) {
+		if err := r.Params[i].check(v); err != nil {
+			return fmt.Errorf("%s: %v", ind.Type, err)
+		}
+	}
+	if r.Validate != nil {
+		if err := r.Validate(ind); err != nil {
+			return fmt.Errorf("%s: %v", ind.Type, err)
+		}
+	}
+	if _, err := ParseSource(string(ind.Source)); err != nil {
+		return fmt.Errorf("%v for %s", err, ind.Type)
+	}
+	if !ind.Source.IsClose() {
+		if _, ok := r.New(ind).(sourced); !ok {
+			return fmt.Errorf("%s does not support source %q", ind.Type, ind.Source)
+		}
+	}
+	if ind.Input != nil {
+		if _, ok := r.New(ind).(priceInput); !ok {
+			return fmt.Errorf("%s cannot take another indicator as input", ind.Type)
+		}
+		if !ind.Source.IsClose() {
+			return fmt.Errorf("%s of %s: set the source on the innermost indicator", ind.Type, ind.Input.Type)
+		}
+		return ValidateIndicator(*ind.Input)
+	}
+	return nil
+}
@@ -62,8 +62,8 @@ func (r *Restorer) ReplayCandles(engine *Engine, candles []model.TFCandle) int {
 // into the engine to warm up cold indicators. This should be called after
 // engine creation/restore and before starting the live stream consumer.
 //
-// maxPeriod is the largest indicator period (e.g. 200 for SMA_200).
-// It reads `maxPeriod` candles per TF to ensure all indicators warm up.
+// It reads as many candles per TF as the slowest indicator needs to warm up
+// (its registered WarmUp, e.g. 200 for SMA_200, 34 for MACD_9).
 // If onResults is non-nil, it is called with the indicator results for each candle,
 // allowing the caller to write them to Redis for history population.
 func (r *Restorer) BackfillFromSQLite(engine *Engine, reader SQLiteReader, onResults func([]model.IndicatorResult)) int {
@@ -71,12 +71,12 @@ func (r *Restorer) BackfillFromSQLite(engine *Engine, reader SQLiteReader, onRes
 		return 0
 	}
 
-	// Find max period across all configs
+	// Find the longest warm-up across all configs
 	maxPeriod := 0
 	for _, cfg := range r.configs {
 		for _, ind := range cfg.Indicators {
-			if ind.Period > maxPeriod {
-				maxPeriod = ind.Period
+			if n := WarmUpBars(ind); n > maxPeriod {
+				maxPeriod = n
 			}
 		}
 	}
@@ -85,7 +85,12 @@ func (r *Restorer) BackfillFromSQLite(engine *Engine, reader SQLiteReader, onRes
 	}
 
 	total := 0
+	seen := make(map[int]bool, len(r.configs))
 	for _, cfg := range r.configs {
+		if seen[cfg.TF] {
+			continue // profiles share the TF's candles — read them once
+		}
+		seen[cfg.TF] = true
 		candles, err := reader.ReadAllTFCandles(cfg.TF, 0)
 		if err != nil {
 			log.Printf("[restorer] WARNING: failed to read TF=%d candles from SQLite: %v", cfg.TF, err)
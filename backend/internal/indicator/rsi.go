@@ -5,6 +5,7 @@ import "trading-systemv1/internal/model"
 // RSI calculates the Relative Strength Index using Wilder's smoothing method.
 // Update is O(1) per candle — no history scans.
 type RSI struct {
+	source    Source // input price (zero value = close)
 	period    int
 	count     int
 	prevClose float64
@@ -18,10 +19,23 @@ func NewRSI(period int) *RSI {
 	return &RSI{period: period}
 }
 
+func init() {
+	Register(Registration{
+		Type:   "RSI",
+		Params: []ParamSpec{{Name: "length", Kind: ParamInt, Default: 14, Min: 1}},
+		New:    func(cfg IndicatorConfig) Indicator { return NewRSI(cfg.Int("length")) },
+		WarmUp: func(cfg IndicatorConfig) int { return cfg.Int("length") + 1 },
+	})
+}
+
 func (r *RSI) Name() string { return "RSI" }
 
 func (r *RSI) Update(candle model.Candle) {
-	price := float64(candle.Close)
+	r.updatePrice(r.source.Price(candle))
+}
+
+// updatePrice feeds a single input value.
+func (r *RSI) updatePrice(price float64) {
 	r.count++
 
 	if r.count == 1 {
@@ -77,11 +91,15 @@ func (r *RSI) Value() float64 { return r.current }
 func (r *RSI) Ready() bool    { return r.count > r.period }
 
 // Peek computes what RSI would be with an additional candle without mutating state.
-func (r *RSI) Peek(closePaise int64) float64 {
+func (r *RSI) Peek(forming model.TFCandle) float64 {
+	return r.peekPrice(r.source.FormingPrice(forming))
+}
+
+// peekPrice previews RSI for a single input value without mutating state.
+func (r *RSI) peekPrice(price float64) float64 {
 	if r.count <= r.period {
 		return r.current
 	}
-	price := float64(closePaise)
 	delta := price - r.prevClose
 	gain, loss := 0.0, 0.0
 	if delta > 0 {
@@ -103,6 +121,7 @@ func (r *RSI) Peek(closePaise int64) float64 {
 func (r *RSI) Snapshot() IndicatorSnapshot {
 	return IndicatorSnapshot{
 		Type:      "RSI",
+		Source:    string(r.source),
 		Period:    r.period,
 		Count:     r.count,
 		PrevClose: r.prevClose,
@@ -114,6 +133,7 @@ func (r *RSI) Snapshot() IndicatorSnapshot {
 
 // RestoreFromSnapshot restores RSI state from a checkpoint.
 func (r *RSI) RestoreFromSnapshot(snap IndicatorSnapshot) error {
+	r.source = Source(snap.Source)
 	r.period = snap.Period
 	r.count = snap.Count
 	r.prevClose = snap.PrevClose
@@ -122,3 +142,6 @@ func (r *RSI) RestoreFromSnapshot(snap IndicatorSnapshot) error {
 	r.current = snap.Current
 	return nil
 }
+
+// setSource implements sourced.
+func (r *RSI) setSource(src Source) { r.source = src }
@@ -5,6 +5,7 @@ import "trading-systemv1/internal/model"
 // SMA calculates Simple Moving Average over a rolling window.
 // Uses a preallocated circular buffer for zero-allocation hot path.
 type SMA struct {
+	source  Source // input price (zero value = close)
 	period  int
 	buf     []float64 // preallocated circular buffer
 	idx     int       // current write position
@@ -21,10 +22,24 @@ func NewSMA(period int) *SMA {
 	}
 }
 
+func init() {
+	Register(Registration{
+		Type:    "SMA",
+		Overlay: true,
+		Params:  []ParamSpec{{Name: "length", Kind: ParamInt, Default: 20, Min: 1}},
+		New:     func(cfg IndicatorConfig) Indicator { return NewSMA(cfg.Int("length")) },
+	})
+}
+
 func (s *SMA) Name() string { return "SMA" }
 
 func (s *SMA) Update(candle model.Candle) {
-	price := float64(candle.Close)
+	s.updatePrice(s.source.Price(candle))
+}
+
+// updatePrice feeds a raw value. Used directly by indicators that smooth
+// derived series (e.g. Stochastic %K/%D) rather than candle closes.
+func (s *SMA) updatePrice(price float64) {
 
 	if s.count >= s.period {
 		// Subtract the oldest value being overwritten
@@ -45,8 +60,12 @@ func (s *SMA) Value() float64 { return s.current }
 func (s *SMA) Ready() bool    { return s.count >= s.period }
 
 // Peek computes what Value() would be with an additional candle without mutating state.
-func (s *SMA) Peek(closePaise int64) float64 {
-	price := float64(closePaise)
+func (s *SMA) Peek(forming model.TFCandle) float64 {
+	return s.peekPrice(s.source.FormingPrice(forming))
+}
+
+// peekPrice is the raw-value counterpart of Peek.
+func (s *SMA) peekPrice(price float64) float64 {
 	if s.count < s.period {
 		// Not fully ready — return partial average including this price
 		return (s.sum + price) / float64(s.count+1)
@@ -72,6 +91,7 @@ func (s *SMA) Snapshot() IndicatorSnapshot {
 	copy(bufCopy, s.buf)
 	return IndicatorSnapshot{
 		Type:    "SMA",
+		Source:  string(s.source),
 		Period:  s.period,
 		Buf:     bufCopy,
 		Idx:     s.idx,
@@ -83,6 +103,7 @@ func (s *SMA) Snapshot() IndicatorSnapshot {
 
 // RestoreFromSnapshot restores SMA state from a checkpoint.
 func (s *SMA) RestoreFromSnapshot(snap IndicatorSnapshot) error {
+	s.source = Source(snap.Source)
 	s.period = snap.Period
 	s.idx = snap.Idx
 	s.count = snap.Count
@@ -96,3 +117,6 @@ func (s *SMA) RestoreFromSnapshot(snap IndicatorSnapshot) error {
 	}
 	return nil
 }
+
+// setSource implements sourced.
+func (s *SMA) setSource(src Source) { s.source = src }
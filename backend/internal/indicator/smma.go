@@ -5,6 +5,7 @@ import "trading-systemv1/internal/model"
 // SMMA calculates Smoothed Moving Average (Wilder-style smoothing).
 // First value is SMA(period), then SMMA = (prev*(period-1) + price) / period.
 type SMMA struct {
+	source  Source // input price (zero value = close)
 	period  int
 	count   int
 	sum     float64
@@ -16,10 +17,24 @@ func NewSMMA(period int) *SMMA {
 	return &SMMA{period: period}
 }
 
+func init() {
+	Register(Registration{
+		Type:    "SMMA",
+		Overlay: true,
+		Params:  []ParamSpec{{Name: "length", Kind: ParamInt, Default: 20, Min: 1}},
+		New:     func(cfg IndicatorConfig) Indicator { return NewSMMA(cfg.Int("length")) },
+	})
+}
+
 func (s *SMMA) Name() string { return "SMMA" }
 
 func (s *SMMA) Update(candle model.Candle) {
-	price := float64(candle.Close)
+	s.updatePrice(s.source.Price(candle))
+}
+
+// updatePrice feeds a raw value. Used directly by indicators that apply
+// Wilder smoothing to derived series (e.g. true range, directional movement).
+func (s *SMMA) updatePrice(price float64) {
 	s.count++
 
 	if s.count <= s.period {
@@ -39,8 +54,12 @@ func (s *SMMA) Value() float64 { return s.current }
 func (s *SMMA) Ready() bool    { return s.count >= s.period }
 
 // Peek computes what Value() would be with an additional candle without mutating state.
-func (s *SMMA) Peek(closePaise int64) float64 {
-	price := float64(closePaise)
+func (s *SMMA) Peek(forming model.TFCandle) float64 {
+	return s.peekPrice(s.source.FormingPrice(forming))
+}
+
+// peekPrice is the raw-value counterpart of Peek.
+func (s *SMMA) peekPrice(price float64) float64 {
 	if s.count < s.period {
 		return (s.sum + price) / float64(s.count+1)
 	}
@@ -58,6 +77,7 @@ func (s *SMMA) Reset() {
 func (s *SMMA) Snapshot() IndicatorSnapshot {
 	return IndicatorSnapshot{
 		Type:    "SMMA",
+		Source:  string(s.source),
 		Period:  s.period,
 		Count:   s.count,
 		Sum:     s.sum,
@@ -67,9 +87,13 @@ func (s *SMMA) Snapshot() IndicatorSnapshot {
 
 // RestoreFromSnapshot restores SMMA state from a checkpoint.
 func (s *SMMA) RestoreFromSnapshot(snap IndicatorSnapshot) error {
+	s.source = Source(snap.Source)
 	s.period = snap.Period
 	s.count = snap.Count
 	s.sum = snap.Sum
 	s.current = snap.Current
 	return nil
 }
+
+// setSource implements sourced.
+func (s *SMMA) setSource(src Source) { s.source = src }
@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
-	"trading-systemv1/internal/model"
 )
 
 // Snapshottable is implemented by indicators that support state serialization.
@@ -16,8 +15,10 @@ type Snapshottable interface {
 
 // IndicatorSnapshot holds the serialized state of a single indicator instance.
 type IndicatorSnapshot struct {
-	Type   string `json:"type"`   // "SMA", "EMA", "SMMA", "RSI"
-	Period int    `json:"period"` // indicator period
+	Type   string `json:"type"`             // registered indicator type, e.g. "SMA", "MACD"
+	Period int    `json:"period"`           // indicator period
+	Params Params `json:"params,omitempty"` // all config parameters by name
+	Source string `json:"source,omitempty"` // input price source; empty = close
 
 	// SMA fields
 	Buf     []float64 `json:"buf,omitempty"`
@@ -33,6 +34,59 @@ type IndicatorSnapshot struct {
 	PrevClose float64 `json:"prev_close,omitempty"`
 	AvgGain   float64 `json:"avg_gain,omitempty"`
 	AvgLoss   float64 `json:"avg_loss,omitempty"`
+
+	// Stochastic fields
+	Highs []float64 `json:"highs,omitempty"`
+	Lows  []float64 `json:"lows,omitempty"`
+
+	// ADX / Parabolic SAR fields
+	PrevHigh  float64 `json:"prev_high,omitempty"`
+	PrevLow   float64 `json:"prev_low,omitempty"`
+	Prev2High float64 `json:"prev2_high,omitempty"`
+	Prev2Low  float64 `json:"prev2_low,omitempty"`
+	AF        float64 `json:"af,omitempty"`
+	EP        float64 `json:"ep,omitempty"`
+	MaxAF     float64 `json:"max_af,omitempty"`
+
+	// SuperTrend / Parabolic SAR fields
+	Upper     float64 `json:"upper,omitempty"`
+	Lower     float64 `json:"lower,omitempty"`
+	Direction int     `json:"direction,omitempty"` // +1 up, -1 down
+
+	// VWAP fields
+	AnchorTS int64   `json:"anchor_ts,omitempty"` // unix seconds of the current anchor open
+	CumPV    float64 `json:"cum_pv,omitempty"`
+	CumPV2   float64 `json:"cum_pv2,omitempty"`
+	CumVol   float64 `json:"cum_vol,omitempty"`
+	SumSq    float64 `json:"sum_sq,omitempty"`
+
+	// Multi-output fields: nested states of internal sub-indicators
+	// (MACD fast/slow/signal EMAs, Stochastic %K/%D SMAs)
+	Children []IndicatorSnapshot `json:"children,omitempty"`
+
+	// Chain field: state of the inner indicator whose output feeds this one
+	Input *IndicatorSnapshot `json:"input,omitempty"`
+}
+
+// config returns the IndicatorConfig that produced this snapshot, including
+// any chained inputs. Used to match snapshots against current configs.
+// Snapshots without Params (single-period era) fall back to Period.
+func (s IndicatorSnapshot) config() IndicatorConfig {
+	cfg := IndicatorConfig{Type: s.Type, Period: s.Period, Params: s.Params, Source: Source(s.Source)}
+	if s.Input != nil {
+		in := s.Input.config()
+		cfg.Input = &in
+	}
+	return cfg
+}
+
+// stampParams records the config's parameters on a snapshot (and its
+// chained inputs) so it can be matched by name on restore.
+func (s *IndicatorSnapshot) stampParams(cfg IndicatorConfig) {
+	s.Params = cfg.paramMap()
+	if s.Input != nil && cfg.Input != nil {
+		s.Input.stampParams(*cfg.Input)
+	}
 }
 
 // TokenSnapshot holds indicator snapshots for a single token within a TF.
@@ -69,11 +123,11 @@ func SnapshotEngine(e *Engine, streamID string) (*EngineSnapshot, error) {
 		Version:  1,
 	}
 
-	for tfIdx, cfg := range e.configs {
+	for tfIdx, tf := range e.tfs {
 		for tokenKey, ti := range e.state[tfIdx] {
 			ts := TokenSnapshot{
 				Token:      tokenKey,
-				TF:         cfg.TF,
+				TF:         tf,
 				Indicators: make([]IndicatorSnapshot, 0, len(ti.indicators)),
 			}
 			// Extract exchange from tokenKey if present (format: "exchange:token")
@@ -89,12 +143,13 @@ func SnapshotEngine(e *Engine, streamID string) (*EngineSnapshot, error) {
 				ts.Token = tokenKey
 			}
 
-			for _, ind := range ti.indicators {
-				si, ok := ind.(Snapshottable)
-				if !ok {
-					return nil, fmt.Errorf("indicator %s does not implement Snapshottable", ind.Name())
+			for i, ind := range ti.indicators {
+				indSnap, err := encodeSnapshot(ind)
+				if err != nil {
+					return nil, fmt.Errorf("snapshot %s: %w", ind.Name(), err)
 				}
-				ts.Indicators = append(ts.Indicators, si.Snapshot())
+				indSnap.stampParams(ti.configs[i])
+				ts.Indicators = append(ts.Indicators, indSnap)
 			}
 			snap.Tokens = append(snap.Tokens, ts)
 		}
@@ -104,52 +159,49 @@ func SnapshotEngine(e *Engine, streamID string) (*EngineSnapshot, error) {
 }
 
 // RestoreEngine rebuilds an indicator Engine from a snapshot.
-// It is tolerant of config changes — indicators are matched by Type+Period
-// rather than by index. Matching indicators get their state restored; new
+// It is tolerant of config changes — indicators are matched by result name
+// (type, parameters, source, chained input) rather than by index. Matching indicators get their state restored; new
 // indicators start fresh (cold). Removed indicators are silently skipped.
 func RestoreEngine(configs []TFIndicatorConfig, snap *EngineSnapshot) (*Engine, error) {
 	e := NewEngine(configs)
 
 	for _, ts := range snap.Tokens {
-		// Find matching TF config
-		tfIdx := -1
-		for i, cfg := range e.configs {
-			if cfg.TF == ts.TF {
-				tfIdx = i
-				break
-			}
+		// Reconstruct the token key
+		key := ts.Token
+		if ts.Exchange != "" {
+			key = ts.Exchange + ":" + ts.Token
 		}
-		if tfIdx == -1 {
+
+		// Find the token's profile on this TF
+		tfIdx, ok := e.tfIndex[ts.TF]
+		if !ok {
 			continue // TF no longer configured — skip
 		}
+		configs, ok := e.profiles[tfIdx].resolve(key)
+		if !ok {
+			continue // token no longer covered by any profile — skip
+		}
 
-		ti := e.createTokenIndicators(tfIdx)
+		ti := createTokenIndicators(configs)
 
-		// Build a lookup: "SMA:9" → IndicatorSnapshot for fast matching
+		// Build a lookup: "SMA_9" → IndicatorSnapshot for fast matching
 		snapLookup := make(map[string]IndicatorSnapshot, len(ts.Indicators))
 		for _, indSnap := range ts.Indicators {
-			lookupKey := indSnap.Type + ":" + model.Itoa(indSnap.Period)
-			snapLookup[lookupKey] = indSnap
+			snapLookup[indSnap.config().Name()] = indSnap
 		}
 
-		// Match current indicators against snapshot by Type+Period
+		// Match current indicators against snapshot by result name
 		restored, cold := 0, 0
 		for i, ind := range ti.indicators {
 			cfg := ti.configs[i]
-			lookupKey := cfg.Type + ":" + model.Itoa(cfg.Period)
 
-			indSnap, found := snapLookup[lookupKey]
+			indSnap, found := snapLookup[cfg.Name()]
 			if !found {
 				cold++
 				continue // new indicator — stays fresh/zero
 			}
 
-			si, ok := ind.(Snapshottable)
-			if !ok {
-				cold++
-				continue
-			}
-			if err := si.RestoreFromSnapshot(indSnap); err != nil {
+			if err := decodeSnapshot(ind, indSnap); err != nil {
 				// Non-fatal: log and leave cold
 				cold++
 				continue
@@ -162,11 +214,6 @@ func RestoreEngine(configs []TFIndicatorConfig, snap *EngineSnapshot) (*Engine,
 				ts.TF, ts.Token, restored, cold)
 		}
 
-		// Reconstruct the token key
-		key := ts.Token
-		if ts.Exchange != "" {
-			key = ts.Exchange + ":" + ts.Token
-		}
 		e.state[tfIdx][key] = ti
 	}
 
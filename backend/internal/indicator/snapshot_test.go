@@ -204,3 +204,186 @@ func TestSnapshot_Engine_RoundTrip(t *testing.T) {
 		}
 	}
 }
+
+func TestSnapshot_Engine_VolatilityTrend_RoundTrip(t *testing.T) {
+	configs := []TFIndicatorConfig{
+		{
+			TF: 60,
+			Indicators: []IndicatorConfig{
+				{Type: "ATR", Period: 5},
+				{Type: "ADX", Period: 5},
+				{Type: "SUPERTREND", Period: 5},
+				{Type: "PSAR"},
+			},
+		},
+	}
+
+	engine := NewEngine(configs)
+	for i := 0; i < 20; i++ {
+		engine.Process(makeTFCandleSnap("SBIN", 60, int64(10000+(i%6)*150)))
+	}
+
+	snap, err := SnapshotEngine(engine, "test-stream-id")
+	if err != nil {
+		t.Fatalf("snapshot failed: %v", err)
+	}
+	engine2, err := RestoreEngine(configs, snap)
+	if err != nil {
+		t.Fatalf("restore failed: %v", err)
+	}
+
+	for i := 0; i < 5; i++ {
+		price := int64(11000 - i*120)
+		r1 := engine.Process(makeTFCandleSnap("SBIN", 60, price))
+		r2 := engine2.Process(makeTFCandleSnap("SBIN", 60, price))
+		for j := range r1 {
+			if math.Abs(r1[j].Value-r2[j].Value) > 1e-10 {
+				t.Errorf("candle %d indicator %s: original=%.6f restored=%.6f",
+					i, r1[j].Name, r1[j].Value, r2[j].Value)
+			}
+		}
+	}
+}
+
+func TestSnapshot_Engine_Source_RoundTrip(t *testing.T) {
+	configs := []TFIndicatorConfig{
+		{
+			TF: 60,
+			Indicators: []IndicatorConfig{
+				{Type: "EMA", Period: 5},
+				{Type: "EMA", Period: 5, Source: SourceLow},
+				{Type: "RSI", Period: 5, Source: SourceOHLC4},
+			},
+		},
+	}
+
+	engine := NewEngine(configs)
+	for i := 0; i < 12; i++ {
+		engine.Process(makeTFCandleSnap("SBIN", 60, int64(10000+(i%4)*150)))
+	}
+
+	snap, err := SnapshotEngine(engine, "test-stream-id")
+	if err != nil {
+		t.Fatalf("snapshot failed: %v", err)
+	}
+	engine2, err := RestoreEngine(configs, snap)
+	if err != nil {
+		t.Fatalf("restore failed: %v", err)
+	}
+
+	for i := 0; i < 5; i++ {
+		price := int64(11000 - i*120)
+		r1 := engine.Process(makeTFCandleSnap("SBIN", 60, price))
+		r2 := engine2.Process(makeTFCandleSnap("SBIN", 60, price))
+		for j := range r1 {
+			if r1[j].Name != r2[j].Name || math.Abs(r1[j].Value-r2[j].Value) > 1e-10 {
+				t.Errorf("candle %d indicator %s: original=%.6f restored %s=%.6f",
+					i, r1[j].Name, r1[j].Value, r2[j].Name, r2[j].Value)
+			}
+		}
+	}
+}
+
+func TestSnapshot_Engine_Chain_RoundTrip(t *testing.T) {
+	emaOfRSI, _ := ParseIndicatorConfig("EMA:3@RSI:4")
+	smaOfRSI, _ := ParseIndicatorConfig("SMA:3@RSI:4")
+	configs := []TFIndicatorConfig{
+		{TF: 60, Indicators: []IndicatorConfig{{Type: "RSI", Period: 4}, emaOfRSI}},
+	}
+
+	engine := NewEngine(configs)
+	for i := 0; i < 12; i++ {
+		engine.Process(makeTFCandleSnap("SBIN", 60, int64(10000+(i%4)*150)))
+	}
+
+	snap, err := SnapshotEngine(engine, "test-stream-id")
+	if err != nil {
+		t.Fatalf("snapshot failed: %v", err)
+	}
+
+	// Restore with an extra chain that shares the inner RSI but not the outer
+	// stage — it must start cold rather than match EMA_3@RSI_4.
+	restoreConfigs := []TFIndicatorConfig{
+		{TF: 60, Indicators: []IndicatorConfig{{Type: "RSI", Period: 4}, emaOfRSI, smaOfRSI}},
+	}
+	engine2, err := RestoreEngine(restoreConfigs, snap)
+	if err != nil {
+		t.Fatalf("restore failed: %v", err)
+	}
+
+	for i := 0; i < 5; i++ {
+		price := int64(11000 - i*120)
+		r1 := engine.Process(makeTFCandleSnap("SBIN", 60, price))
+		r2 := engine2.Process(makeTFCandleSnap("SBIN", 60, price))
+		for j := range r1 {
+			if r1[j].Name != r2[j].Name || math.Abs(r1[j].Value-r2[j].Value) > 1e-10 {
+				t.Errorf("candle %d indicator %s: original=%.6f restored %s=%.6f",
+					i, r1[j].Name, r1[j].Value, r2[j].Name, r2[j].Value)
+			}
+		}
+		if i == 0 && r2[2].Ready {
+			t.Error("expected SMA_3@RSI_4 to start cold")
+		}
+	}
+}
+
+func TestSnapshot_Engine_MultiParam_RoundTrip(t *testing.T) {
+	macd, _ := ParseIndicatorConfig("MACD(5,10,4)")
+	bb, _ := ParseIndicatorConfig("BB(6,2.5)")
+	configs := []TFIndicatorConfig{{TF: 60, Indicators: []IndicatorConfig{macd, bb}}}
+
+	engine := NewEngine(configs)
+	for i := 0; i < 20; i++ {
+		engine.Process(makeTFCandleSnap("SBIN", 60, int64(10000+(i%5)*120)))
+	}
+	snap, err := SnapshotEngine(engine, "test-stream-id")
+	if err != nil {
+		t.Fatalf("snapshot failed: %v", err)
+	}
+	if p := snap.Tokens[0].Indicators[1].Params; p["mult"] != 2.5 || p["length"] != 6 {
+		t.Errorf("expected BB params in snapshot, got %v", p)
+	}
+
+	// A different multiplier is a different indicator and must not match
+	other, _ := ParseIndicatorConfig("BB(6,3)")
+	restoreConfigs := []TFIndicatorConfig{{TF: 60, Indicators: []IndicatorConfig{macd, bb, other}}}
+	engine2, err := RestoreEngine(restoreConfigs, snap)
+	if err != nil {
+		t.Fatalf("restore failed: %v", err)
+	}
+
+	price := int64(10900)
+	r1 := engine.Process(makeTFCandleSnap("SBIN", 60, price))
+	r2 := engine2.Process(makeTFCandleSnap("SBIN", 60, price))
+	for j := range r1 {
+		if math.Abs(r1[j].Value-r2[j].Value) > 1e-10 {
+			t.Errorf("indicator %s: original=%.6f restored=%.6f", r1[j].Name, r1[j].Value, r2[j].Value)
+		}
+	}
+	if r2[2].Ready {
+		t.Error("expected BB_6_3 to start cold")
+	}
+}
+
+func TestSnapshot_LegacyPeriodOnly_Restores(t *testing.T) {
+	configs := []TFIndicatorConfig{{TF: 60, Indicators: []IndicatorConfig{{Type: "SMA", Period: 3}}}}
+	engine := NewEngine(configs)
+	for i := 0; i < 3; i++ {
+		engine.Process(makeTFCandleSnap("SBIN", 60, int64(10000+i*100)))
+	}
+	snap, err := SnapshotEngine(engine, "test-stream-id")
+	if err != nil {
+		t.Fatalf("snapshot failed: %v", err)
+	}
+	// Snapshots written before named parameters carry only Period
+	snap.Tokens[0].Indicators[0].Params = nil
+
+	engine2, err := RestoreEngine(configs, snap)
+	if err != nil {
+		t.Fatalf("restore failed: %v", err)
+	}
+	r := engine2.Process(makeTFCandleSnap("SBIN", 60, 10300))
+	if !r[0].Ready || math.Abs(r[0].Value-10200) > 1e-9 {
+		t.Errorf("expected restored SMA_3=10200, got %+v", r[0])
+	}
+}
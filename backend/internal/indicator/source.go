@@ -0,0 +1,77 @@
+package indicator
+
+import (
+	"fmt"
+	"strings"
+
+	"trading-systemv1/internal/model"
+)
+
+// Source selects which candle price a price-based indicator consumes.
+// The zero value means close.
+type Source string
+
+const (
+	SourceClose Source = "close"
+	SourceOpen  Source = "open"
+	SourceHigh  Source = "high"
+	SourceLow   Source = "low"
+	SourceHL2   Source = "hl2"   // (high+low)/2
+	SourceHLC3  Source = "hlc3"  // (high+low+close)/3
+	SourceOHLC4 Source = "ohlc4" // (open+high+low+close)/4
+	SourceVol   Source = "volume"
+)
+
+// ParseSource parses a source name (case-insensitive). Empty means close.
+func ParseSource(s string) (Source, error) {
+	src := Source(strings.ToLower(strings.TrimSpace(s)))
+	switch src {
+	case "":
+		return SourceClose, nil
+	case SourceClose, SourceOpen, SourceHigh, SourceLow, SourceHL2, SourceHLC3, SourceOHLC4, SourceVol:
+		return src, nil
+	}
+	return "", fmt.Errorf("unknown price source %q", s)
+}
+
+// IsClose reports whether the source is the default close price.
+func (s Source) IsClose() bool { return s == "" || s == SourceClose }
+
+// Price returns the source value of a candle: a price in paise, or the
+// volume for SourceVol.
+func (s Source) Price(c model.Candle) float64 {
+	return s.price(c.Open, c.High, c.Low, c.Close, c.Volume)
+}
+
+// FormingPrice returns the source value of a forming TF candle.
+func (s Source) FormingPrice(tfc model.TFCandle) float64 {
+	return s.price(tfc.Open, tfc.High, tfc.Low, tfc.Close, tfc.Volume)
+}
+
+func (s Source) price(open, high, low, close, volume int64) float64 {
+	switch s {
+	case SourceVol:
+		return float64(volume)
+	case SourceOpen:
+		return float64(open)
+	case SourceHigh:
+		return float64(high)
+	case SourceLow:
+		return float64(low)
+	case SourceHL2:
+		return float64(high+low) / 2.0
+	case SourceHLC3:
+		return float64(high+low+close) / 3.0
+	case SourceOHLC4:
+		return float64(open+high+low+close) / 4.0
+	default:
+		return float64(close)
+	}
+}
+
+// sourced is implemented by indicators whose input price is selectable.
+// Range- and volume-based indicators (ATR, STOCH, VWAP, ...) read the full
+// candle and do not implement it.
+type sourced interface {
+	setSource(src Source)
+}
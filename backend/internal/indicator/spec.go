@@ -0,0 +1,312 @@
+package indicator
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"trading-systemv1/internal/model"
+)
+
+// Params holds named indicator parameters (e.g. {"length": 20, "mult": 2.5}).
+// Int parameters are stored as whole floats; the registered ParamSpec gives
+// each parameter its kind, default and bounds.
+type Params map[string]float64
+
+// values returns the config's parameters in schema order. A parameter comes
+// from Params, then — for the first parameter only — from the legacy Period
+// field, and finally from the schema default.
+func (c IndicatorConfig) values(r *Registration) []float64 {
+	out := make([]float64, len(r.Params))
+	for i, p := range r.Params {
+		v, ok := c.Params[p.Name]
+		switch {
+		case ok:
+		case i == 0 && c.Period != 0:
+			v = float64(c.Period)
+		default:
+			v = p.Default
+		}
+		out[i] = v
+	}
+	return out
+}
+
+// Float returns the named parameter, falling back to Period (first
+// parameter) and then to the registered default. Unknown names return 0.
+func (c IndicatorConfig) Float(name string) float64 {
+	r, ok := Lookup(c.Type)
+	if !ok {
+		return c.Params[name]
+	}
+	vals := c.values(r)
+	for i, p := range r.Params {
+		if p.Name == name {
+			return vals[i]
+		}
+	}
+	return 0
+}
+
+// Int returns the named parameter as an int (see Float).
+func (c IndicatorConfig) Int(name string) int {
+	return int(math.Round(c.Float(name)))
+}
+
+// Name returns the result name for this config: the type followed by every
+// parameter in schema order, e.g. "SMA_20", "BB_20_2.5", "MACD_12_26_9".
+// A non-close source adds a "_source" suffix (e.g. "EMA_21_hl2"), so the
+// same indicator on different sources can live side by side. Chains append
+// "@" and the inner name: EMA(9) of RSI(14) is "EMA_9@RSI_14".
+func (c IndicatorConfig) Name() string {
+	name := c.Type
+	if r, ok := Lookup(c.Type); ok {
+		for i, v := range c.values(r) {
+			name += "_" + r.Params[i].format(v)
+		}
+	} else {
+		name += "_" + model.Itoa(c.Period)
+	}
+	if !c.Source.IsClose() {
+		name += "_" + string(c.Source)
+	}
+	if c.Input != nil {
+		name += "@" + c.Input.Name()
+	}
+	return name
+}
+
+// Overlay reports whether the config's values are on the price scale.
+// A chain takes the scale of its innermost input, so "EMA_9@RSI_14" is an
+// oscillator, and a volume source is never on the price scale.
+func (c IndicatorConfig) Overlay() bool {
+	for c.Input != nil {
+		c = *c.Input
+	}
+	r, ok := Lookup(c.Type)
+	return ok && r.Overlay && c.Source != SourceVol
+}
+
+// Spec returns the canonical config-string form accepted by
+// ParseIndicatorConfig: "SMA:20" for single-parameter types,
+// "MACD(12,26,9)" otherwise, plus ":source" and "@inner" as needed.
+func (c IndicatorConfig) Spec() string {
+	var spec string
+	r, ok := Lookup(c.Type)
+	switch {
+	case !ok:
+		spec = c.Type + ":" + model.Itoa(c.Period)
+	case len(r.Params) == 1:
+		spec = c.Type + ":" + r.Params[0].format(c.values(r)[0])
+	default:
+		vals := c.values(r)
+		parts := make([]string, len(vals))
+		for i, v := range vals {
+			parts[i] = r.Params[i].format(v)
+		}
+		spec = c.Type + "(" + strings.Join(parts, ",") + ")"
+	}
+	if !c.Source.IsClose() {
+		spec += ":" + string(c.Source)
+	}
+	if c.Input != nil {
+		spec += "@" + c.Input.Spec()
+	}
+	return spec
+}
+
+// paramMap returns every parameter by name, defaults filled in.
+func (c IndicatorConfig) paramMap() Params {
+	r, ok := Lookup(c.Type)
+	if !ok {
+		return nil
+	}
+	out := make(Params, len(r.Params))
+	for i, v := range c.values(r) {
+		out[r.Params[i].Name] = v
+	}
+	return out
+}
+
+// format renders a parameter value for names and specs: numbers without
+// trailing zeros (20, 2.5, 0.02), enum values by name ("week").
+func (p ParamSpec) format(v float64) string {
+	if i := int(v - p.Min); p.Kind == ParamEnum && v == math.Trunc(v) && i >= 0 && i < len(p.Values) {
+		return p.Values[i]
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// parse is the inverse of format. Enum parameters also accept their number.
+func (p ParamSpec) parse(arg string) (float64, error) {
+	arg = strings.TrimSpace(arg)
+	if p.Kind == ParamEnum {
+		for i, name := range p.Values {
+			if strings.EqualFold(arg, name) {
+				return p.Min + float64(i), nil
+			}
+		}
+	}
+	v, err := strconv.ParseFloat(arg, 64)
+	if err != nil {
+		if p.Kind == ParamEnum {
+			return 0, fmt.Errorf("%s: %q is not one of %s", p.Name, arg, strings.Join(p.Values, ", "))
+		}
+		return 0, fmt.Errorf("%s: %q is not a number", p.Name, arg)
+	}
+	if p.Kind != ParamFloat && v != math.Trunc(v) {
+		return 0, fmt.Errorf("%s must be an integer, got %s", p.Name, arg)
+	}
+	return v, nil
+}
+
+// ParseIndicatorConfig parses a single indicator spec. Two forms are accepted:
+//
+//	TYPE:PERIOD[:SOURCE]        e.g. "SMA:20", "EMA:21:hl2"
+//	TYPE(P1,P2,...)[:SOURCE]    e.g. "MACD(12,26,9)", "BB(20,2.5)"
+//
+// Positional parameters follow the registered schema order; omitted trailing
+// parameters take their defaults, so "BB:20" is BB(20,2). Specs chain with
+// "@", outermost first: "EMA:9@RSI:14" is EMA(9) of RSI(14), and
+// "SMA:20:volume" is SMA(20) of volume. Unknown types and malformed values
+// are rejected; use ValidateConfigs for range checks.
+func ParseIndicatorConfig(spec string) (IndicatorConfig, error) {
+	head, rest, chained := strings.Cut(strings.TrimSpace(spec), "@")
+
+	var typ, args, src string
+	if open := strings.IndexByte(head, '('); open >= 0 {
+		close := strings.IndexByte(head, ')')
+		if close < open {
+			return IndicatorConfig{}, fmt.Errorf("invalid indicator spec %q: unbalanced parentheses", spec)
+		}
+		typ, args = head[:open], head[open+1:close]
+		if tail := head[close+1:]; tail != "" {
+			var ok bool
+			if src, ok = strings.CutPrefix(tail, ":"); !ok {
+				return IndicatorConfig{}, fmt.Errorf("invalid indicator spec %q", spec)
+			}
+		}
+	} else {
+		tokens := strings.SplitN(head, ":", 3)
+		if len(tokens) < 2 {
+			return IndicatorConfig{}, fmt.Errorf("invalid indicator spec %q: want TYPE:PERIOD or TYPE(P1,P2,...)", spec)
+		}
+		typ, args = tokens[0], tokens[1]
+		if len(tokens) == 3 {
+			src = tokens[2]
+		}
+	}
+
+	typ = strings.ToUpper(strings.TrimSpace(typ))
+	r, err := lookupConfig(typ)
+	if err != nil {
+		return IndicatorConfig{}, err
+	}
+	cfg := IndicatorConfig{Type: typ}
+	if cfg.Params, err = parseParams(r, strings.Split(args, ",")); err != nil {
+		return IndicatorConfig{}, fmt.Errorf("invalid indicator spec %q: %v", spec, err)
+	}
+	if src != "" {
+		if cfg.Source, err = ParseSource(src); err != nil {
+			return IndicatorConfig{}, err
+		}
+	}
+
+	if chained {
+		in, err := ParseIndicatorConfig(rest)
+		if err != nil {
+			return IndicatorConfig{}, err
+		}
+		cfg.Input = &in
+	}
+	return cfg, nil
+}
+
+// parseParams maps positional values onto the registered schema.
+func parseParams(r *Registration, args []string) (Params, error) {
+	if len(args) > len(r.Params) {
+		return nil, fmt.Errorf("%s takes at most %d parameters, got %d", r.Type, len(r.Params), len(args))
+	}
+	params := make(Params, len(args))
+	for i, arg := range args {
+		p := r.Params[i]
+		v, err := p.parse(arg)
+		if err != nil {
+			return nil, err
+		}
+		params[p.Name] = v
+	}
+	return params, nil
+}
+
+// ParseIndicatorName is the inverse of IndicatorConfig.Name: it parses a
+// result name like "BB_20_2.5", "EMA_21_hl2", "VWAP_week" or "EMA_9@RSI_14"
+// back into a config.
+func ParseIndicatorName(name string) (IndicatorConfig, error) {
+	head, rest, chained := strings.Cut(name, "@")
+
+	tokens := strings.Split(head, "_")
+	r, err := lookupConfig(tokens[0])
+	if err != nil {
+		return IndicatorConfig{}, err
+	}
+	args := tokens[1:]
+	cfg := IndicatorConfig{Type: r.Type}
+	if n := len(args); n > 0 {
+		// A trailing word is the source, unless it names an enum value.
+		enum := n <= len(r.Params) && r.Params[n-1].Kind == ParamEnum
+		if _, err := strconv.ParseFloat(args[n-1], 64); err != nil && !enum {
+			if cfg.Source, err = ParseSource(args[n-1]); err != nil {
+				return IndicatorConfig{}, err
+			}
+			args = args[:n-1]
+		}
+	}
+	if len(args) == 0 {
+		return IndicatorConfig{}, fmt.Errorf("invalid indicator name %q: missing parameters", name)
+	}
+	if cfg.Params, err = parseParams(r, args); err != nil {
+		return IndicatorConfig{}, fmt.Errorf("invalid indicator name %q: %v", name, err)
+	}
+
+	if chained {
+		in, err := ParseIndicatorName(rest)
+		if err != nil {
+			return IndicatorConfig{}, err
+		}
+		cfg.Input = &in
+	}
+	return cfg, nil
+}
+
+// SplitIndicatorSpecs splits a comma-separated spec list, keeping commas
+// inside parentheses: "SMA:20,MACD(12,26,9)" → ["SMA:20", "MACD(12,26,9)"].
+// Empty entries are dropped.
+func SplitIndicatorSpecs(s string) []string {
+	var out []string
+	depth, start := 0, 0
+	for i := 0; i <= len(s); i++ {
+		if i < len(s) {
+			switch s[i] {
+			case '(':
+				depth++
+				continue
+			case ')':
+				depth--
+				continue
+			case ',':
+				if depth > 0 {
+					continue
+				}
+			default:
+				continue
+			}
+		}
+		if part := strings.TrimSpace(s[start:i]); part != "" {
+			out = append(out, part)
+		}
+		start = i + 1
+	}
+	return out
+}
@@ -0,0 +1,98 @@
+package indicator
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"trading-systemv1/internal/model"
+)
+
+func TestParseIndicatorConfig_MultiParam(t *testing.T) {
+	cases := []struct {
+		spec  string
+		name  string
+		canon string
+	}{
+		{"SMA:20", "SMA_20", "SMA:20"},
+		{"macd(12,26,9)", "MACD_12_26_9", "MACD(12,26,9)"},
+		{"MACD(8,21)", "MACD_8_21_9", "MACD(8,21,9)"},
+		{"BB:20", "BB_20_2", "BB(20,2)"},
+		{"BB(20, 2.5):hlc3", "BB_20_2.5_hlc3", "BB(20,2.5):hlc3"},
+		{"STOCH(14,3,3)", "STOCH_14_3_3", "STOCH(14,3,3)"},
+		{"SUPERTREND(10,3.0)", "SUPERTREND_10_3", "SUPERTREND(10,3)"},
+		{"PSAR(0.02,0.2)", "PSAR_0.02_0.2", "PSAR(0.02,0.2)"},
+		{"BB(20,2.5)@RSI:14", "BB_20_2.5@RSI_14", "BB(20,2.5)@RSI:14"},
+		{"VWAP:session", "VWAP_session", "VWAP:session"},
+		{"vwap:Week", "VWAP_week", "VWAP:week"},
+		{"VWAP:3", "VWAP_month", "VWAP:month"},
+	}
+	for _, tc := range cases {
+		cfg, err := ParseIndicatorConfig(tc.spec)
+		if err != nil {
+			t.Errorf("%s: %v", tc.spec, err)
+			continue
+		}
+		if cfg.Name() != tc.name {
+			t.Errorf("%s: Name()=%s, want %s", tc.spec, cfg.Name(), tc.name)
+		}
+		if cfg.Spec() != tc.canon {
+			t.Errorf("%s: Spec()=%s, want %s", tc.spec, cfg.Spec(), tc.canon)
+		}
+		back, err := ParseIndicatorName(cfg.Name())
+		if err != nil || back.Name() != cfg.Name() {
+			t.Errorf("%s: ParseIndicatorName(%s) = %s, %v", tc.spec, cfg.Name(), back.Name(), err)
+		}
+	}
+
+	for _, bad := range []string{"MACD(12.5,26,9)", "BB(20,2,1)", "BB(20,x)", "BB(20,2", "VWAP:year"} {
+		if _, err := ParseIndicatorConfig(bad); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestIndicatorConfig_ParamAccessors(t *testing.T) {
+	legacy := IndicatorConfig{Type: "BB", Period: 10}
+	if legacy.Int("length") != 10 || legacy.Float("mult") != 2.0 {
+		t.Errorf("expected BB(10,2) from Period, got length=%d mult=%g", legacy.Int("length"), legacy.Float("mult"))
+	}
+	named := IndicatorConfig{Type: "BB", Period: 10, Params: Params{"length": 30, "mult": 1.5}}
+	if named.Int("length") != 30 || named.Float("mult") != 1.5 {
+		t.Errorf("expected Params to override Period, got length=%d mult=%g", named.Int("length"), named.Float("mult"))
+	}
+	if err := ValidateIndicator(IndicatorConfig{Type: "BB", Params: Params{"width": 2}}); err == nil {
+		t.Error("expected unknown parameter to be rejected")
+	}
+	if err := ValidateIndicator(IndicatorConfig{Type: "MACD", Params: Params{"slow": 26.5}}); err == nil {
+		t.Error("expected fractional int parameter to be rejected")
+	}
+}
+
+func TestSplitIndicatorSpecs(t *testing.T) {
+	got := SplitIndicatorSpecs(" SMA:20, MACD(12,26,9),,BB(20,2.5):hl2@RSI:14 ")
+	want := []string{"SMA:20", "MACD(12,26,9)", "BB(20,2.5):hl2@RSI:14"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestEngine_MultiParamSideBySide(t *testing.T) {
+	narrow, _ := ParseIndicatorConfig("BB(5,1)")
+	wide, _ := ParseIndicatorConfig("BB(5,3)")
+	engine := NewEngine([]TFIndicatorConfig{{TF: 60, Indicators: []IndicatorConfig{narrow, wide}}})
+
+	var results []model.IndicatorResult
+	for i := 0; i < 6; i++ {
+		results = engine.Process(makeTFCandle("MP", 60, int64(10000+i*100)))
+	}
+	if results[0].Name != "BB_5_1" || results[1].Name != "BB_5_3" {
+		t.Fatalf("unexpected names %s, %s", results[0].Name, results[1].Name)
+	}
+	nu, _ := results[0].Components.Get("upper")
+	wu, _ := results[1].Components.Get("upper")
+	mid := results[0].Value
+	if math.Abs((wu-mid)-3*(nu-mid)) > 1e-6 {
+		t.Errorf("expected the 3x band to be three times as wide: narrow=%.4f wide=%.4f mid=%.4f", nu, wu, mid)
+	}
+}
@@ -0,0 +1,170 @@
+package indicator
+
+import (
+	"fmt"
+
+	"trading-systemv1/internal/model"
+)
+
+// Stochastic calculates the Stochastic Oscillator.
+// raw %K = 100 * (close - lowestLow) / (highestHigh - lowestLow) over period,
+// %K = SMA(smoothK) of raw %K, %D = SMA(dPeriod) of %K.
+// Uses preallocated circular buffers for the high/low window.
+type Stochastic struct {
+	period  int
+	highs   []float64 // circular buffer of candle highs
+	lows    []float64 // circular buffer of candle lows
+	idx     int       // current write position
+	count   int       // total candles received
+	smoothK *SMA
+	d       *SMA
+	k       float64
+}
+
+var stochasticComponents = []string{"k", "d"}
+
+// NewStochastic creates a new Stochastic oscillator (typically 14, 3, 3).
+func NewStochastic(period, smoothK, dPeriod int) *Stochastic {
+	return &Stochastic{
+		period:  period,
+		highs:   make([]float64, period),
+		lows:    make([]float64, period),
+		smoothK: NewSMA(smoothK),
+		d:       NewSMA(dPeriod),
+	}
+}
+
+func init() {
+	Register(Registration{
+		Type: "STOCH",
+		Params: []ParamSpec{
+			{Name: "length", Kind: ParamInt, Default: 14, Min: 1},
+			{Name: "smooth_k", Kind: ParamInt, Default: 3, Min: 1},
+			{Name: "smooth_d", Kind: ParamInt, Default: 3, Min: 1},
+		},
+		New: func(cfg IndicatorConfig) Indicator {
+			return NewStochastic(cfg.Int("length"), cfg.Int("smooth_k"), cfg.Int("smooth_d"))
+		},
+		WarmUp: func(cfg IndicatorConfig) int {
+			return cfg.Int("length") + cfg.Int("smooth_k") + cfg.Int("smooth_d") - 2
+		},
+	})
+}
+
+func (s *Stochastic) Name() string { return "STOCH" }
+
+func (s *Stochastic) Update(candle model.Candle) {
+	s.highs[s.idx] = float64(candle.High)
+	s.lows[s.idx] = float64(candle.Low)
+	s.idx = (s.idx + 1) % s.period
+	s.count++
+
+	if s.count < s.period {
+		return
+	}
+	hh, ll := s.highs[0], s.lows[0]
+	for i := 1; i < s.period; i++ {
+		if s.highs[i] > hh {
+			hh = s.highs[i]
+		}
+		if s.lows[i] < ll {
+			ll = s.lows[i]
+		}
+	}
+	s.smoothK.updatePrice(rawStochK(float64(candle.Close), hh, ll))
+	if !s.smoothK.Ready() {
+		return
+	}
+	s.k = s.smoothK.Value()
+	s.d.updatePrice(s.k)
+}
+
+func (s *Stochastic) Value() float64 { return s.k }
+func (s *Stochastic) Ready() bool    { return s.d.Ready() }
+
+// Components returns the Stochastic output names: k, d.
+func (s *Stochastic) Components() []string { return stochasticComponents }
+
+// Values returns the current %K and %D.
+func (s *Stochastic) Values() []float64 {
+	return []float64{s.k, s.d.Value()}
+}
+
+// Peek computes what %K would be with an additional candle without mutating state.
+func (s *Stochastic) Peek(forming model.TFCandle) float64 {
+	return s.PeekValues(forming)[0]
+}
+
+// PeekValues computes %K and %D with an additional candle without mutating state.
+// The forming candle's high and low take part in the lookback range.
+func (s *Stochastic) PeekValues(forming model.TFCandle) []float64 {
+	if s.count+1 < s.period {
+		return s.Values()
+	}
+	price := float64(forming.Close)
+	hh, ll := float64(forming.High), float64(forming.Low)
+	for i := 0; i < s.period; i++ {
+		if i == s.idx || i >= s.count {
+			continue // slot being replaced, or not yet filled
+		}
+		if s.highs[i] > hh {
+			hh = s.highs[i]
+		}
+		if s.lows[i] < ll {
+			ll = s.lows[i]
+		}
+	}
+	k := s.smoothK.peekPrice(rawStochK(price, hh, ll))
+	if !s.d.Ready() {
+		return []float64{k, s.d.Value()}
+	}
+	return []float64{k, s.d.peekPrice(k)}
+}
+
+// rawStochK returns the unsmoothed %K. A flat range yields the midpoint 50.
+func rawStochK(close, hh, ll float64) float64 {
+	if hh == ll {
+		return 50.0
+	}
+	return 100.0 * (close - ll) / (hh - ll)
+}
+
+// Snapshot serializes the Stochastic state for checkpoint persistence.
+func (s *Stochastic) Snapshot() IndicatorSnapshot {
+	highs := make([]float64, len(s.highs))
+	copy(highs, s.highs)
+	lows := make([]float64, len(s.lows))
+	copy(lows, s.lows)
+	return IndicatorSnapshot{
+		Type:     "STOCH",
+		Period:   s.period,
+		Highs:    highs,
+		Lows:     lows,
+		Idx:      s.idx,
+		Count:    s.count,
+		Current:  s.k,
+		Children: []IndicatorSnapshot{s.smoothK.Snapshot(), s.d.Snapshot()},
+	}
+}
+
+// RestoreFromSnapshot restores Stochastic state from a checkpoint.
+func (s *Stochastic) RestoreFromSnapshot(snap IndicatorSnapshot) error {
+	if len(snap.Children) != 2 {
+		return fmt.Errorf("STOCH snapshot: expected 2 children, got %d", len(snap.Children))
+	}
+	if err := s.smoothK.RestoreFromSnapshot(snap.Children[0]); err != nil {
+		return err
+	}
+	if err := s.d.RestoreFromSnapshot(snap.Children[1]); err != nil {
+		return err
+	}
+	s.period = snap.Period
+	s.idx = snap.Idx
+	s.count = snap.Count
+	s.k = snap.Current
+	s.highs = make([]float64, snap.Period)
+	s.lows = make([]float64, snap.Period)
+	copy(s.highs, snap.Highs)
+	copy(s.lows, snap.Lows)
+	return nil
+}
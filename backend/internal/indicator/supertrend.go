@@ -0,0 +1,149 @@
+package indicator
+
+import (
+	"fmt"
+
+	"trading-systemv1/internal/model"
+)
+
+// SuperTrend calculates the ATR-based SuperTrend trailing stop.
+// Bands are hl2 ± mult*ATR, ratcheted so the active band only moves in the
+// trend direction; the trend flips when the close crosses the active band.
+// Starts in a downtrend (upper band active), matching common charting tools.
+// O(1) per update.
+type SuperTrend struct {
+	mult      float64
+	atr       *ATR
+	count     int     // candles with a computed SuperTrend value
+	upper     float64 // final upper band
+	lower     float64 // final lower band
+	direction int     // +1 up (lower band active), -1 down (upper band active)
+}
+
+var superTrendComponents = []string{"supertrend", "direction"}
+
+// NewSuperTrend creates a new SuperTrend indicator (typically 10, 3.0).
+func NewSuperTrend(period int, mult float64) *SuperTrend {
+	return &SuperTrend{mult: mult, atr: NewATR(period)}
+}
+
+func init() {
+	Register(Registration{
+		Type:    "SUPERTREND",
+		Overlay: true,
+		Params: []ParamSpec{
+			{Name: "length", Kind: ParamInt, Default: 10, Min: 1},
+			{Name: "mult", Kind: ParamFloat, Default: 3.0, Min: 0.1, Max: 20},
+		},
+		New: func(cfg IndicatorConfig) Indicator { return NewSuperTrend(cfg.Int("length"), cfg.Float("mult")) },
+	})
+}
+
+func (s *SuperTrend) Name() string { return "SUPERTREND" }
+
+func (s *SuperTrend) Update(candle model.Candle) {
+	high, low, close := float64(candle.High), float64(candle.Low), float64(candle.Close)
+	prevClose := s.atr.prevClose
+	s.atr.updateHLC(high, low, close)
+	if !s.atr.Ready() {
+		return
+	}
+	s.upper, s.lower, s.direction = s.step(high, low, close, prevClose, s.atr.Value())
+	s.count++
+}
+
+// step computes the next final bands and direction without mutating state.
+func (s *SuperTrend) step(high, low, close, prevClose, atr float64) (upper, lower float64, direction int) {
+	hl2 := (high + low) / 2
+	upper = hl2 + s.mult*atr
+	lower = hl2 - s.mult*atr
+	if s.count == 0 {
+		return upper, lower, -1
+	}
+
+	if !(upper < s.upper || prevClose > s.upper) {
+		upper = s.upper
+	}
+	if !(lower > s.lower || prevClose < s.lower) {
+		lower = s.lower
+	}
+
+	direction = s.direction
+	if s.direction < 0 && close > upper {
+		direction = 1
+	} else if s.direction > 0 && close < lower {
+		direction = -1
+	}
+	return upper, lower, direction
+}
+
+// superTrendLine returns the active band for a direction.
+func superTrendLine(upper, lower float64, direction int) float64 {
+	if direction > 0 {
+		return lower
+	}
+	return upper
+}
+
+func (s *SuperTrend) Value() float64 {
+	if s.count == 0 {
+		return 0
+	}
+	return superTrendLine(s.upper, s.lower, s.direction)
+}
+
+func (s *SuperTrend) Ready() bool { return s.count > 0 }
+
+// Components returns the SuperTrend output names: supertrend, direction.
+func (s *SuperTrend) Components() []string { return superTrendComponents }
+
+// Values returns the current SuperTrend line and direction (+1 up, -1 down).
+func (s *SuperTrend) Values() []float64 {
+	return []float64{s.Value(), float64(s.direction)}
+}
+
+// Peek computes what the SuperTrend line would be with the forming candle without mutating state.
+func (s *SuperTrend) Peek(forming model.TFCandle) float64 {
+	return s.PeekValues(forming)[0]
+}
+
+// PeekValues computes the SuperTrend line and direction with the forming candle without mutating state.
+func (s *SuperTrend) PeekValues(forming model.TFCandle) []float64 {
+	if s.atr.smma.count+1 < s.atr.period {
+		return s.Values()
+	}
+	high, low, close := float64(forming.High), float64(forming.Low), float64(forming.Close)
+	upper, lower, direction := s.step(high, low, close, s.atr.prevClose, s.atr.peekHL(high, low))
+	return []float64{superTrendLine(upper, lower, direction), float64(direction)}
+}
+
+// Snapshot serializes the SuperTrend state for checkpoint persistence.
+func (s *SuperTrend) Snapshot() IndicatorSnapshot {
+	return IndicatorSnapshot{
+		Type:       "SUPERTREND",
+		Period:     s.atr.period,
+		Multiplier: s.mult,
+		Count:      s.count,
+		Upper:      s.upper,
+		Lower:      s.lower,
+		Direction:  s.direction,
+		Current:    s.Value(),
+		Children:   []IndicatorSnapshot{s.atr.Snapshot()},
+	}
+}
+
+// RestoreFromSnapshot restores SuperTrend state from a checkpoint.
+func (s *SuperTrend) RestoreFromSnapshot(snap IndicatorSnapshot) error {
+	if len(snap.Children) != 1 {
+		return fmt.Errorf("SUPERTREND snapshot: expected 1 child, got %d", len(snap.Children))
+	}
+	if err := s.atr.RestoreFromSnapshot(snap.Children[0]); err != nil {
+		return err
+	}
+	s.mult = snap.Multiplier
+	s.count = snap.Count
+	s.upper = snap.Upper
+	s.lower = snap.Lower
+	s.direction = snap.Direction
+	return nil
+}
@@ -0,0 +1,179 @@
+package indicator
+
+import (
+	"math"
+	"time"
+
+	"trading-systemv1/internal/markethours"
+	"trading-systemv1/internal/model"
+)
+
+// VWAP anchor periods, selected by the "anchor" parameter by name
+// (VWAP:session, VWAP:week, VWAP:month). Result names carry the name too:
+// "VWAP_week".
+const (
+	VWAPAnchorSession = 1 // reset at each 9:15 IST session open
+	VWAPAnchorWeek    = 2 // reset at the first session of each IST week
+	VWAPAnchorMonth   = 3 // reset at the first session of each IST month
+)
+
+// VWAP calculates the anchored Volume-Weighted Average Price of the typical
+// price (H+L+C)/3, with ±1σ and ±2σ volume-weighted standard deviation bands.
+// Accumulators reset whenever a candle falls into a new anchor period as
+// given by markethours. Instruments that report no volume (e.g. indices)
+// fall back to an equal-weighted average over the anchor period.
+// O(1) per update.
+type VWAP struct {
+	anchor   int
+	anchorTS int64 // unix seconds of the current anchor open
+
+	// Volume-weighted accumulators
+	cumPV  float64
+	cumPV2 float64
+	cumVol float64
+
+	// Equal-weighted fallback accumulators
+	count int
+	sum   float64
+	sumSq float64
+}
+
+var vwapComponents = []string{"vwap", "upper1", "lower1", "upper2", "lower2"}
+
+// NewVWAP creates a new VWAP indicator anchored to the session, week or month.
+// Unknown anchors fall back to the session anchor.
+func NewVWAP(anchor int) *VWAP {
+	if anchor != VWAPAnchorWeek && anchor != VWAPAnchorMonth {
+		anchor = VWAPAnchorSession
+	}
+	return &VWAP{anchor: anchor}
+}
+
+func init() {
+	Register(Registration{
+		Type:    "VWAP",
+		Overlay: true,
+		Params: []ParamSpec{{
+			Name: "anchor", Kind: ParamEnum, Default: VWAPAnchorSession,
+			Min: VWAPAnchorSession, Max: VWAPAnchorMonth,
+			Values: []string{"session", "week", "month"},
+		}},
+		New: func(cfg IndicatorConfig) Indicator { return NewVWAP(cfg.Int("anchor")) },
+	})
+}
+
+func (v *VWAP) Name() string { return "VWAP" }
+
+// anchorOpen returns the unix open time of the anchor period containing ts.
+func (v *VWAP) anchorOpen(ts time.Time) int64 {
+	switch v.anchor {
+	case VWAPAnchorWeek:
+		return markethours.WeekOpen(ts).Unix()
+	case VWAPAnchorMonth:
+		return markethours.MonthOpen(ts).Unix()
+	default:
+		return markethours.SessionOpen(ts).Unix()
+	}
+}
+
+func (v *VWAP) Update(candle model.Candle) {
+	if a := v.anchorOpen(candle.TS); a != v.anchorTS {
+		*v = VWAP{anchor: v.anchor, anchorTS: a}
+	}
+	tp := typicalPrice(candle.High, candle.Low, candle.Close)
+	vol := float64(candle.Volume)
+	if vol > 0 {
+		v.cumPV += tp * vol
+		v.cumPV2 += tp * tp * vol
+		v.cumVol += vol
+	}
+	v.count++
+	v.sum += tp
+	v.sumSq += tp * tp
+}
+
+// typicalPrice returns (high+low+close)/3.
+func typicalPrice(high, low, close int64) float64 {
+	return float64(high+low+close) / 3.0
+}
+
+// vwapBands computes VWAP and its σ bands from weighted sums.
+func vwapBands(sum, sumSq, weight float64) []float64 {
+	if weight == 0 {
+		return []float64{0, 0, 0, 0, 0}
+	}
+	mean := sum / weight
+	sd := math.Sqrt(math.Max(0, sumSq/weight-mean*mean))
+	return []float64{mean, mean + sd, mean - sd, mean + 2*sd, mean - 2*sd}
+}
+
+// bands returns the current components from whichever accumulators apply.
+func (v *VWAP) bands(cumPV, cumPV2, cumVol, sum, sumSq float64, count int) []float64 {
+	if cumVol > 0 {
+		return vwapBands(cumPV, cumPV2, cumVol)
+	}
+	return vwapBands(sum, sumSq, float64(count))
+}
+
+func (v *VWAP) Value() float64 { return v.Values()[0] }
+func (v *VWAP) Ready() bool    { return v.count > 0 }
+
+// Components returns the VWAP output names: vwap, upper1, lower1, upper2, lower2.
+func (v *VWAP) Components() []string { return vwapComponents }
+
+// Values returns the current VWAP and its ±1σ/±2σ bands.
+func (v *VWAP) Values() []float64 {
+	return v.bands(v.cumPV, v.cumPV2, v.cumVol, v.sum, v.sumSq, v.count)
+}
+
+// Peek computes what VWAP would be with the forming candle without mutating state.
+func (v *VWAP) Peek(forming model.TFCandle) float64 {
+	return v.PeekValues(forming)[0]
+}
+
+// PeekValues computes VWAP and its bands with the forming candle without mutating state.
+// A forming candle in a new anchor period previews a fresh VWAP from that candle alone.
+func (v *VWAP) PeekValues(forming model.TFCandle) []float64 {
+	cumPV, cumPV2, cumVol := v.cumPV, v.cumPV2, v.cumVol
+	sum, sumSq, count := v.sum, v.sumSq, v.count
+	if v.anchorOpen(forming.TS) != v.anchorTS {
+		cumPV, cumPV2, cumVol, sum, sumSq, count = 0, 0, 0, 0, 0, 0
+	}
+	tp := typicalPrice(forming.High, forming.Low, forming.Close)
+	if vol := float64(forming.Volume); vol > 0 {
+		cumPV += tp * vol
+		cumPV2 += tp * tp * vol
+		cumVol += vol
+	}
+	return v.bands(cumPV, cumPV2, cumVol, sum+tp, sumSq+tp*tp, count+1)
+}
+
+// Snapshot serializes the VWAP state for checkpoint persistence.
+func (v *VWAP) Snapshot() IndicatorSnapshot {
+	return IndicatorSnapshot{
+		Type:     "VWAP",
+		Period:   v.anchor,
+		AnchorTS: v.anchorTS,
+		CumPV:    v.cumPV,
+		CumPV2:   v.cumPV2,
+		CumVol:   v.cumVol,
+		Count:    v.count,
+		Sum:      v.sum,
+		SumSq:    v.sumSq,
+		Current:  v.Value(),
+	}
+}
+
+// RestoreFromSnapshot restores VWAP state from a checkpoint.
+// A snapshot from an earlier anchor period is harmless: the next candle resets it.
+func (v *VWAP) RestoreFromSnapshot(snap IndicatorSnapshot) error {
+	v.anchor = snap.Period
+	v.anchorTS = snap.AnchorTS
+	v.cumPV = snap.CumPV
+	v.cumPV2 = snap.CumPV2
+	v.cumVol = snap.CumVol
+	v.count = snap.Count
+	v.sum = snap.Sum
+	v.sumSq = snap.SumSq
+	return nil
+}
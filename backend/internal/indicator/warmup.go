@@ -0,0 +1,124 @@
+package indicator
+
+import (
+	"sort"
+	"time"
+
+	"trading-systemv1/internal/model"
+)
+
+// warmKey identifies one token's state on one TF.
+type warmKey struct {
+	tf  int
+	key string
+}
+
+// warmState tracks the cold instances ReloadConfigs created for a token.
+// Warm-up feeds history into these instances only; the instances that were
+// preserved have already seen it.
+type warmState struct {
+	ti     *tokenIndicators
+	fresh  []int     // positions in ti of the cold instances
+	lastTS time.Time // newest candle fed so far, to skip overlapping sources
+
+	// live holds the finalized candles Process saw while the history was
+	// being read; FinishWarmUp feeds them to the cold instances after it.
+	live []model.TFCandle
+}
+
+// markWarming records the cold instances of a token after a reload.
+func (e *Engine) markWarming(tf int, key string, ti *tokenIndicators, fresh []int) {
+	if len(fresh) == 0 {
+		return
+	}
+	if e.warming == nil {
+		e.warming = make(map[warmKey]*warmState)
+	}
+	e.warming[warmKey{tf, key}] = &warmState{ti: ti, fresh: fresh}
+}
+
+// WarmingUp reports whether the last reload left cold instances that are
+// waiting for history (see WarmUp).
+func (e *Engine) WarmingUp() bool {
+	return len(e.warming) > 0
+}
+
+// WarmUp feeds a historical candle into the instances the last
+// ReloadConfigs created cold, leaving every other instance untouched, and
+// returns their results. Candles must arrive oldest first per token;
+// candles at or before the last one fed are skipped, so several sources
+// (SQLite, then Redis) can be replayed back to back.
+func (e *Engine) WarmUp(tfc model.TFCandle) []model.IndicatorResult {
+	ws, ok := e.warming[warmKey{tfc.TF, tfc.Key()}]
+	if !ok || tfc.Forming || !tfc.TS.After(ws.lastTS) {
+		return nil
+	}
+	ws.lastTS = tfc.TS
+
+	candle := candleOf(tfc)
+	results := make([]model.IndicatorResult, 0, len(ws.fresh))
+	for _, i := range ws.fresh {
+		ind := ws.ti.indicators[i]
+		ind.Update(candle)
+		results = append(results, newResult(ws.ti.configs[i], ind, tfc))
+	}
+	return results
+}
+
+// FinishWarmUp ends the warm-up started by the last ReloadConfigs: it feeds
+// the cold instances the live candles Process held back from them (those
+// newer than the history fed) and returns their results.
+func (e *Engine) FinishWarmUp() []model.IndicatorResult {
+	var results []model.IndicatorResult
+	for _, ws := range e.warming {
+		live := ws.live
+		ws.live = nil
+		for _, tfc := range live {
+			results = append(results, e.WarmUp(tfc)...)
+		}
+	}
+	e.warming = nil
+	if e.amends != nil {
+		e.amends = make(map[amendKey][]amendEntry) // logged states predate the warm-up
+	}
+	return results
+}
+
+// WarmUpTarget is one token on one TF with instances waiting for history.
+type WarmUpTarget struct {
+	TF   int
+	Key  string // "exchange:token"
+	Bars int    // most recent candles to feed: the slowest warm-up plus the ind:* stream depth
+}
+
+// WarmUpTargets lists the tokens the last ReloadConfigs left waiting for
+// history, ordered by TF then token, and how many recent candles each
+// needs. Callers read that history (off the processing goroutine if they
+// like, since this only describes the work), feed it with WarmUp and end
+// with FinishWarmUp.
+func (e *Engine) WarmUpTargets() []WarmUpTarget {
+	targets := make([]WarmUpTarget, 0, len(e.warming))
+	for k, ws := range e.warming {
+		need := 0
+		for _, i := range ws.fresh {
+			need = max(need, WarmUpBars(ws.ti.configs[i]))
+		}
+		targets = append(targets, WarmUpTarget{TF: k.tf, Key: k.key, Bars: need + historyBars(k.tf)})
+	}
+	sort.Slice(targets, func(i, j int) bool {
+		if targets[i].TF != targets[j].TF {
+			return targets[i].TF < targets[j].TF
+		}
+		return targets[i].Key < targets[j].Key
+	})
+	return targets
+}
+
+// historyBars is how many results to backfill beyond the warm-up, matching
+// the depth of the ind:* streams (3h of TF candles, at least 200).
+func historyBars(tf int) int {
+	if n := 10800/tf + 100; n > 200 {
+		return n
+	}
+	return 200
+}
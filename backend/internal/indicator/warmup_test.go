@@ -0,0 +1,150 @@
+package indicator
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"trading-systemv1/internal/model"
+)
+
+func historyCandles(token string, tf, n int) []model.TFCandle {
+	base := time.Date(2026, 1, 5, 3, 45, 0, 0, time.UTC)
+	out := make([]model.TFCandle, n)
+	for i := range out {
+		c := makeTFCandle(token, tf, int64(10000+(i%7)*35+i*3))
+		c.TS = base.Add(time.Duration(i*tf) * time.Second)
+		out[i] = c
+	}
+	return out
+}
+
+func TestWarmUp_OnlyFeedsNewInstances(t *testing.T) {
+	sma := []IndicatorConfig{{Type: "SMA", Period: 5}}
+	both := []IndicatorConfig{{Type: "SMA", Period: 5}, {Type: "EMA", Period: 9}}
+	history := historyCandles("SBIN", 60, 40)
+
+	engine := NewEngine([]TFIndicatorConfig{{TF: 60, Indicators: sma}})
+	reference := NewEngine([]TFIndicatorConfig{{TF: 60, Indicators: both}})
+	for _, c := range history {
+		engine.Process(c)
+		reference.Process(c)
+	}
+	smaBefore := engine.state[0]["NSE:SBIN"].indicators[0].Value()
+
+	engine.ReloadConfigs([]TFIndicatorConfig{{TF: 60, Indicators: both}})
+	if !engine.WarmingUp() {
+		t.Fatal("expected the new EMA_9 to be waiting for warm-up")
+	}
+
+	for _, c := range history {
+		if results := engine.WarmUp(c); len(results) != 1 || results[0].Name != "EMA_9" {
+			t.Fatalf("expected only EMA_9 results, got %v", resultNames(results))
+		}
+	}
+
+	// Replaying an overlapping source (Redis) must not feed candles twice.
+	for _, c := range history {
+		if results := engine.WarmUp(c); results != nil {
+			t.Fatalf("candle %s fed twice", c.TS)
+		}
+	}
+	engine.FinishWarmUp()
+
+	ti := engine.state[0]["NSE:SBIN"]
+	if ti.indicators[0].Value() != smaBefore {
+		t.Error("preserved SMA_5 must not be fed history again")
+	}
+	want := reference.state[0]["NSE:SBIN"].indicators[1].Value()
+	if got := ti.indicators[1].Value(); math.Abs(got-want) > 1e-9 || !ti.indicators[1].Ready() {
+		t.Errorf("warmed EMA_9=%.6f (ready=%v), want %.6f", got, ti.indicators[1].Ready(), want)
+	}
+}
+
+func TestWarmUp_SeedsNewTimeframe(t *testing.T) {
+	sma := []IndicatorConfig{{Type: "SMA", Period: 5}}
+	engine := NewEngine([]TFIndicatorConfig{{TF: 60, Indicators: sma}})
+	engine.Process(makeTFCandle("SBIN", 60, 10000))
+
+	engine.ReloadConfigs([]TFIndicatorConfig{
+		{TF: 60, Indicators: sma},
+		{TF: 300, Indicators: sma},
+	})
+
+	fed := 0
+	for _, c := range historyCandles("SBIN", 300, 10) {
+		if results := engine.WarmUp(c); len(results) == 1 {
+			fed++
+		}
+	}
+	engine.FinishWarmUp()
+	if fed != 10 {
+		t.Fatalf("expected 10 warm-up candles on the new TF, got %d", fed)
+	}
+	if !engine.state[engine.tfIndex[300]]["NSE:SBIN"].indicators[0].Ready() {
+		t.Error("SMA_5 on TF=300 should be ready after warm-up")
+	}
+	if engine.WarmingUp() {
+		t.Error("FinishWarmUp should clear pending instances")
+	}
+}
+
+func TestWarmUpTargets_Depth(t *testing.T) {
+	engine := NewEngine([]TFIndicatorConfig{{TF: 60, Indicators: []IndicatorConfig{{Type: "SMA", Period: 5}}}})
+	engine.Process(makeTFCandle("SBIN", 60, 10000))
+	engine.Process(makeTFCandle("INFY", 60, 10000))
+	engine.ReloadConfigs([]TFIndicatorConfig{
+		{TF: 60, Indicators: []IndicatorConfig{{Type: "SMA", Period: 5}, {Type: "SMA", Period: 50}}},
+		{TF: 300, Indicators: []IndicatorConfig{{Type: "SMA", Period: 5}}},
+	})
+
+	want := []WarmUpTarget{
+		{TF: 60, Key: "NSE:INFY", Bars: 50 + historyBars(60)},
+		{TF: 60, Key: "NSE:SBIN", Bars: 50 + historyBars(60)},
+		{TF: 300, Key: "NSE:INFY", Bars: 5 + historyBars(300)},
+		{TF: 300, Key: "NSE:SBIN", Bars: 5 + historyBars(300)},
+	}
+	if got := engine.WarmUpTargets(); !reflect.DeepEqual(got, want) {
+		t.Errorf("targets:\n got %+v\nwant %+v", got, want)
+	}
+}
+
+func TestWarmUp_LiveCandlesWaitForHistory(t *testing.T) {
+	sma := []IndicatorConfig{{Type: "SMA", Period: 5}}
+	both := []IndicatorConfig{{Type: "SMA", Period: 5}, {Type: "EMA", Period: 9}}
+	all := historyCandles("SBIN", 60, 40)
+	history, live := all[:30], all[30:]
+
+	engine := NewEngine([]TFIndicatorConfig{{TF: 60, Indicators: sma}})
+	reference := NewEngine([]TFIndicatorConfig{{TF: 60, Indicators: both}})
+	for _, c := range history {
+		engine.Process(c)
+	}
+	for _, c := range all {
+		reference.Process(c)
+	}
+
+	engine.ReloadConfigs([]TFIndicatorConfig{{TF: 60, Indicators: both}})
+	// Live candles arrive while the history is being read: the preserved
+	// SMA keeps updating, the cold EMA waits.
+	for _, c := range live {
+		if results := engine.Process(c); len(results) != 1 || results[0].Name != "SMA_5" {
+			t.Fatalf("expected only SMA_5 while warming, got %v", resultNames(results))
+		}
+	}
+	for _, c := range history {
+		engine.WarmUp(c)
+	}
+	if results := engine.FinishWarmUp(); len(results) != len(live) {
+		t.Errorf("expected %d held-back results, got %d", len(live), len(results))
+	}
+
+	for i := range both {
+		got := engine.state[0]["NSE:SBIN"].indicators[i].Value()
+		want := reference.state[0]["NSE:SBIN"].indicators[i].Value()
+		if math.Abs(got-want) > 1e-9 {
+			t.Errorf("%s=%.6f, want %.6f", both[i].Name(), got, want)
+		}
+	}
+}
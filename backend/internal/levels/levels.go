@@ -0,0 +1,83 @@
+// Package levels computes the daily price levels intraday traders key off:
+// classic, Fibonacci and Camarilla pivots, the Central Pivot Range and the
+// previous session's high/low/close.
+//
+// All levels for a session derive from the previous session's OHLC:
+//
+//	classic:   P = (H+L+C)/3, R1 = 2P−L, S1 = 2P−H, R2/S2 = P ± (H−L),
+//	           R3 = H + 2(P−L), S3 = L − 2(H−P)
+//	Fibonacci: P ± 0.382, 0.618, 1.000 × (H−L)
+//	Camarilla: C ± (H−L) × 1.1/12, 1.1/6, 1.1/4, 1.1/2
+//	CPR:       pivot P, BC = (H+L)/2, TC = 2P−BC (swapped so TC ≥ BC)
+//
+// Levels are rounded to the nearest paisa.
+package levels
+
+import (
+	"math"
+	"time"
+
+	"trading-systemv1/internal/model"
+)
+
+// Compute derives the levels for session from prev, the aggregate candle of
+// the session before it (open of its first candle, high/low over the
+// session, close of its last candle).
+func Compute(prev model.TFCandle, session time.Time) model.SessionLevels {
+	h, l, c := float64(prev.High), float64(prev.Low), float64(prev.Close)
+	r := h - l
+	p := (h + l + c) / 3
+
+	bc := (h + l) / 2
+	tc := 2*p - bc
+	if bc > tc {
+		bc, tc = tc, bc
+	}
+
+	return model.SessionLevels{
+		Token:       prev.Token,
+		Exchange:    prev.Exchange,
+		Session:     session,
+		PrevSession: prev.TS,
+		PrevOpen:    prev.Open,
+		PrevHigh:    prev.High,
+		PrevLow:     prev.Low,
+		PrevClose:   prev.Close,
+		Classic: model.PivotLevels{
+			P:  paise(p),
+			R1: paise(2*p - l),
+			R2: paise(p + r),
+			R3: paise(h + 2*(p-l)),
+			S1: paise(2*p - h),
+			S2: paise(p - r),
+			S3: paise(l - 2*(h-p)),
+		},
+		Fibonacci: model.PivotLevels{
+			P:  paise(p),
+			R1: paise(p + 0.382*r),
+			R2: paise(p + 0.618*r),
+			R3: paise(p + r),
+			S1: paise(p - 0.382*r),
+			S2: paise(p - 0.618*r),
+			S3: paise(p - r),
+		},
+		Camarilla: model.PivotLevels{
+			P:  paise(p),
+			R1: paise(c + r*1.1/12),
+			R2: paise(c + r*1.1/6),
+			R3: paise(c + r*1.1/4),
+			R4: paise(c + r*1.1/2),
+			S1: paise(c - r*1.1/12),
+			S2: paise(c - r*1.1/6),
+			S3: paise(c - r*1.1/4),
+			S4: paise(c - r*1.1/2),
+		},
+		CPR: model.CPR{
+			TC:    paise(tc),
+			Pivot: paise(p),
+			BC:    paise(bc),
+		},
+	}
+}
+
+func paise(v float64) int64 { return int64(math.Round(v)) }
@@ -0,0 +1,77 @@
+package levels
+
+import (
+	"testing"
+	"time"
+
+	"trading-systemv1/internal/model"
+)
+
+var (
+	prevOpen = time.Date(2026, 1, 5, 3, 45, 0, 0, time.UTC)
+	session  = time.Date(2026, 1, 6, 3, 45, 0, 0, time.UTC)
+)
+
+// prevDay builds the previous session's aggregate candle; prices in paise.
+func prevDay(o, h, l, c int64) model.TFCandle {
+	return model.TFCandle{
+		Token: "99926000", Exchange: "NSE", TS: prevOpen,
+		Open: o, High: h, Low: l, Close: c,
+	}
+}
+
+func TestCompute(t *testing.T) {
+	lv := Compute(prevDay(10200, 11000, 10000, 10600), session)
+
+	if lv.Key() != "NSE:99926000" || !lv.Session.Equal(session) || !lv.PrevSession.Equal(prevOpen) {
+		t.Errorf("identity: %s %s %s", lv.Key(), lv.Session, lv.PrevSession)
+	}
+	if lv.PrevOpen != 10200 || lv.PrevHigh != 11000 || lv.PrevLow != 10000 || lv.PrevClose != 10600 {
+		t.Errorf("previous session OHLC not carried over: %+v", lv)
+	}
+
+	tests := []struct {
+		name string
+		got  model.PivotLevels
+		want model.PivotLevels
+	}{
+		// P = 10533.33, range = 1000
+		{"classic", lv.Classic, model.PivotLevels{
+			P: 10533, R1: 11067, R2: 11533, R3: 12067, S1: 10067, S2: 9533, S3: 9067,
+		}},
+		{"fibonacci", lv.Fibonacci, model.PivotLevels{
+			P: 10533, R1: 10915, R2: 11151, R3: 11533, S1: 10151, S2: 9915, S3: 9533,
+		}},
+		{"camarilla", lv.Camarilla, model.PivotLevels{
+			P: 10533, R1: 10692, R2: 10783, R3: 10875, R4: 11150, S1: 10508, S2: 10417, S3: 10325, S4: 10050,
+		}},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s:\n got %+v\nwant %+v", tt.name, tt.got, tt.want)
+		}
+	}
+
+	if want := (model.CPR{TC: 10567, Pivot: 10533, BC: 10500}); lv.CPR != want {
+		t.Errorf("cpr: got %+v, want %+v", lv.CPR, want)
+	}
+}
+
+func TestCompute_CPROrdered(t *testing.T) {
+	// A close near the low puts the pivot below the high/low midpoint, so
+	// 2P−BC falls under BC and the two must be swapped.
+	lv := Compute(prevDay(10800, 11000, 10000, 10100), session)
+	if want := (model.CPR{TC: 10500, Pivot: 10367, BC: 10233}); lv.CPR != want {
+		t.Errorf("cpr: got %+v, want %+v", lv.CPR, want)
+	}
+}
+
+func TestSessionLevels_Keys(t *testing.T) {
+	lv := model.SessionLevels{Exchange: "NSE", Token: "99926000"}
+	if got := lv.RedisKey(); got != "levels:NSE:99926000" {
+		t.Errorf("redis key: %s", got)
+	}
+	if got := lv.PubSubChannel(); got != "pub:levels:NSE:99926000" {
+		t.Errorf("channel: %s", got)
+	}
+}
@@ -3,6 +3,8 @@ package agg
 import (
 	"context"
 	"log"
+	"math"
+	"sort"
 	"sync"
 	"time"
 
@@ -13,6 +15,31 @@ import (
 type candleState struct {
 	bucket int64 // Unix second of this bucket
 	candle model.Candle
+
+	first, last time.Time // canonical times of the earliest and latest tick merged
+}
+
+// newState starts the candle of bucket with tick.
+func newState(tick model.Tick, bucket int64) *candleState {
+	ts := tick.CanonicalTS()
+	return &candleState{
+		bucket: bucket,
+		candle: model.Candle{
+			Token:      tick.Token,
+			Exchange:   tick.Exchange,
+			TS:         time.Unix(bucket, 0).UTC(),
+			Open:       tick.Price,
+			High:       tick.Price,
+			Low:        tick.Price,
+			Close:      tick.Price,
+			Volume:     tick.Qty,
+			TicksCount: 1,
+			OIOpen:     tick.OI,
+			OIClose:    tick.OI,
+		},
+		first: ts,
+		last:  ts,
+	}
 }
 
 // Aggregator builds 1-second OHLC candles from a stream of ticks.
@@ -38,14 +65,45 @@ type Aggregator struct {
 	// Metrics hooks (optional, set externally)
 	OnDroppedTick func() // called when candleCh is full
 	OnLateTick    func() // called when tick arrives behind watermark (event-time)
+
+	// OnTick sees every tick as received, before aggregation (optional).
+	// It runs on the hot path and must not block.
+	OnTick func(t model.Tick)
+
+	// AmendWindow enables candle amendments: a tick up to AmendWindow behind
+	// the watermark revises its already finalized 1s candle, which is sent
+	// again on candleCh with Revision incremented, instead of being dropped.
+	// 0 disables amendments (default).
+	AmendWindow time.Duration
+	OnAmend     func() // called per amended candle (optional)
+
+	// recent holds the finalized candles still within AmendWindow,
+	// by instrument key and bucket.
+	recent map[string]map[int64]*candleState
+
+	// GapFill selects the instruments ("exchange:token") whose empty seconds
+	// during market hours are filled with synthetic flat candles (see
+	// fillGap). nil disables gap filling (default).
+	GapFill func(key string) bool
+
+	// MaxGapFill is the longest gap filled at once, e.g. after a feed
+	// reconnect; longer gaps are left empty. 0 = no cap. Default: 30m.
+	MaxGapFill time.Duration
+	OnGapFill  func(n int) // called with the number of candles filled (optional)
+
+	// gaps holds the last candle emitted per gap-fill instrument.
+	gaps map[string]*gapState
 }
 
 // New creates a new Aggregator with default settings.
 func New() *Aggregator {
 	return &Aggregator{
 		states:        make(map[string]*candleState),
+		recent:        make(map[string]map[int64]*candleState),
+		gaps:          make(map[string]*gapState),
 		flushInterval: 100 * time.Millisecond, // check frequency for bucket rollover
 		ReorderBuffer: 300 * time.Millisecond, // default out-of-order tolerance
+		MaxGapFill:    30 * time.Minute,
 	}
 }
 
@@ -79,6 +137,9 @@ func (a *Aggregator) Run(ctx context.Context, tickCh <-chan model.Tick, candleCh
 				a.flushAll(candleCh)
 				return
 			}
+			if a.OnTick != nil {
+				a.OnTick(tick)
+			}
 			a.processTick(tick, candleCh)
 
 		case <-ticker.C:
@@ -108,8 +169,18 @@ func (a *Aggregator) processTick(tick model.Tick, candleCh chan<- model.Candle)
 		a.watermark = a.maxEventTS - bufSec
 	}
 
-	// Drop ticks behind the watermark — their buckets are already finalized (immutable)
+	// Ticks behind the watermark — their buckets are already finalized: amend
+	// the candle if amendments are on and it is recent enough, else drop
 	if a.watermark > 0 && bucket < a.watermark {
+		if a.amend(key, bucket, tick, candleCh) {
+			cb := a.OnAmend
+			a.mu.Unlock()
+			if cb != nil {
+				cb()
+			}
+			a.mu.Lock()
+			return
+		}
 		cb := a.OnLateTick
 		a.mu.Unlock()
 		if cb != nil {
@@ -126,20 +197,7 @@ func (a *Aggregator) processTick(tick model.Tick, candleCh chan<- model.Candle)
 		// With event-time watermark, this bucket hasn't been finalized yet,
 		// so we need to create a new bucket entry for it.
 		// The old state stays as-is; we start a new one for the older bucket.
-		a.states[key+":"+time.Unix(bucket, 0).UTC().Format("15:04:05")] = &candleState{
-			bucket: bucket,
-			candle: model.Candle{
-				Token:      tick.Token,
-				Exchange:   tick.Exchange,
-				TS:         time.Unix(bucket, 0).UTC(),
-				Open:       tick.Price,
-				High:       tick.Price,
-				Low:        tick.Price,
-				Close:      tick.Price,
-				Volume:     tick.Qty,
-				TicksCount: 1,
-			},
-		}
+		a.states[pendingKey(key, bucket)] = newState(tick, bucket)
 		return
 	}
 
@@ -152,20 +210,7 @@ func (a *Aggregator) processTick(tick model.Tick, candleCh chan<- model.Candle)
 
 	if !exists {
 		// Start a new candle for this bucket
-		a.states[key] = &candleState{
-			bucket: bucket,
-			candle: model.Candle{
-				Token:      tick.Token,
-				Exchange:   tick.Exchange,
-				TS:         time.Unix(bucket, 0).UTC(),
-				Open:       tick.Price,
-				High:       tick.Price,
-				Low:        tick.Price,
-				Close:      tick.Price,
-				Volume:     tick.Qty,
-				TicksCount: 1,
-			},
-		}
+		a.states[key] = newState(tick, bucket)
 		return
 	}
 
@@ -180,6 +225,80 @@ func (a *Aggregator) processTick(tick model.Tick, candleCh chan<- model.Candle)
 	c.Close = tick.Price
 	c.Volume += tick.Qty
 	c.TicksCount++
+	if tick.OI != 0 {
+		if c.OIOpen == 0 {
+			c.OIOpen = tick.OI
+		}
+		c.OIClose = tick.OI
+	}
+	state.first = minTime(state.first, canonicalTS)
+	state.last = maxTime(state.last, canonicalTS)
+}
+
+// amend merges a tick behind the watermark into its finalized candle, or
+// starts one if the second had none, and re-emits the candle with Revision
+// incremented. Unlike the live path, open and close follow the tick's event
+// time, not its arrival. Returns false if amendments are off or the bucket
+// is older than AmendWindow. Must be called with a.mu held.
+func (a *Aggregator) amend(key string, bucket int64, tick model.Tick, candleCh chan<- model.Candle) bool {
+	if a.AmendWindow <= 0 || bucket < a.watermark-int64(a.AmendWindow/time.Second) {
+		return false
+	}
+	byBucket := a.recent[key]
+	if byBucket == nil {
+		byBucket = make(map[int64]*candleState)
+		a.recent[key] = byBucket
+	}
+
+	ts := tick.CanonicalTS()
+	state, ok := byBucket[bucket]
+	if !ok || state.candle.Synthetic {
+		// The first trade of a second that had none, or only a gap-fill candle
+		rev := 0
+		if ok {
+			rev = state.candle.Revision
+		}
+		state = newState(tick, bucket)
+		state.candle.Revision = rev
+		byBucket[bucket] = state
+	} else {
+		c := &state.candle
+		c.High = max(c.High, tick.Price)
+		c.Low = min(c.Low, tick.Price)
+		if ts.Before(state.first) {
+			c.Open = tick.Price
+			state.first = ts
+			if tick.OI != 0 {
+				c.OIOpen = tick.OI
+			}
+		}
+		if !ts.Before(state.last) {
+			c.Close = tick.Price
+			state.last = ts
+			if tick.OI != 0 {
+				c.OIClose = tick.OI
+			}
+		}
+		c.Volume += tick.Qty
+		c.TicksCount++
+	}
+	state.candle.Revision++
+	a.send(state, candleCh)
+	return true
+}
+
+func minTime(a, b time.Time) time.Time {
+	if b.Before(a) {
+		return b
+	}
+	return a
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
 }
 
 // flushOld emits candles for any bucket that is behind the event-time watermark.
@@ -189,20 +308,29 @@ func (a *Aggregator) flushOld(candleCh chan<- model.Candle) {
 
 	if a.watermark == 0 {
 		// No ticks received yet; fall back to wall-clock time
-		now := time.Now().Unix()
-		for key, state := range a.states {
-			if state.bucket < now {
-				a.emit(state, candleCh)
-				delete(a.states, key)
-			}
-		}
+		a.emitBefore(time.Now().Unix(), candleCh)
 		return
 	}
 
-	for key, state := range a.states {
-		if state.bucket < a.watermark {
-			a.emit(state, candleCh)
-			delete(a.states, key)
+	a.emitBefore(a.watermark, candleCh)
+
+	// Fill the empty seconds of gap-fill instruments up to the watermark
+	if a.GapFill != nil {
+		for key := range a.gaps {
+			a.fillGap(key, a.watermark, candleCh)
+		}
+	}
+
+	// Forget finalized candles that fell out of the amendment window
+	horizon := a.watermark - int64(a.AmendWindow/time.Second)
+	for key, byBucket := range a.recent {
+		for bucket := range byBucket {
+			if bucket < horizon {
+				delete(byBucket, bucket)
+			}
+		}
+		if len(byBucket) == 0 {
+			delete(a.recent, key)
 		}
 	}
 }
@@ -220,14 +348,55 @@ func (a *Aggregator) flushAll(candleCh chan<- model.Candle) {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
+	a.emitBefore(math.MaxInt64, candleCh)
+}
+
+// emitBefore emits and removes the candles whose bucket is before limit,
+// oldest first, so that an out-of-order bucket still pending is emitted
+// before a newer one of its instrument. Must be called with a.mu held.
+func (a *Aggregator) emitBefore(limit int64, candleCh chan<- model.Candle) {
+	var keys []string
 	for key, state := range a.states {
-		a.emit(state, candleCh)
+		if state.bucket < limit {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return a.states[keys[i]].bucket < a.states[keys[j]].bucket
+	})
+	for _, key := range keys {
+		a.emit(a.states[key], candleCh)
 		delete(a.states, key)
 	}
 }
 
-// emit sends a finalized candle to candleCh. Non-blocking to avoid deadlocks.
+// pendingKey is the states key of an out-of-order bucket held while an
+// instrument's newer bucket is forming.
+func pendingKey(key string, bucket int64) string {
+	return key + ":" + time.Unix(bucket, 0).UTC().Format("15:04:05")
+}
+
+// emit sends a finalized candle to candleCh, after the synthetic candles
+// filling the gap before it if the instrument has gap filling on.
 func (a *Aggregator) emit(state *candleState, candleCh chan<- model.Candle) {
+	if a.GapFill != nil {
+		a.fillGap(state.candle.Key(), state.bucket, candleCh)
+		a.trackGap(state.candle)
+	}
+	a.send(state, candleCh)
+}
+
+// send sends a candle to candleCh. Non-blocking to avoid deadlocks.
+// With amendments on, the candle is kept for AmendWindow.
+func (a *Aggregator) send(state *candleState, candleCh chan<- model.Candle) {
+	if a.AmendWindow > 0 {
+		key := state.candle.Key()
+		if a.recent[key] == nil {
+			a.recent[key] = make(map[int64]*candleState)
+		}
+		a.recent[key][state.bucket] = state
+	}
+
 	select {
 	case candleCh <- state.candle:
 	default:
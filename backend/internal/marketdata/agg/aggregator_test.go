@@ -335,3 +335,201 @@ done3:
 		}
 	}
 }
+
+func TestAggregator_OpenInterest(t *testing.T) {
+	agg := New()
+	candleCh := make(chan model.Candle, 10)
+
+	ts := time.Date(2026, 1, 5, 3, 45, 0, 0, time.UTC)
+	tick := func(ms int, price, oi int64) model.Tick {
+		return model.Tick{Token: "43650", Exchange: "NFO", Price: price, Qty: 75, TickTS: ts.Add(time.Duration(ms) * time.Millisecond), OI: oi}
+	}
+	agg.processTick(tick(0, 2000000, 0), candleCh) // LTP tick: OI unknown
+	agg.processTick(tick(200, 2000500, 1500000), candleCh)
+	agg.processTick(tick(400, 2000100, 0), candleCh)
+	agg.processTick(tick(600, 2000200, 1498500), candleCh)
+	agg.flushAll(candleCh)
+
+	c := <-candleCh
+	if c.OIOpen != 1500000 || c.OIClose != 1498500 {
+		t.Errorf("oi open/close: got %d/%d, want 1500000/1498500", c.OIOpen, c.OIClose)
+	}
+}
+
+// TestAggregator_Amend verifies that with AmendWindow set, a tick behind the
+// watermark revises its finalized candle, and one beyond the window is dropped.
+func TestAggregator_Amend(t *testing.T) {
+	agg := New()
+	agg.ReorderBuffer = time.Second
+	agg.AmendWindow = 5 * time.Second
+	amended, late := 0, 0
+	agg.OnAmend = func() { amended++ }
+	agg.OnLateTick = func() { late++ }
+	candleCh := make(chan model.Candle, 10)
+
+	base := time.Date(2026, 1, 5, 3, 45, 0, 0, time.UTC)
+	tick := func(sec, ms int, price int64) model.Tick {
+		return model.Tick{Token: "3045", Exchange: "NSE", Price: price, Qty: 10,
+			TickTS: base.Add(time.Duration(sec)*time.Second + time.Duration(ms)*time.Millisecond)}
+	}
+	agg.processTick(tick(10, 200, 50000), candleCh)
+	agg.processTick(tick(10, 600, 50100), candleCh)
+	agg.processTick(tick(13, 0, 50200), candleCh) // watermark → 12, finalizes :10
+
+	if c := <-candleCh; c.Revision != 0 || c.Close != 50100 {
+		t.Fatalf("first version: revision=%d close=%d, want 0/50100", c.Revision, c.Close)
+	}
+
+	agg.processTick(tick(10, 100, 49900), candleCh) // before the first tick: new open and low
+	c := <-candleCh
+	if c.Revision != 1 || c.Open != 49900 || c.Low != 49900 || c.Close != 50100 || c.Volume != 30 || c.TicksCount != 3 {
+		t.Errorf("revision 1: got rev=%d O=%d L=%d C=%d V=%d n=%d", c.Revision, c.Open, c.Low, c.Close, c.Volume, c.TicksCount)
+	}
+
+	agg.processTick(tick(10, 900, 50300), candleCh) // after the last tick: new close and high
+	c = <-candleCh
+	if c.Revision != 2 || c.High != 50300 || c.Close != 50300 {
+		t.Errorf("revision 2: got rev=%d H=%d C=%d", c.Revision, c.High, c.Close)
+	}
+
+	agg.processTick(tick(11, 0, 50000), candleCh) // a second with no candle yet
+	c = <-candleCh
+	if c.Revision != 1 || c.TS.Unix() != base.Unix()+11 || c.TicksCount != 1 {
+		t.Errorf("new second: got rev=%d ts=%v n=%d", c.Revision, c.TS, c.TicksCount)
+	}
+
+	agg.processTick(tick(20, 0, 50000), candleCh) // watermark → 19, horizon 14
+	agg.flushOld(candleCh)
+	<-candleCh // :13
+	agg.processTick(tick(10, 300, 50000), candleCh)
+	if len(candleCh) != 0 {
+		t.Errorf("tick beyond the window should not amend, got %+v", <-candleCh)
+	}
+	if amended != 3 || late != 1 {
+		t.Errorf("callbacks: amended=%d late=%d, want 3/1", amended, late)
+	}
+}
+
+// TestAggregator_GapFill verifies that empty seconds of a gap-fill
+// instrument are emitted as flat synthetic candles, in order, and that
+// other instruments are left alone.
+func TestAggregator_GapFill(t *testing.T) {
+	agg := New()
+	agg.GapFill = func(key string) bool { return key == "NSE:3045" }
+	filled := 0
+	agg.OnGapFill = func(n int) { filled += n }
+	candleCh := make(chan model.Candle, 100)
+
+	base := time.Date(2026, 1, 5, 3, 50, 0, 0, time.UTC) // 9:20 IST
+	tick := func(token string, sec int, price int64) model.Tick {
+		return model.Tick{Token: token, Exchange: "NSE", Price: price, Qty: 10, OI: 500, TickTS: base.Add(time.Duration(sec) * time.Second)}
+	}
+	agg.processTick(tick("3045", 0, 50000), candleCh)
+	agg.processTick(tick("2885", 0, 120000), candleCh)
+	agg.processTick(tick("2885", 5, 120100), candleCh) // watermark → 4
+	agg.flushOld(candleCh)                             // :0 of both, then 3045 filled :1–:3
+	agg.processTick(tick("3045", 6, 50200), candleCh)  // watermark → 5
+	agg.flushOld(candleCh)
+	agg.flushAll(candleCh) // 3045 filled :4 and :5 before its :6
+
+	var got []model.Candle
+	for len(candleCh) > 0 {
+		if c := <-candleCh; c.Token == "3045" {
+			got = append(got, c)
+		}
+	}
+	if len(got) != 7 {
+		t.Fatalf("expected 7 candles for 3045 (:0–:6), got %d", len(got))
+	}
+	for i, c := range got {
+		if c.TS.Unix() != base.Unix()+int64(i) {
+			t.Errorf("candle %d: ts=%v, want :%02d", i, c.TS, i)
+		}
+		synthetic := i > 0 && i < 6
+		if c.Synthetic != synthetic {
+			t.Errorf("candle %d: synthetic=%v, want %v", i, c.Synthetic, synthetic)
+		}
+		if synthetic && (c.Open != 50000 || c.High != 50000 || c.Low != 50000 || c.Close != 50000 || c.Volume != 0 || c.TicksCount != 0 || c.OIClose != 500) {
+			t.Errorf("candle %d: not flat at the previous close: %+v", i, c)
+		}
+	}
+	if filled != 5 {
+		t.Errorf("OnGapFill total: got %d, want 5", filled)
+	}
+}
+
+// TestAggregator_GapFillMarketHours verifies that gaps are only filled
+// during market hours and that a long outage is left empty.
+func TestAggregator_GapFillMarketHours(t *testing.T) {
+	agg := New()
+	agg.GapFill = func(string) bool { return true }
+	agg.MaxGapFill = time.Minute
+	candleCh := make(chan model.Candle, 100)
+
+	open := time.Date(2026, 1, 5, 3, 45, 0, 0, time.UTC) // 9:15 IST
+	tick := func(at time.Time, price int64) model.Tick {
+		return model.Tick{Token: "3045", Exchange: "NSE", Price: price, Qty: 10, TickTS: at}
+	}
+	agg.processTick(tick(open.Add(-3*time.Second), 50000), candleCh) // pre-open
+	agg.processTick(tick(open.Add(2*time.Second), 50100), candleCh)
+	agg.flushAll(candleCh)
+
+	var got []model.Candle
+	for len(candleCh) > 0 {
+		got = append(got, <-candleCh)
+	}
+	// 9:14:57 real, 9:15:00 and :01 filled (9:14:58–59 are before the open), 9:15:02 real
+	if len(got) != 4 || !got[1].Synthetic || !got[1].TS.Equal(open) || got[3].Synthetic {
+		t.Fatalf("pre-open gap: got %+v", got)
+	}
+
+	agg.processTick(tick(open.Add(5*time.Minute), 50200), candleCh) // 5 min outage
+	agg.flushAll(candleCh)
+	if n := len(candleCh); n != 1 {
+		t.Errorf("gap beyond MaxGapFill: expected only the real candle, got %d candles", n)
+	}
+}
+
+// TestAggregator_GapFillOutOfOrder verifies that a bucket held for an
+// out-of-order tick is emitted before the newer one and not gap-filled, so
+// each second gets exactly one candle.
+func TestAggregator_GapFillOutOfOrder(t *testing.T) {
+	base := time.Date(2026, 1, 5, 3, 50, 0, 0, time.UTC) // 9:20 IST
+	tick := func(token string, sec int, price int64) model.Tick {
+		return model.Tick{Token: token, Exchange: "NSE", Price: price, Qty: 10, TickTS: base.Add(time.Duration(sec) * time.Second)}
+	}
+
+	for run := 0; run < 20; run++ { // flushOld walks a map: repeat to cover its orders
+		agg := New()
+		agg.ReorderBuffer = 3 * time.Second
+		agg.GapFill = func(key string) bool { return key == "NSE:3045" }
+		candleCh := make(chan model.Candle, 100)
+
+		agg.processTick(tick("3045", 0, 50000), candleCh)
+		agg.processTick(tick("3045", 3, 50300), candleCh)  // emits :0
+		agg.processTick(tick("3045", 1, 50100), candleCh)  // out of order, within the reorder window
+		agg.processTick(tick("2885", 8, 120000), candleCh) // watermark → 5
+		agg.flushOld(candleCh)                             // :1 and :3 pending, :2 and :4 empty
+
+		var got []model.Candle
+		for len(candleCh) > 0 {
+			if c := <-candleCh; c.Token == "3045" {
+				got = append(got, c)
+			}
+		}
+		if len(got) != 5 {
+			t.Fatalf("run %d: expected 5 candles (:0–:4), got %d: %+v", run, len(got), got)
+		}
+		for i, c := range got {
+			if c.TS.Unix() != base.Unix()+int64(i) {
+				t.Fatalf("run %d: candle %d ts=%v, want :%02d", run, i, c.TS, i)
+			}
+			if synthetic := i == 2 || i == 4; c.Synthetic != synthetic {
+				t.Errorf("run %d: candle %d synthetic=%v, want %v", run, i, c.Synthetic, synthetic)
+			}
+		}
+		if got[1].Close != 50100 {
+			t.Errorf("run %d: :01 close=%d, want the out-of-order tick's 50100", run, got[1].Close)
+		}
+	}
+}
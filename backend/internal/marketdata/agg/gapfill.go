@@ -0,0 +1,94 @@
+package agg
+
+import (
+	"log"
+	"time"
+
+	"trading-systemv1/internal/markethours"
+	"trading-systemv1/internal/model"
+)
+
+// gapState is the last candle emitted for a gap-fill instrument.
+type gapState struct {
+	bucket int64
+	candle model.Candle
+}
+
+// trackGap records c as the last candle of its instrument, if the
+// instrument has gap filling on. Must be called with a.mu held.
+func (a *Aggregator) trackGap(c model.Candle) {
+	key := c.Key()
+	gs, ok := a.gaps[key]
+	if !ok {
+		if !a.GapFill(key) {
+			return
+		}
+		gs = &gapState{bucket: -1}
+		a.gaps[key] = gs
+	}
+	if bucket := c.TS.Unix(); bucket > gs.bucket {
+		gs.bucket, gs.candle = bucket, c
+	}
+}
+
+// fillGap emits a synthetic candle for every market-hours second after the
+// instrument's last candle and before bucket: OHLC at the last close, zero
+// volume and ticks, the last open interest. A gap spanning sessions is
+// filled from the new session's open. Seconds with an out-of-order bucket
+// still pending are left to it, and gaps longer than MaxGapFill are
+// skipped. Must be called with a.mu held.
+func (a *Aggregator) fillGap(key string, bucket int64, candleCh chan<- model.Candle) {
+	gs, ok := a.gaps[key]
+	if !ok || gs.bucket < 0 {
+		return // no close to carry forward yet
+	}
+	from := gs.bucket + 1
+	if open := markethours.SessionOpen(time.Unix(bucket, 0)).Unix(); from < open {
+		from = open
+	}
+	if from >= bucket {
+		return
+	}
+	if gap := time.Duration(bucket-from) * time.Second; a.MaxGapFill > 0 && gap > a.MaxGapFill {
+		log.Printf("[agg] %s: %v gap too long to fill (max %v), left empty", key, gap, a.MaxGapFill)
+		gs.bucket = bucket - 1
+		return
+	}
+
+	prev := gs.candle
+	n := 0
+	for b := from; b < bucket; b++ {
+		ts := time.Unix(b, 0).UTC()
+		if !markethours.IsMarketOpen(ts) {
+			continue
+		}
+		if _, pending := a.states[pendingKey(key, b)]; pending {
+			continue
+		}
+		c := model.Candle{
+			Token:     prev.Token,
+			Exchange:  prev.Exchange,
+			TS:        ts,
+			Open:      prev.Close,
+			High:      prev.Close,
+			Low:       prev.Close,
+			Close:     prev.Close,
+			OIOpen:    prev.OIClose,
+			OIClose:   prev.OIClose,
+			Synthetic: true,
+		}
+		a.send(&candleState{bucket: b, candle: c}, candleCh)
+		gs.bucket, gs.candle = b, c
+		n++
+	}
+	gs.bucket = bucket - 1
+	if n == 0 {
+		return
+	}
+	if n > 60 {
+		log.Printf("[agg] %s: filled a %ds gap with synthetic candles", key, n)
+	}
+	if a.OnGapFill != nil {
+		a.OnGapFill(n)
+	}
+}
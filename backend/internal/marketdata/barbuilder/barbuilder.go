@@ -0,0 +1,228 @@
+// Package barbuilder builds bars that close on activity rather than time:
+// N-tick, N-volume and fixed-range bars and Renko bricks. It is the sibling
+// of tfbuilder, but consumes the raw ticks (agg.Aggregator.OnTick) rather
+// than 1s candles, so thresholds are met on the exact tick.
+//
+// Bars are model.TFCandles whose TF is a bar series identity (model.BarTF),
+// so they share the candle streams, the indicator engine and the gateway
+// with time TFs under their own key segment, e.g. "candle:tick500:NSE:2885".
+//
+// A trade is never split: volume bars close on the tick that reaches their
+// threshold (so its quantity may overshoot it). Renko bricks are built from
+// trade prices; when one tick moves several bricks they are emitted
+// together. Bars are stamped with the event time of their first tick, moved
+// a millisecond past the series' previous bar when needed, so timestamps
+// stay unique and increasing even for ticks that share (or go back in) time.
+package barbuilder
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"trading-systemv1/internal/model"
+)
+
+// barState holds the forming bar for one (token, bar series) pair.
+type barState struct {
+	candle  model.TFCandle
+	started bool
+	lastTS  time.Time // timestamp of the latest bar or brick
+
+	// Renko only: the last brick's body, and whether any brick exists yet.
+	brickLo, brickHi int64
+	bricks           bool
+}
+
+// Builder turns ticks into bars for every configured bar series.
+// RunTick runs on the aggregator goroutine and FlushSession on the session
+// loop, so the state is guarded by a mutex, as in agg.Aggregator.
+type Builder struct {
+	mu  sync.Mutex
+	tfs []int // bar series identities (model.BarTF)
+
+	// Per-series per-token state.
+	// Key structure: states[tfIdx][tokenKey] → *barState
+	states []map[string]*barState
+
+	// OnBar is called on every finalized bar or brick (optional).
+	OnBar func(c model.TFCandle)
+}
+
+// New creates a bar builder for the given bar series. Time TFs in tfs are ignored.
+func New(tfs []int) *Builder {
+	var bars []int
+	for _, tf := range tfs {
+		if model.IsBarTF(tf) {
+			bars = append(bars, tf)
+		}
+	}
+	states := make([]map[string]*barState, len(bars))
+	for i := range states {
+		states[i] = make(map[string]*barState, 64)
+	}
+	return &Builder{tfs: bars, states: states}
+}
+
+// TFs returns the bar series being built.
+func (b *Builder) TFs() []int {
+	return b.tfs
+}
+
+// RunTick processes a single tick against all bar series (hot path).
+func (b *Builder) RunTick(t model.Tick, outCh chan<- model.TFCandle) {
+	key := t.Exchange + ":" + t.Token
+	ts := t.CanonicalTS()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for i, tf := range b.tfs {
+		st, ok := b.states[i][key]
+		if !ok {
+			st = &barState{}
+			b.states[i][key] = st
+		}
+		kind, size := model.BarSpec(tf)
+		if kind == model.BarRenko {
+			b.renko(st, tf, size, t, ts, outCh)
+			continue
+		}
+		b.merge(st, tf, t, ts)
+
+		var done bool
+		switch kind {
+		case model.BarTick:
+			done = int64(st.candle.Count) >= size
+		case model.BarVolume:
+			done = st.candle.Volume >= size
+		case model.BarRange:
+			done = st.candle.High-st.candle.Low >= size
+		}
+		if done {
+			b.finalize(st, outCh)
+			continue
+		}
+		// Forming snapshot for the live chart, as tfbuilder does.
+		emit(outCh, st.candle)
+	}
+}
+
+// merge folds a tick into the forming bar, starting one if needed.
+func (b *Builder) merge(st *barState, tf int, t model.Tick, ts time.Time) {
+	if !st.started {
+		st.candle = model.TFCandle{
+			Token:    t.Token,
+			Exchange: t.Exchange,
+			TF:       tf,
+			TS:       st.stamp(ts),
+			Open:     t.Price,
+			High:     t.Price,
+			Low:      t.Price,
+			Close:    t.Price,
+			Volume:   t.Qty,
+			Count:    1,
+			Forming:  true,
+			OIOpen:   t.OI,
+			OIClose:  t.OI,
+		}
+		st.started = true
+		return
+	}
+	fc := &st.candle
+	if t.Price > fc.High {
+		fc.High = t.Price
+	}
+	if t.Price < fc.Low {
+		fc.Low = t.Price
+	}
+	fc.Close = t.Price
+	fc.Volume += t.Qty
+	fc.Count++
+	fc.MergeOI(t.OI, t.OI)
+}
+
+// stamp returns the timestamp for a new bar starting at ts: ts itself, or
+// a millisecond after the previous bar if ts is not later.
+func (st *barState) stamp(ts time.Time) time.Time {
+	if !ts.After(st.lastTS) {
+		ts = st.lastTS.Add(time.Millisecond)
+	}
+	st.lastTS = ts
+	return ts
+}
+
+// finalize emits the forming bar and resets the state for the next one.
+func (b *Builder) finalize(st *barState, outCh chan<- model.TFCandle) {
+	st.candle.Forming = false
+	emit(outCh, st.candle)
+	if b.OnBar != nil {
+		b.OnBar(st.candle)
+	}
+	st.started = false
+}
+
+// renko emits the bricks a tick's price completes. An up brick needs a
+// price one size above the last brick's top, a down brick one size below
+// its bottom, so a reversal takes two sizes from the last price. Volume
+// and tick count since the previous brick go to the first new brick.
+func (b *Builder) renko(st *barState, tf int, size int64, t model.Tick, ts time.Time, outCh chan<- model.TFCandle) {
+	if !st.bricks {
+		// The first price anchors the grid; no brick until price moves.
+		st.brickLo, st.brickHi, st.bricks = t.Price, t.Price, true
+		st.candle = model.TFCandle{Token: t.Token, Exchange: t.Exchange, TF: tf}
+	}
+	st.candle.Volume += t.Qty
+	st.candle.Count++
+	st.candle.MergeOI(t.OI, t.OI)
+
+	for {
+		var open, close int64
+		switch {
+		case t.Price >= st.brickHi+size:
+			open, close = st.brickHi, st.brickHi+size
+		case t.Price <= st.brickLo-size:
+			open, close = st.brickLo, st.brickLo-size
+		default:
+			return
+		}
+		brick := st.candle
+		brick.TS = st.stamp(ts)
+		brick.Open, brick.Close = open, close
+		brick.High, brick.Low = max(open, close), min(open, close)
+		brick.Forming = false
+		emit(outCh, brick)
+		if b.OnBar != nil {
+			b.OnBar(brick)
+		}
+		st.brickLo, st.brickHi = brick.Low, brick.High
+		st.candle.Volume, st.candle.Count = 0, 0
+		st.candle.OIOpen = st.candle.OIClose // the next brick opens where this one closed
+	}
+}
+
+// FlushSession finalizes the forming tick, volume and range bars so every
+// session starts a fresh bar. Renko keeps its brick grid across sessions.
+func (b *Builder) FlushSession(outCh chan<- model.TFCandle) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for i, tf := range b.tfs {
+		if kind, _ := model.BarSpec(tf); kind == model.BarRenko {
+			continue
+		}
+		for _, st := range b.states[i] {
+			if st.started {
+				b.finalize(st, outCh)
+			}
+		}
+	}
+	log.Println("[barbuilder] session flushed — forming bars finalized")
+}
+
+// emit sends a bar to the output channel. Non-blocking to avoid deadlocks.
+func emit(outCh chan<- model.TFCandle, c model.TFCandle) {
+	select {
+	case outCh <- c:
+	default:
+		log.Printf("[barbuilder] outCh full, dropping bar %s %s ts=%v", c.Key(), model.TFKey(c.TF), c.TS)
+	}
+}
@@ -0,0 +1,186 @@
+package barbuilder
+
+import (
+	"testing"
+	"time"
+
+	"trading-systemv1/internal/model"
+)
+
+var base = time.Date(2026, 1, 5, 3, 45, 0, 0, time.UTC)
+
+// tick builds a trade i×100ms after base.
+func tick(i int, price, qty int64) model.Tick {
+	return model.Tick{
+		Token: "2885", Exchange: "NSE",
+		Price: price, Qty: qty,
+		TickTS: base.Add(time.Duration(i) * 100 * time.Millisecond),
+	}
+}
+
+// run feeds ticks and returns the finalized bars.
+func run(b *Builder, ticks ...model.Tick) []model.TFCandle {
+	outCh := make(chan model.TFCandle, 1000)
+	for _, t := range ticks {
+		b.RunTick(t, outCh)
+	}
+	var bars []model.TFCandle
+	for len(outCh) > 0 {
+		if c := <-outCh; !c.Forming {
+			bars = append(bars, c)
+		}
+	}
+	return bars
+}
+
+func TestTickBars(t *testing.T) {
+	b := New([]int{60, model.BarTF(model.BarTick, 3)})
+	if len(b.TFs()) != 1 {
+		t.Fatalf("time TFs must be ignored, got %v", b.TFs())
+	}
+	bars := run(b,
+		tick(0, 100, 10),
+		tick(1, 105, 10),
+		tick(2, 99, 10), // 3rd tick → closes
+		tick(3, 104, 10),
+	)
+	if len(bars) != 1 {
+		t.Fatalf("expected 1 bar, got %d", len(bars))
+	}
+	got := bars[0]
+	if got.TF != model.BarTF(model.BarTick, 3) || !got.TS.Equal(base) {
+		t.Errorf("identity: tf=%d ts=%s", got.TF, got.TS)
+	}
+	if got.Open != 100 || got.High != 105 || got.Low != 99 || got.Close != 99 || got.Volume != 30 || got.Count != 3 {
+		t.Errorf("unexpected bar: %+v", got)
+	}
+}
+
+func TestVolumeBars(t *testing.T) {
+	b := New([]int{model.BarTF(model.BarVolume, 100)})
+	bars := run(b,
+		tick(0, 100, 60),
+		tick(1, 101, 50), // 110 ≥ 100
+		tick(2, 99, 100), // exactly 100
+		tick(3, 98, 10),
+	)
+	if len(bars) != 2 {
+		t.Fatalf("expected 2 bars, got %d", len(bars))
+	}
+	if bars[0].Volume != 110 || bars[1].Volume != 100 || !bars[1].TS.Equal(base.Add(200*time.Millisecond)) {
+		t.Errorf("unexpected bars: %+v", bars)
+	}
+}
+
+func TestRangeBars(t *testing.T) {
+	b := New([]int{model.BarTF(model.BarRange, 50)})
+	bars := run(b,
+		tick(0, 1000, 1),
+		tick(1, 1020, 1),
+		tick(2, 990, 1),  // range 30
+		tick(3, 1045, 1), // range 55 → closes
+	)
+	if len(bars) != 1 || bars[0].High-bars[0].Low != 55 || bars[0].Close != 1045 || bars[0].Count != 4 {
+		t.Fatalf("unexpected bars: %+v", bars)
+	}
+}
+
+func TestRenkoBricks(t *testing.T) {
+	b := New([]int{model.BarTF(model.BarRenko, 100)})
+	bars := run(b,
+		tick(0, 1000, 5), // anchor
+		tick(1, 1250, 5), // two up bricks
+		tick(2, 1150, 5), // pullback inside reversal band
+		tick(3, 1000, 5), // reversal: 1100 → 1000
+	)
+	want := []struct{ open, close int64 }{{1000, 1100}, {1100, 1200}, {1100, 1000}}
+	if len(bars) != len(want) {
+		t.Fatalf("expected %d bricks, got %+v", len(want), bars)
+	}
+	for i, w := range want {
+		if bars[i].Open != w.open || bars[i].Close != w.close {
+			t.Errorf("brick %d: %d→%d, want %d→%d", i, bars[i].Open, bars[i].Close, w.open, w.close)
+		}
+	}
+	if !bars[1].TS.After(bars[0].TS) {
+		t.Error("bricks from one tick must have increasing timestamps")
+	}
+	if bars[0].Volume != 10 || bars[1].Volume != 0 || bars[2].Volume != 10 || bars[2].Count != 2 {
+		t.Errorf("volume since the previous brick goes to the first new brick: %+v", bars)
+	}
+}
+
+func TestBarTimestampsIncrease(t *testing.T) {
+	b := New([]int{model.BarTF(model.BarTick, 1)})
+	same := tick(0, 100, 1)
+	bars := run(b, same, same, tick(0, 101, 1))
+	if len(bars) != 3 {
+		t.Fatalf("expected 3 bars, got %d", len(bars))
+	}
+	for i := 1; i < len(bars); i++ {
+		if !bars[i].TS.After(bars[i-1].TS) {
+			t.Errorf("bar %d ts=%v not after %v", i, bars[i].TS, bars[i-1].TS)
+		}
+	}
+}
+
+func TestFlushSession(t *testing.T) {
+	b := New([]int{model.BarTF(model.BarTick, 100), model.BarTF(model.BarRenko, 100)})
+	outCh := make(chan model.TFCandle, 100)
+	b.RunTick(tick(0, 100, 5), outCh)
+	b.RunTick(tick(1, 105, 5), outCh)
+	for len(outCh) > 0 {
+		<-outCh
+	}
+
+	b.FlushSession(outCh)
+	if len(outCh) != 1 {
+		t.Fatalf("expected only the tick bar flushed, got %d", len(outCh))
+	}
+	if c := <-outCh; c.Forming || c.TF != model.BarTF(model.BarTick, 100) || c.Count != 2 {
+		t.Errorf("unexpected flush output: %+v", c)
+	}
+}
+
+func TestFlushSessionConcurrent(t *testing.T) {
+	b := New([]int{model.BarTF(model.BarTick, 5)})
+	outCh := make(chan model.TFCandle, 10000)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 1000; i++ {
+			b.RunTick(tick(i, 100+int64(i%7), 1), outCh)
+		}
+	}()
+	for i := 0; i < 10; i++ {
+		b.FlushSession(outCh)
+	}
+	<-done
+}
+
+func TestTFKey_BarSeries(t *testing.T) {
+	tests := map[int]string{
+		60:                                "60s",
+		model.BarTF(model.BarTick, 500):   "tick500",
+		model.BarTF(model.BarVolume, 1e5): "vol100000",
+		model.BarTF(model.BarRange, 1000): "range1000",
+		model.BarTF(model.BarRenko, 500):  "renko500",
+	}
+	for tf, key := range tests {
+		if got := model.TFKey(tf); got != key {
+			t.Errorf("TFKey(%d) = %q, want %q", tf, got, key)
+		}
+		if got, ok := model.ParseTFKey(key); !ok || got != tf {
+			t.Errorf("ParseTFKey(%q) = %d, %v; want %d", key, got, ok, tf)
+		}
+	}
+	for _, bad := range []string{"", "60", "tick", "tick-5", "bogus10"} {
+		if _, ok := model.ParseTFKey(bad); ok {
+			t.Errorf("ParseTFKey(%q) should fail", bad)
+		}
+	}
+	c := model.TFCandle{TF: model.BarTF(model.BarTick, 500), Exchange: "NSE", Token: "2885"}
+	if got := c.StreamKey(); got != "candle:tick500:NSE:2885" {
+		t.Errorf("stream key: %s", got)
+	}
+}
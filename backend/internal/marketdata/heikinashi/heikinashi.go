@@ -0,0 +1,63 @@
+// Package heikinashi derives Heikin-Ashi candles from TF candles.
+//
+// Each HA candle is published under its own series identity (model.HATF), so
+// it gets its own stream and channel, e.g. "candle:ha60s:NSE:2885", and the
+// indicator engine and gateway treat it like any other TF:
+//
+//	HA close = (open + high + low + close) / 4
+//	HA open  = (previous HA open + previous HA close) / 2, or (open + close) / 2 for the first
+//	HA high  = max(high, HA open, HA close)
+//	HA low   = min(low, HA open, HA close)
+//
+// Forming candles are derived from the last finalized HA candle without
+// advancing it, so the live candle converges on the one finalized at close.
+package heikinashi
+
+import "trading-systemv1/internal/model"
+
+// haState is the last finalized HA candle's body for one (token, TF) pair.
+type haState struct {
+	open, close int64
+}
+
+// Deriver turns TF candles into Heikin-Ashi candles.
+// Designed for single-goroutine usage (one consumer), like tfbuilder.
+type Deriver struct {
+	// Key structure: prev[tf][tokenKey] → last finalized HA body
+	prev map[int]map[string]haState
+}
+
+// New creates an empty Deriver.
+func New() *Deriver {
+	return &Deriver{prev: make(map[int]map[string]haState)}
+}
+
+// Derive returns the Heikin-Ashi candle for c. Finalized candles advance the
+// series; forming ones do not. Candles that already are HA are returned as is.
+func (d *Deriver) Derive(c model.TFCandle) model.TFCandle {
+	if model.IsHATF(c.TF) {
+		return c
+	}
+	byToken, ok := d.prev[c.TF]
+	if !ok {
+		byToken = make(map[string]haState, 64)
+		d.prev[c.TF] = byToken
+	}
+	key := c.Key()
+
+	ha := c
+	ha.TF = model.HATF(c.TF)
+	ha.Close = (c.Open + c.High + c.Low + c.Close + 2) / 4 // rounded to the nearest paisa
+	if p, ok := byToken[key]; ok {
+		ha.Open = (p.open + p.close + 1) / 2
+	} else {
+		ha.Open = (c.Open + c.Close + 1) / 2
+	}
+	ha.High = max(c.High, ha.Open, ha.Close)
+	ha.Low = min(c.Low, ha.Open, ha.Close)
+
+	if !c.Forming {
+		byToken[key] = haState{open: ha.Open, close: ha.Close}
+	}
+	return ha
+}
@@ -0,0 +1,99 @@
+package heikinashi
+
+import (
+	"testing"
+	"time"
+
+	"trading-systemv1/internal/model"
+)
+
+func tfCandle(min int, o, h, l, c int64, forming bool) model.TFCandle {
+	return model.TFCandle{
+		Token: "2885", Exchange: "NSE", TF: 60,
+		TS:   time.Date(2026, 1, 5, 3, 45+min, 0, 0, time.UTC),
+		Open: o, High: h, Low: l, Close: c,
+		Volume: 100, Count: 60, Forming: forming,
+	}
+}
+
+func TestDerive(t *testing.T) {
+	d := New()
+
+	first := d.Derive(tfCandle(0, 1000, 1100, 900, 1050, false))
+	if first.TF != model.HATF(60) || model.TFKey(first.TF) != "ha60s" {
+		t.Fatalf("HA series identity: tf=%d key=%s", first.TF, model.TFKey(first.TF))
+	}
+	// close = (1000+1100+900+1050)/4 = 1012.5 → 1013, open = (1000+1050)/2 = 1025
+	if first.Open != 1025 || first.Close != 1013 || first.High != 1100 || first.Low != 900 {
+		t.Errorf("first HA candle: %+v", first)
+	}
+	if first.Volume != 100 || first.Count != 60 || !first.TS.Equal(tfCandle(0, 0, 0, 0, 0, false).TS) {
+		t.Errorf("volume, count and TS must carry over: %+v", first)
+	}
+
+	// Forming candles use the last finalized HA body without advancing it.
+	for i := 0; i < 2; i++ {
+		f := d.Derive(tfCandle(1, 1050, 1060, 1040, 1055, true))
+		if f.Open != 1019 || !f.Forming { // (1025+1013)/2
+			t.Fatalf("forming HA open = %d, want 1019", f.Open)
+		}
+	}
+
+	second := d.Derive(tfCandle(1, 1050, 1200, 1040, 1180, false))
+	// open = 1019, close = (1050+1200+1040+1180)/4 = 1117.5 → 1118
+	if second.Open != 1019 || second.Close != 1118 || second.High != 1200 || second.Low != 1019 {
+		t.Errorf("second HA candle: %+v", second)
+	}
+
+	third := d.Derive(tfCandle(2, 1180, 1190, 1170, 1185, false))
+	if third.Open != 1069 { // (1019+1118+1)/2
+		t.Errorf("third HA open = %d, want 1069", third.Open)
+	}
+}
+
+func TestDerive_SeriesAreIndependent(t *testing.T) {
+	d := New()
+	d.Derive(tfCandle(0, 1000, 1100, 900, 1050, false))
+
+	other := tfCandle(0, 500, 600, 400, 550, false)
+	other.Token = "99926000"
+	if ha := d.Derive(other); ha.Open != 525 {
+		t.Errorf("another token starts its own series, got open=%d", ha.Open)
+	}
+
+	fiveMin := tfCandle(0, 500, 600, 400, 550, false)
+	fiveMin.TF = 300
+	if ha := d.Derive(fiveMin); ha.Open != 525 || ha.TF != model.HATF(300) {
+		t.Errorf("another TF starts its own series, got %+v", ha)
+	}
+
+	if ha := d.Derive(d.Derive(fiveMin)); ha.TF != model.HATF(300) {
+		t.Errorf("HA candles pass through unchanged, got tf=%d", ha.TF)
+	}
+}
+
+func TestTFKey_HeikinAshi(t *testing.T) {
+	tests := map[int]string{
+		model.HATF(60):    "ha60s",
+		model.HATF(86400): "ha86400s",
+		model.HATF(model.BarTF(model.BarRenko, 500)): "harenko500",
+	}
+	for tf, key := range tests {
+		if got := model.TFKey(tf); got != key {
+			t.Errorf("TFKey(%d) = %q, want %q", tf, got, key)
+		}
+		if got, ok := model.ParseTFKey(key); !ok || got != tf {
+			t.Errorf("ParseTFKey(%q) = %d, %v; want %d", key, got, ok, tf)
+		}
+	}
+	if _, ok := model.ParseTFKey("haha60s"); ok {
+		t.Error("nested HA keys must not parse")
+	}
+	renkoHA := model.HATF(model.BarTF(model.BarRenko, 500))
+	if !model.IsBarTF(renkoHA) || model.BaseTF(renkoHA) != model.BarTF(model.BarRenko, 500) {
+		t.Error("HA of a bar series is still a bar series")
+	}
+	if model.IsBarTF(model.HATF(60)) || model.BaseTF(model.HATF(60)) != 60 {
+		t.Error("HA of a time TF is a time series")
+	}
+}
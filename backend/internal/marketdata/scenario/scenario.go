@@ -0,0 +1,277 @@
+// Package scenario generates scripted, seeded market sessions for the
+// tickserver: a JSON file lists instruments and a sequence of phases (trend,
+// mean reversion, volatility spike, gap open, circuit freeze, late and
+// out-of-order ticks, feed stall, post-close settling) and Generate turns it
+// into a tick stream.
+//
+// Generation is fully deterministic: the same scenario and seed always yield
+// the same ticks, so tests can assert exact candles. Each instrument draws
+// from its own random source (seed + index), so adding an instrument does not
+// change the others.
+//
+// Ticks carry two times: EventTS is when the trade happened on the simulated
+// exchange and TickTS when it reaches the feed. They only differ inside a
+// "disorder" phase, where some ticks are delivered late.
+package scenario
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"sort"
+	"time"
+
+	"trading-systemv1/internal/model"
+)
+
+// Phase kinds.
+const (
+	KindTrend    = "trend"    // linear drift plus noise
+	KindRevert   = "revert"   // noise pulled back toward the phase's opening price
+	KindSpike    = "spike"    // high-volatility noise with heavier volume
+	KindGap      = "gap"      // no ticks for Duration, then the price jumps by GapBps
+	KindFreeze   = "freeze"   // price pinned at a circuit limit LimitBps from the phase's opening price
+	KindDisorder = "disorder" // noise with a share of ticks delivered late (out of order)
+	KindStall    = "stall"    // feed silent for Duration
+	KindSettle   = "settle"   // post-close: noise decays to zero, then a constant price for StableFor
+)
+
+// Default per-tick noise (standard deviation, basis points) by phase kind.
+const (
+	defaultVolBps      = 2.0
+	defaultSpikeVolBps = 25.0
+	defaultTickSize    = 5 // paise (₹0.05)
+	defaultStableFor   = time.Minute
+)
+
+// Scenario is the JSON scenario file.
+type Scenario struct {
+	Seed        int64        `json:"seed"`
+	Start       time.Time    `json:"start"`      // time of the first tick (RFC3339); zero = caller decides
+	TickEvery   Duration     `json:"tick_every"` // tick spacing per instrument (default 250ms)
+	Instruments []Instrument `json:"instruments"`
+	Phases      []Phase      `json:"phases"`
+}
+
+// Instrument is one simulated instrument and its opening price.
+type Instrument struct {
+	Token    string `json:"token"`
+	Exchange string `json:"exchange"`
+	Price    int64  `json:"price"`     // paise
+	TickSize int64  `json:"tick_size"` // paise (default 5)
+}
+
+// Phase is one scripted stretch of the session. Fields that do not apply to
+// a kind are ignored.
+type Phase struct {
+	Kind      string   `json:"kind"`
+	Duration  Duration `json:"duration"`
+	DriftBps  float64  `json:"drift_bps"`  // trend: drift per minute, relative to the phase's opening price
+	VolBps    float64  `json:"vol_bps"`    // per-tick noise (default 2, spike 25)
+	Revert    float64  `json:"revert"`     // revert: share of the deviation pulled back per tick (0..1)
+	GapBps    float64  `json:"gap_bps"`    // gap: price jump
+	LimitBps  float64  `json:"limit_bps"`  // freeze: circuit band; positive = upper circuit
+	LateRatio float64  `json:"late_ratio"` // disorder: share of ticks delivered late (0..1)
+	LateBy    Duration `json:"late_by"`    // disorder: maximum delivery delay, 0 or >= 1ms
+	StableFor Duration `json:"stable_for"` // settle: final constant-price stretch (default 1m)
+}
+
+// Duration is a time.Duration that reads JSON strings such as "90s" or "5m".
+type Duration time.Duration
+
+// UnmarshalJSON parses a Go duration string.
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("duration must be a string like \"5m\": %s", b)
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// MarshalJSON writes the duration as a Go duration string.
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// Load reads and validates a scenario file.
+func Load(path string) (*Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var s Scenario
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("scenario %s: %w", path, err)
+	}
+	if err := s.Validate(); err != nil {
+		return nil, fmt.Errorf("scenario %s: %w", path, err)
+	}
+	return &s, nil
+}
+
+// Validate checks the scenario and fills in defaults.
+func (s *Scenario) Validate() error {
+	if s.TickEvery == 0 {
+		s.TickEvery = Duration(250 * time.Millisecond)
+	}
+	if s.TickEvery < 0 {
+		return fmt.Errorf("tick_every must be positive")
+	}
+	if len(s.Instruments) == 0 {
+		return fmt.Errorf("no instruments")
+	}
+	for i := range s.Instruments {
+		in := &s.Instruments[i]
+		if in.Token == "" || in.Exchange == "" || in.Price <= 0 {
+			return fmt.Errorf("instrument %d: token, exchange and a positive price are required", i)
+		}
+		if in.TickSize <= 0 {
+			in.TickSize = defaultTickSize
+		}
+	}
+	for i := range s.Phases {
+		p := &s.Phases[i]
+		switch p.Kind {
+		case KindTrend, KindRevert, KindDisorder:
+			if p.VolBps == 0 {
+				p.VolBps = defaultVolBps
+			}
+		case KindSpike:
+			if p.VolBps == 0 {
+				p.VolBps = defaultSpikeVolBps
+			}
+		case KindSettle:
+			if p.VolBps == 0 {
+				p.VolBps = defaultVolBps
+			}
+			if p.StableFor == 0 {
+				p.StableFor = Duration(defaultStableFor)
+			}
+		case KindGap, KindFreeze, KindStall:
+		default:
+			return fmt.Errorf("phase %d: unknown kind %q", i, p.Kind)
+		}
+		if p.Duration < 0 || p.LateBy < 0 || p.StableFor < 0 {
+			return fmt.Errorf("phase %d (%s): durations must not be negative", i, p.Kind)
+		}
+		if p.LateBy > 0 && time.Duration(p.LateBy) < time.Millisecond {
+			// Delays are drawn in whole milliseconds.
+			return fmt.Errorf("phase %d (%s): late_by must be 0 or at least 1ms", i, p.Kind)
+		}
+		if p.Revert < 0 || p.Revert > 1 || p.LateRatio < 0 || p.LateRatio > 1 {
+			return fmt.Errorf("phase %d (%s): revert and late_ratio must be within 0..1", i, p.Kind)
+		}
+	}
+	return nil
+}
+
+// End returns the time just after the last phase.
+func (s *Scenario) End() time.Time {
+	end := s.Start
+	for _, p := range s.Phases {
+		end = end.Add(time.Duration(p.Duration))
+	}
+	return end
+}
+
+// Generate returns the scenario's ticks for all instruments in delivery
+// order (TickTS, then instrument order). s must have been validated.
+func Generate(s *Scenario) []model.Tick {
+	var all []model.Tick
+	for i, in := range s.Instruments {
+		all = append(all, generateOne(s, in, rand.New(rand.NewSource(s.Seed+int64(i))))...)
+	}
+	sort.SliceStable(all, func(i, j int) bool {
+		return all[i].TickTS.Before(all[j].TickTS)
+	})
+	return all
+}
+
+// generateOne walks one instrument through every phase.
+func generateOne(s *Scenario, in Instrument, rng *rand.Rand) []model.Tick {
+	every := time.Duration(s.TickEvery)
+	perMinute := float64(every) / float64(time.Minute)
+	price := float64(in.Price)
+	ts := s.Start
+
+	var ticks []model.Tick
+	emit := func(at time.Time, p float64, qty int64, delay time.Duration) {
+		t := model.Tick{
+			Token:    in.Token,
+			Exchange: in.Exchange,
+			Price:    roundTo(p, in.TickSize),
+			Qty:      qty,
+			TickTS:   at.Add(delay),
+			EventTS:  at,
+		}
+		ticks = append(ticks, t)
+	}
+	noise := func(p, volBps float64) float64 {
+		return p * volBps / 1e4 * rng.NormFloat64()
+	}
+
+	for _, ph := range s.Phases {
+		dur := time.Duration(ph.Duration)
+		n := int(dur / every)
+		open := price
+
+		switch ph.Kind {
+		case KindStall:
+		case KindGap:
+			price *= 1 + ph.GapBps/1e4
+		case KindFreeze:
+			price = open * (1 + ph.LimitBps/1e4)
+			for k := 0; k < n; k++ {
+				emit(ts.Add(time.Duration(k)*every), price, int64(rng.Intn(5)+1), 0)
+			}
+		case KindSettle:
+			moving := n - int(time.Duration(ph.StableFor)/every)
+			for k := 0; k < n; k++ {
+				if k < moving {
+					decay := 1 - float64(k)/float64(moving)
+					price += noise(price, ph.VolBps*decay)
+				} else if k == moving {
+					price = float64(roundTo(price, in.TickSize)) // pin to a tradable price
+				}
+				emit(ts.Add(time.Duration(k)*every), price, int64(rng.Intn(20)+1), 0)
+			}
+		default: // trend, revert, spike, disorder
+			qtyMax := 100
+			if ph.Kind == KindSpike {
+				qtyMax = 500
+			}
+			for k := 0; k < n; k++ {
+				price += open*ph.DriftBps/1e4*perMinute + noise(price, ph.VolBps)
+				if ph.Kind == KindRevert {
+					price += (open - price) * ph.Revert
+				}
+				var delay time.Duration
+				if ph.Kind == KindDisorder && rng.Float64() < ph.LateRatio && ph.LateBy > 0 {
+					delay = time.Duration(rng.Int63n(int64(time.Duration(ph.LateBy)/time.Millisecond))+1) * time.Millisecond
+				}
+				emit(ts.Add(time.Duration(k)*every), price, int64(rng.Intn(qtyMax)+1), delay)
+			}
+		}
+		if price < float64(in.TickSize) {
+			price = float64(in.TickSize)
+		}
+		ts = ts.Add(dur)
+	}
+	return ticks
+}
+
+// roundTo rounds p to the nearest multiple of step (at least one step).
+func roundTo(p float64, step int64) int64 {
+	r := int64(math.Round(p/float64(step))) * step
+	if r < step {
+		r = step
+	}
+	return r
+}
@@ -0,0 +1,209 @@
+package scenario
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"trading-systemv1/internal/marketdata/agg"
+	"trading-systemv1/internal/marketdata/closedetector"
+	"trading-systemv1/internal/markethours"
+	"trading-systemv1/internal/model"
+)
+
+func parse(t *testing.T, js string) *Scenario {
+	t.Helper()
+	var s Scenario
+	if err := json.Unmarshal([]byte(js), &s); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	return &s
+}
+
+// aggregate runs ticks (in delivery order) through the 1s aggregator.
+func aggregate(ticks []model.Tick) (candles []model.Candle, late int) {
+	tickCh := make(chan model.Tick, len(ticks))
+	for _, tk := range ticks {
+		tickCh <- tk
+	}
+	close(tickCh)
+	candleCh := make(chan model.Candle, len(ticks)+1)
+	a := agg.New()
+	a.OnLateTick = func() { late++ }
+	a.Run(context.Background(), tickCh, candleCh)
+	close(candleCh)
+	for c := range candleCh {
+		candles = append(candles, c)
+	}
+	return candles, late
+}
+
+const sessionJSON = `{
+	"seed": 7,
+	"start": "2026-01-05T15:25:00+05:30",
+	"tick_every": "250ms",
+	"instruments": [{"token": "2885", "exchange": "NSE", "price": 250000}],
+	"phases": [
+		{"kind": "trend", "duration": "2s", "drift_bps": 600, "vol_bps": 1},
+		{"kind": "freeze", "duration": "2s", "limit_bps": 500},
+		{"kind": "stall", "duration": "3s"},
+		{"kind": "disorder", "duration": "3s", "late_ratio": 0.5, "late_by": "3s"},
+		{"kind": "settle", "duration": "7m", "stable_for": "2m"}
+	]
+}`
+
+func TestGenerate_Deterministic(t *testing.T) {
+	a := Generate(parse(t, sessionJSON))
+	b := Generate(parse(t, sessionJSON))
+	if !reflect.DeepEqual(a, b) {
+		t.Fatal("same scenario and seed should generate identical ticks")
+	}
+	s := parse(t, sessionJSON)
+	s.Seed++
+	if reflect.DeepEqual(a, Generate(s)) {
+		t.Error("a different seed should change the ticks")
+	}
+}
+
+func TestGenerate_ExactCandles(t *testing.T) {
+	s := parse(t, sessionJSON)
+	ticks := Generate(s)
+	candles, late := aggregate(ticks)
+
+	got := make([]string, 0, 4)
+	for _, c := range candles[:4] {
+		got = append(got, fmt.Sprintf("%s %d/%d/%d/%d v%d",
+			c.TS.In(markethours.IST).Format("15:04:05"), c.Open, c.High, c.Low, c.Close, c.Volume))
+	}
+	want := []string{
+		"15:25:00 250055/250325/250055/250325 v147",
+		"15:25:01 250390/250675/250390/250675 v231",
+		"15:25:02 263210/263210/263210/263210 v12", // upper circuit: 5% above the phase open
+		"15:25:03 263210/263210/263210/263210 v13",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("candles:\n got %q\nwant %q", got, want)
+	}
+
+	// Stall: nothing between the freeze and the disorder phase.
+	for _, c := range candles {
+		if sec := c.TS.In(markethours.IST).Second(); c.TS.In(markethours.IST).Minute() == 25 && sec >= 4 && sec < 7 {
+			t.Errorf("candle during the stall: %+v", c)
+		}
+	}
+	// Disorder: some ticks arrive after later ones, and those behind the watermark are dropped.
+	outOfOrder := 0
+	for i := 1; i < len(ticks); i++ {
+		if ticks[i].EventTS.Before(ticks[i-1].EventTS) {
+			outOfOrder++
+		}
+	}
+	if outOfOrder == 0 || late == 0 {
+		t.Errorf("expected out-of-order and late ticks, got %d and %d", outOfOrder, late)
+	}
+}
+
+func TestGenerate_SettleCapturesClose(t *testing.T) {
+	s := parse(t, sessionJSON)
+	ticks := Generate(s)
+
+	d := closedetector.New(time.Date(2026, time.January, 5, 15, 30, 0, 0, markethours.IST))
+	var closed bool
+	var at time.Time
+	for _, tk := range ticks {
+		if d.Observe(tk.Price, tk.TickTS) {
+			closed, at = true, tk.TickTS
+			break
+		}
+	}
+	last := ticks[len(ticks)-1]
+	if !closed || d.ClosingPrice() != last.Price {
+		t.Fatalf("closedetector should capture the settled price %d, got %d (closed=%v)", last.Price, d.ClosingPrice(), closed)
+	}
+	if end := s.End(); at.After(end) || at.Before(end.Add(-2*time.Minute)) {
+		t.Errorf("close captured at %v, want within the final stable stretch before %v", at, end)
+	}
+}
+
+func TestGenerate_GapAndRevert(t *testing.T) {
+	s := parse(t, `{
+		"seed": 1, "start": "2026-01-05T09:15:00+05:30", "tick_every": "1s",
+		"instruments": [{"token": "1594", "exchange": "NSE", "price": 100000}, {"token": "2885", "exchange": "NSE", "price": 50000}],
+		"phases": [
+			{"kind": "gap", "duration": "10s", "gap_bps": 200},
+			{"kind": "revert", "duration": "1m", "revert": 0.5, "vol_bps": 5},
+			{"kind": "spike", "duration": "10s"}
+		]
+	}`)
+	ticks := Generate(s)
+	if len(ticks) != 2*70 {
+		t.Fatalf("expected 140 ticks, got %d", len(ticks))
+	}
+	first := ticks[0]
+	if !first.TickTS.Equal(time.Date(2026, time.January, 5, 9, 15, 10, 0, markethours.IST)) {
+		t.Errorf("first tick at %v, want after the 10s gap", first.TickTS)
+	}
+	if first.Token != "1594" || first.Price < 101900 || first.Price > 102100 {
+		t.Errorf("first tick should open ~2%% higher: %+v", first)
+	}
+	for _, tk := range ticks[:120] {
+		if tk.Token == "1594" && (tk.Price < 101500 || tk.Price > 102500) {
+			t.Errorf("revert phase strayed from the opening price: %+v", tk)
+		}
+	}
+
+	// An instrument's ticks do not depend on the others.
+	solo := *s
+	solo.Instruments = solo.Instruments[:1]
+	var only []model.Tick
+	for _, tk := range ticks {
+		if tk.Token == "1594" {
+			only = append(only, tk)
+		}
+	}
+	if !reflect.DeepEqual(only, Generate(&solo)) {
+		t.Error("adding an instrument changed another instrument's ticks")
+	}
+}
+
+func TestGenerate_MinimumLateBy(t *testing.T) {
+	s := parse(t, `{
+		"seed": 1,
+		"start": "2026-01-05T10:00:00+05:30",
+		"instruments": [{"token": "1", "exchange": "NSE", "price": 100}],
+		"phases": [{"kind": "disorder", "duration": "2s", "late_ratio": 1, "late_by": "1ms"}]
+	}`)
+	for _, tk := range Generate(s) {
+		if d := tk.TickTS.Sub(tk.EventTS); d != time.Millisecond {
+			t.Fatalf("tick delayed by %v, want 1ms", d)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, js := range []string{
+		`{"instruments": []}`,
+		`{"instruments": [{"token": "1", "exchange": "NSE"}]}`,
+		`{"instruments": [{"token": "1", "exchange": "NSE", "price": 100}], "phases": [{"kind": "moon"}]}`,
+		`{"instruments": [{"token": "1", "exchange": "NSE", "price": 100}], "phases": [{"kind": "revert", "revert": 2}]}`,
+		`{"instruments": [{"token": "1", "exchange": "NSE", "price": 100}], "phases": [{"kind": "disorder", "late_ratio": 1, "late_by": "500us"}]}`,
+	} {
+		var s Scenario
+		if err := json.Unmarshal([]byte(js), &s); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.Validate(); err == nil {
+			t.Errorf("expected validation error for %s", js)
+		}
+	}
+	var d Duration
+	if err := json.Unmarshal([]byte(`90`), &d); err == nil {
+		t.Error("numeric durations should be rejected")
+	}
+}
@@ -0,0 +1,217 @@
+package tfbuilder
+
+import (
+	"testing"
+	"time"
+
+	"trading-systemv1/internal/markethours"
+	"trading-systemv1/internal/model"
+)
+
+// istCandle creates a 1s candle at the given IST wall-clock time.
+func istCandle(year int, month time.Month, day, hour, min int, price int64) model.Candle {
+	ts := time.Date(year, month, day, hour, min, 0, 0, markethours.IST)
+	return makeCandle("NIFTY", ts.Unix(), price, price+10, price-10, price+5, 1)
+}
+
+// finalized drains outCh and returns the finalized candles by TF.
+func finalized(outCh chan model.TFCandle) map[int][]model.TFCandle {
+	out := make(map[int][]model.TFCandle)
+	for len(outCh) > 0 {
+		if c := <-outCh; !c.Forming {
+			out[c.TF] = append(out[c.TF], c)
+		}
+	}
+	return out
+}
+
+func TestBuilder_75m_AnchoredToSessionOpen(t *testing.T) {
+	b := New([]int{4500})
+	b.StaleTolerance = 0
+	outCh := make(chan model.TFCandle, 100)
+
+	b.process(istCandle(2026, time.January, 5, 9, 15, 100), outCh)
+	b.process(istCandle(2026, time.January, 5, 10, 29, 110), outCh)
+	b.process(istCandle(2026, time.January, 5, 10, 30, 120), outCh) // second bucket
+
+	got := finalized(outCh)[4500]
+	if len(got) != 1 {
+		t.Fatalf("expected 1 finalized 75m candle, got %d", len(got))
+	}
+	want := time.Date(2026, time.January, 5, 9, 15, 0, 0, markethours.IST)
+	if !got[0].TS.Equal(want) {
+		t.Errorf("75m bucket starts at %s, want 9:15 IST", got[0].TS.In(markethours.IST))
+	}
+	if got[0].Open != 100 || got[0].Close != 115 || got[0].Count != 2 {
+		t.Errorf("unexpected candle: %+v", got[0])
+	}
+
+	// The last bucket of the day (14:15) is cut short by the close.
+	if start := markethours.BucketStart(istCandle(2026, time.January, 5, 15, 29, 0).TS.Unix(), 4500); start !=
+		time.Date(2026, time.January, 5, 14, 15, 0, 0, markethours.IST).Unix() {
+		t.Errorf("15:29 falls in bucket %s, want 14:15", time.Unix(start, 0).In(markethours.IST))
+	}
+}
+
+func TestBuilder_DailyFinalizesAtSessionFlush(t *testing.T) {
+	b := New([]int{60, markethours.TFDay})
+	b.StaleTolerance = 0
+	outCh := make(chan model.TFCandle, 100)
+
+	b.process(istCandle(2026, time.January, 5, 9, 15, 100), outCh)
+	b.process(istCandle(2026, time.January, 5, 12, 0, 90), outCh)
+	b.process(istCandle(2026, time.January, 5, 15, 29, 120), outCh)
+	if got := finalized(outCh)[markethours.TFDay]; len(got) != 0 {
+		t.Fatalf("daily candle finalized mid-session: %+v", got)
+	}
+
+	b.FlushSession(outCh)
+	got := finalized(outCh)
+	if len(got[60]) != 1 {
+		t.Errorf("expected the forming 1m candle flushed, got %d", len(got[60]))
+	}
+	daily := got[markethours.TFDay]
+	if len(daily) != 1 {
+		t.Fatalf("expected 1 daily candle, got %d", len(daily))
+	}
+	d := daily[0]
+	if !d.TS.Equal(time.Date(2026, time.January, 5, 9, 15, 0, 0, markethours.IST)) {
+		t.Errorf("daily TS %s, want the session open", d.TS)
+	}
+	if d.Open != 100 || d.High != 130 || d.Low != 80 || d.Close != 125 || d.Count != 3 {
+		t.Errorf("unexpected daily candle: %+v", d)
+	}
+}
+
+func TestBuilder_WeeklyAndMonthlySpanSessions(t *testing.T) {
+	b := New([]int{markethours.TFDay, markethours.TFWeek, markethours.TFMonth})
+	b.StaleTolerance = 0
+	outCh := make(chan model.TFCandle, 100)
+
+	// Wed 8 Apr 2026: mid-week — only the daily candle ends.
+	b.process(istCandle(2026, time.April, 8, 10, 0, 100), outCh)
+	b.FlushSession(outCh)
+	got := finalized(outCh)
+	if len(got[markethours.TFDay]) != 1 || len(got[markethours.TFWeek]) != 0 || len(got[markethours.TFMonth]) != 0 {
+		t.Fatalf("mid-week flush: got D=%d W=%d M=%d, want 1/0/0",
+			len(got[markethours.TFDay]), len(got[markethours.TFWeek]), len(got[markethours.TFMonth]))
+	}
+
+	// Thu 9 Apr: Good Friday (10 Apr) is a holiday, so Thursday ends the week.
+	b.process(istCandle(2026, time.April, 9, 10, 0, 200), outCh)
+	b.FlushSession(outCh)
+	weekly := finalized(outCh)[markethours.TFWeek]
+	if len(weekly) != 1 {
+		t.Fatalf("expected the weekly candle at Thursday's close, got %d", len(weekly))
+	}
+	w := weekly[0]
+	if !w.TS.Equal(time.Date(2026, time.April, 7, 9, 15, 0, 0, markethours.IST)) {
+		t.Errorf("weekly TS %s, want Tuesday's open (Monday 6 Apr is a holiday)", w.TS.In(markethours.IST))
+	}
+	if w.Open != 100 || w.Close != 205 || w.Count != 2 {
+		t.Errorf("weekly candle did not span both sessions: %+v", w)
+	}
+
+	// Thu 30 Apr: last trading day of the month (1 May is a holiday).
+	b.process(istCandle(2026, time.April, 30, 10, 0, 300), outCh)
+	b.FlushSession(outCh)
+	monthly := finalized(outCh)[markethours.TFMonth]
+	if len(monthly) != 1 || monthly[0].Open != 100 || monthly[0].Count != 3 {
+		t.Fatalf("expected one monthly candle spanning April, got %+v", monthly)
+	}
+}
+
+func TestWeekAndMonthOpen_SkipHolidays(t *testing.T) {
+	ist := func(month time.Month, day, hour, min int) time.Time {
+		return time.Date(2026, month, day, hour, min, 0, 0, markethours.IST)
+	}
+	tests := []struct {
+		name string
+		got  time.Time
+		want time.Time
+	}{
+		// Mon 26 Jan 2026 is Republic Day: the week opens on Tuesday.
+		{"holiday Monday", markethours.WeekOpen(ist(time.January, 29, 11, 0)), ist(time.January, 27, 9, 15)},
+		{"regular Monday", markethours.WeekOpen(ist(time.January, 8, 11, 0)), ist(time.January, 5, 9, 15)},
+		// Fri 1 May 2026 is Maharashtra Day, then a weekend: May opens on Monday the 4th.
+		{"holiday 1st", markethours.MonthOpen(ist(time.May, 20, 11, 0)), ist(time.May, 4, 9, 15)},
+		{"weekend 1st", markethours.MonthOpen(ist(time.February, 10, 11, 0)), ist(time.February, 2, 9, 15)},
+		{"trading 1st", markethours.MonthOpen(ist(time.April, 20, 11, 0)), ist(time.April, 1, 9, 15)},
+	}
+	for _, tt := range tests {
+		if !tt.got.Equal(tt.want) {
+			t.Errorf("%s: got %s, want %s", tt.name, tt.got.In(markethours.IST), tt.want)
+		}
+	}
+
+	weekly := markethours.BucketStart(ist(time.January, 29, 11, 0).Unix(), markethours.TFWeek)
+	if weekly != ist(time.January, 27, 9, 15).Unix() {
+		t.Errorf("weekly bucket starts at %s, want Tue 27 Jan", time.Unix(weekly, 0).In(markethours.IST))
+	}
+}
+
+func TestBucketStart_MatchesSessionOpen(t *testing.T) {
+	// Every 7 minutes over a week, around midnight and the open included
+	from := time.Date(2026, time.January, 4, 0, 3, 0, 0, markethours.IST).Unix()
+	for ts := from; ts < from+7*86400; ts += 7*60 + 1 {
+		open := markethours.SessionOpen(time.Unix(ts, 0)).Unix()
+		if got := markethours.BucketStart(ts, markethours.TFDay); got != open {
+			t.Fatalf("D bucket of %s = %s, want %s", time.Unix(ts, 0).In(markethours.IST),
+				time.Unix(got, 0).In(markethours.IST), time.Unix(open, 0).In(markethours.IST))
+		}
+		anchor := open
+		if ts < anchor {
+			anchor -= 86400
+		}
+		if got, want := markethours.BucketStart(ts, 4500), anchor+(ts-anchor)/4500*4500; got != want {
+			t.Fatalf("75m bucket of %s = %s, want %s", time.Unix(ts, 0).In(markethours.IST),
+				time.Unix(got, 0).In(markethours.IST), time.Unix(want, 0).In(markethours.IST))
+		}
+	}
+}
+
+func TestParseTF(t *testing.T) {
+	tests := map[string]int{
+		"300": 300, "4500": 4500,
+		"D": markethours.TFDay, "1d": markethours.TFDay,
+		"W": markethours.TFWeek, "M": markethours.TFMonth, "1M": markethours.TFMonth, "MN": markethours.TFMonth,
+		"1m": 60, "5m": 300, "75m": 4500, "1h": 3600, "2H": 7200, "45s": 45,
+		"tick500": model.BarTF(model.BarTick, 500), "HA 5m": model.HATF(300),
+	}
+	for in, want := range tests {
+		if got, err := markethours.ParseTF(in); err != nil || got != want {
+			t.Errorf("ParseTF(%q) = %d, %v; want %d", in, got, err, want)
+		}
+	}
+	for _, bad := range []string{"", "0", "-60", "Y", "m", "0m", "5M", "HA HA 5m"} {
+		if _, err := markethours.ParseTF(bad); err == nil {
+			t.Errorf("ParseTF(%q) should fail", bad)
+		}
+	}
+	if got := markethours.TFLabel(4500); got != "75m" {
+		t.Errorf("TFLabel(4500) = %q, want 75m", got)
+	}
+}
+
+func TestParseTF_LabelRoundTrip(t *testing.T) {
+	tfs := []int{
+		1, 15, 45, 60, 120, 180, 300, 900, 1800, 3600, 4500, 7200, 14400,
+		markethours.TFDay, markethours.TFWeek, markethours.TFMonth,
+		model.BarTF(model.BarTick, 500), model.BarTF(model.BarVolume, 100000),
+		model.BarTF(model.BarRange, 1000), model.BarTF(model.BarRenko, 500),
+	}
+	for _, tf := range append(tfs[:len(tfs):len(tfs)], haOf(tfs)...) {
+		label := markethours.TFLabel(tf)
+		if got, err := markethours.ParseTF(label); err != nil || got != tf {
+			t.Errorf("ParseTF(TFLabel(%d) = %q) = %d, %v", tf, label, got, err)
+		}
+	}
+}
+
+func haOf(tfs []int) []int {
+	out := make([]int, len(tfs))
+	for i, tf := range tfs {
+		out[i] = model.HATF(tf)
+	}
+	return out
+}
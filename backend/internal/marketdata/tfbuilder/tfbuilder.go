@@ -3,6 +3,11 @@
 // states that are updated in O(1) per candle per TF. When a TF bucket
 // closes (i.e., a candle arrives in a new bucket), the previous TF candle
 // is finalized and emitted.
+//
+// Buckets come from markethours.BucketStart: intraday TFs are anchored to
+// the 9:15 IST open, and the calendar TFs (markethours.TFDay, TFWeek,
+// TFMonth) span a session, week or month and are finalized by FlushSession
+// at the close that ends them.
 package tfbuilder
 
 import (
@@ -10,14 +15,17 @@ import (
 	"log"
 	"time"
 
+	"trading-systemv1/internal/markethours"
 	"trading-systemv1/internal/model"
 )
 
 // tfState holds the forming candle state for one (token, TF) pair.
 type tfState struct {
-	bucket  int64 // bucket start = ts - ts%tf (Unix seconds)
+	bucket  int64 // bucket start = markethours.BucketStart(ts, tf) (Unix seconds)
 	candle  model.TFCandle
 	started bool
+
+	first, last int64 // earliest and latest 1s candle merged (Unix seconds)
 }
 
 // Builder resamples 1s candles into multiple dynamic timeframes.
@@ -29,10 +37,25 @@ type Builder struct {
 	// Key structure: states[tfIdx][tokenKey] → *tfState
 	states []map[string]*tfState
 
+	lastTS time.Time // latest 1s candle seen, dates the session FlushSession closes
+
 	// Staleness validation: reject candles older than bucket_start - tolerance.
 	// Default: 2s. Set to 0 to disable.
 	StaleTolerance time.Duration
 
+	// AmendWindow enables amendments: a revised 1s candle (Revision > 0) up
+	// to AmendWindow old is applied to its TF candle. A forming candle emits
+	// a new snapshot; a finalized one is emitted again with its Revision
+	// incremented. 0 disables amendments (default), and revisions are dropped.
+	AmendWindow time.Duration
+
+	// Candles kept for amendments while within AmendWindow: the latest
+	// version of each 1s candle, recent1s[tokenKey], and the finalized TF
+	// candles, closed[tfIdx][tokenKey][bucket]. Both are pruned per token
+	// as that token's candles arrive, so each holds at most one window.
+	recent1s map[string]*recentCandles
+	closed   []map[string]map[int64]*tfState
+
 	// Metrics hooks
 	OnTFCandle    func(c model.TFCandle) // called on finalized TF candle (optional)
 	OnStaleCandle func()                 // called when a stale candle is rejected (optional)
@@ -41,12 +64,16 @@ type Builder struct {
 // New creates a TF builder with the given timeframes (in seconds).
 func New(tfs []int) *Builder {
 	states := make([]map[string]*tfState, len(tfs))
+	closed := make([]map[string]map[int64]*tfState, len(tfs))
 	for i := range states {
 		states[i] = make(map[string]*tfState, 64) // preallocate for ~64 tokens
+		closed[i] = make(map[string]map[int64]*tfState)
 	}
 	return &Builder{
 		tfs:            tfs,
 		states:         states,
+		recent1s:       make(map[string]*recentCandles),
+		closed:         closed,
 		StaleTolerance: 2 * time.Second, // default: reject candles > 2s stale
 	}
 }
@@ -74,17 +101,22 @@ func (b *Builder) UpdateTFs(newTFs []int, outCh chan<- model.TFCandle) {
 
 	// Rebuild states: keep existing states for TFs that persist, add new ones
 	oldStates := make(map[int]map[string]*tfState, len(b.tfs))
+	oldClosed := make(map[int]map[string]map[int64]*tfState, len(b.tfs))
 	for i, tf := range b.tfs {
 		oldStates[tf] = b.states[i]
+		oldClosed[tf] = b.closed[i]
 	}
 
 	b.tfs = newTFs
 	b.states = make([]map[string]*tfState, len(newTFs))
+	b.closed = make([]map[string]map[int64]*tfState, len(newTFs))
 	for i, tf := range newTFs {
 		if old, ok := oldStates[tf]; ok {
 			b.states[i] = old
+			b.closed[i] = oldClosed[tf]
 		} else {
 			b.states[i] = make(map[string]*tfState, 64)
+			b.closed[i] = make(map[string]map[int64]*tfState)
 		}
 	}
 }
@@ -108,14 +140,24 @@ func (b *Builder) Run(ctx context.Context, candleCh <-chan model.Candle, outCh c
 }
 
 // Process handles a single 1s candle against all enabled TFs.
-// This is the hot path — O(1) per TF.
+// This is the hot path: intraday buckets are integer arithmetic, while
+// weekly and monthly ones resolve their open through the holiday calendar.
 func (b *Builder) process(c model.Candle, outCh chan<- model.TFCandle) {
 	ts := c.TS.Unix()
 	key := c.Key()
+	if c.Revision > 0 {
+		b.amend(c, outCh)
+		return
+	}
+	if c.TS.After(b.lastTS) {
+		b.lastTS = c.TS
+	}
+	if b.AmendWindow > 0 {
+		b.remember1s(c)
+	}
 
 	for i, tf := range b.tfs {
-		tf64 := int64(tf)
-		bucket := ts - (ts % tf64) // align to TF boundary
+		bucket := markethours.BucketStart(ts, tf) // session-anchored TF boundary
 
 		st, exists := b.states[i][key]
 
@@ -140,6 +182,7 @@ func (b *Builder) process(c model.Candle, outCh chan<- model.TFCandle) {
 			if b.OnTFCandle != nil {
 				b.OnTFCandle(st.candle)
 			}
+			b.rememberClosed(i, key, st)
 			exists = false
 		}
 
@@ -148,18 +191,23 @@ func (b *Builder) process(c model.Candle, outCh chan<- model.TFCandle) {
 			newState := &tfState{
 				bucket:  bucket,
 				started: true,
+				first:   ts,
+				last:    ts,
 				candle: model.TFCandle{
-					Token:    c.Token,
-					Exchange: c.Exchange,
-					TF:       tf,
-					TS:       time.Unix(bucket, 0).UTC(),
-					Open:     c.Open,
-					High:     c.High,
-					Low:      c.Low,
-					Close:    c.Close,
-					Volume:   c.Volume,
-					Count:    1,
-					Forming:  true,
+					Token:     c.Token,
+					Exchange:  c.Exchange,
+					TF:        tf,
+					TS:        time.Unix(bucket, 0).UTC(),
+					Open:      c.Open,
+					High:      c.High,
+					Low:       c.Low,
+					Close:     c.Close,
+					Volume:    c.Volume,
+					Count:     1,
+					Forming:   true,
+					OIOpen:    c.OIOpen,
+					OIClose:   c.OIClose,
+					Synthetic: c.Synthetic,
 				},
 			}
 			b.states[i][key] = newState
@@ -171,6 +219,7 @@ func (b *Builder) process(c model.Candle, outCh chan<- model.TFCandle) {
 
 		// Same bucket — merge OHLCV (O(1))
 		fc := &st.candle
+		replaceSynthetic(fc, c)
 		if c.High > fc.High {
 			fc.High = c.High
 		}
@@ -180,6 +229,9 @@ func (b *Builder) process(c model.Candle, outCh chan<- model.TFCandle) {
 		fc.Close = c.Close
 		fc.Volume += c.Volume
 		fc.Count++
+		fc.MergeOI(c.OIOpen, c.OIClose)
+		st.first = min(st.first, ts)
+		st.last = max(st.last, ts)
 
 		// Emit a forming snapshot so the live-preview pipeline can peek at
 		// the in-progress candle every second.  We copy the struct to avoid
@@ -189,22 +241,174 @@ func (b *Builder) process(c model.Candle, outCh chan<- model.TFCandle) {
 	}
 }
 
-// FlushSession finalizes and emits all forming TF candles.
-// Called at market close to ensure last candles include the closing price.
+// FlushSession finalizes and emits the forming TF candles that end with the
+// session. Called at market close, after Aggregator.FlushSession, so the last
+// candles include the closing price. Weekly and monthly candles keep forming
+// until the close of their period's last trading day.
 func (b *Builder) FlushSession(outCh chan<- model.TFCandle) {
-	b.flushAll(outCh)
+	kept := 0
+	for i, tf := range b.tfs {
+		if !b.lastTS.IsZero() && !markethours.PeriodEnds(tf, b.lastTS) {
+			kept += len(b.states[i])
+			continue
+		}
+		b.flushTF(i, outCh)
+	}
+	if kept > 0 {
+		log.Printf("[tfbuilder] session flushed — %d weekly/monthly candles still forming", kept)
+		return
+	}
 	log.Println("[tfbuilder] session flushed — all forming TF candles finalized")
 }
 
 // flushAll finalizes and emits all forming candles.
 func (b *Builder) flushAll(outCh chan<- model.TFCandle) {
 	for i := range b.tfs {
-		for key, st := range b.states[i] {
-			if st.started {
-				st.candle.Forming = false
-				emit(outCh, st.candle)
+		b.flushTF(i, outCh)
+	}
+}
+
+// flushTF finalizes and emits the forming candles of the i-th TF.
+func (b *Builder) flushTF(i int, outCh chan<- model.TFCandle) {
+	for key, st := range b.states[i] {
+		if st.started {
+			st.candle.Forming = false
+			emit(outCh, st.candle)
+			b.rememberClosed(i, key, st)
+		}
+		delete(b.states[i], key)
+	}
+}
+
+// amend applies a revised 1s candle to the TF candles containing it, as the
+// difference from the version merged before. Revisions older than
+// AmendWindow, or whose TF candle is no longer kept, are dropped.
+func (b *Builder) amend(c model.Candle, outCh chan<- model.TFCandle) {
+	ts := c.TS.Unix()
+	key := c.Key()
+	if b.AmendWindow <= 0 || c.TS.Before(b.lastTS.Add(-b.AmendWindow)) {
+		if b.OnStaleCandle != nil {
+			b.OnStaleCandle()
+		}
+		return
+	}
+	prev, merged := b.recent1s[key].get(ts)
+	b.remember1s(c)
+
+	for i, tf := range b.tfs {
+		bucket := markethours.BucketStart(ts, tf)
+		st, forming := b.states[i][key], true
+		if st == nil || st.bucket != bucket {
+			st, forming = b.closed[i][key][bucket], false
+		}
+		if st == nil {
+			continue
+		}
+
+		fc := &st.candle
+		replaceSynthetic(fc, c)
+		fc.High = max(fc.High, c.High)
+		fc.Low = min(fc.Low, c.Low)
+		if merged {
+			fc.Volume += c.Volume - prev.Volume
+		} else {
+			fc.Volume += c.Volume
+			fc.Count++
+		}
+		if ts <= st.first {
+			fc.Open = c.Open
+			if c.OIOpen != 0 {
+				fc.OIOpen = c.OIOpen
+			}
+			st.first = ts
+		}
+		if ts >= st.last {
+			fc.Close = c.Close
+			if c.OIClose != 0 {
+				fc.OIClose = c.OIClose
 			}
-			delete(b.states[i], key)
+			st.last = ts
+		}
+
+		if !forming {
+			fc.Revision++
+		}
+		emit(outCh, *fc)
+	}
+}
+
+// replaceSynthetic resets the open, high and low of a TF candle built from
+// gap-fill candles only when the first real 1s candle c joins it, so that
+// the bar reflects traded prices alone.
+func replaceSynthetic(fc *model.TFCandle, c model.Candle) {
+	if !fc.Synthetic || c.Synthetic {
+		return
+	}
+	fc.Open, fc.High, fc.Low = c.Open, c.High, c.Low
+	fc.Synthetic = false
+}
+
+// recentCandles holds the latest version of one token's 1s candles, with their
+// seconds in arrival order so expired ones can be pruned from the front.
+type recentCandles struct {
+	bySec map[int64]model.Candle
+	secs  []int64
+}
+
+// get returns the candle remembered for a second. Safe on a nil receiver.
+func (r *recentCandles) get(sec int64) (model.Candle, bool) {
+	if r == nil {
+		return model.Candle{}, false
+	}
+	c, ok := r.bySec[sec]
+	return c, ok
+}
+
+// horizon returns the oldest unix second still within AmendWindow.
+func (b *Builder) horizon() int64 {
+	return b.lastTS.Add(-b.AmendWindow).Unix()
+}
+
+// remember1s keeps the latest version of a 1s candle for amendments, and
+// prunes that token's candles that fell out of AmendWindow.
+func (b *Builder) remember1s(c model.Candle) {
+	key := c.Key()
+	r := b.recent1s[key]
+	if r == nil {
+		r = &recentCandles{bySec: make(map[int64]model.Candle)}
+		b.recent1s[key] = r
+	}
+	sec := c.TS.Unix()
+	if _, ok := r.bySec[sec]; !ok {
+		r.secs = append(r.secs, sec)
+	}
+	r.bySec[sec] = c
+
+	horizon, n := b.horizon(), 0
+	for n < len(r.secs) && r.secs[n] < horizon {
+		delete(r.bySec, r.secs[n])
+		n++
+	}
+	r.secs = r.secs[n:]
+}
+
+// rememberClosed keeps a finalized TF candle for amendments, and prunes
+// that token's candles of the same TF that fell out of AmendWindow.
+func (b *Builder) rememberClosed(i int, key string, st *tfState) {
+	if b.AmendWindow <= 0 {
+		return
+	}
+	byBucket := b.closed[i][key]
+	if byBucket == nil {
+		byBucket = make(map[int64]*tfState)
+		b.closed[i][key] = byBucket
+	}
+	byBucket[st.bucket] = st
+
+	oldest := markethours.BucketStart(b.horizon(), b.tfs[i])
+	for bucket := range byBucket {
+		if bucket < oldest {
+			delete(byBucket, bucket)
 		}
 	}
 }
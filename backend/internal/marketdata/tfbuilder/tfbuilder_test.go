@@ -240,3 +240,148 @@ func TestBuilder_PartialBucket_NoFinalize(t *testing.T) {
 		}
 	}
 }
+
+func TestBuilder_OpenInterest(t *testing.T) {
+	b := New([]int{60})
+	b.StaleTolerance = 0
+	outCh := make(chan model.TFCandle, 5000)
+
+	baseTS := int64(1700000000)
+	baseTS = baseTS - (baseTS % 60)
+
+	oi := [][2]int64{{0, 0}, {1500000, 1500750}, {0, 0}, {1500750, 1499250}}
+	for i, v := range oi {
+		c := makeCandle("43650", baseTS+int64(i), 100, 110, 90, 105, 1)
+		c.OIOpen, c.OIClose = v[0], v[1]
+		b.process(c, outCh)
+	}
+	b.process(makeCandle("43650", baseTS+60, 100, 110, 90, 105, 1), outCh)
+
+	for len(outCh) > 0 {
+		c := <-outCh
+		if c.Forming {
+			continue
+		}
+		if c.OIOpen != 1500000 || c.OIClose != 1499250 {
+			t.Errorf("oi open/close: got %d/%d, want 1500000/1499250", c.OIOpen, c.OIClose)
+		}
+		return
+	}
+	t.Fatal("no finalized candle")
+}
+
+func TestBuilder_Amend(t *testing.T) {
+	b := New([]int{60})
+	b.StaleTolerance = 0
+	b.AmendWindow = 30 * time.Second
+	outCh := make(chan model.TFCandle, 100)
+
+	base := time.Date(2026, 1, 5, 3, 45, 0, 0, time.UTC).Unix() // 9:15 IST
+	b.process(makeCandle("SBIN", base+50, 500, 510, 495, 505, 100), outCh)
+	b.process(makeCandle("SBIN", base+58, 505, 508, 500, 506, 100), outCh)
+	b.process(makeCandle("SBIN", base+61, 506, 507, 504, 505, 100), outCh) // finalizes the first minute
+	for len(outCh) > 0 {
+		<-outCh
+	}
+
+	// Revised :58 (more volume, new high and close), then a new :59
+	rev := makeCandle("SBIN", base+58, 505, 520, 500, 518, 150)
+	rev.Revision = 1
+	b.process(rev, outCh)
+	c := <-outCh
+	if c.Forming || c.Revision != 1 || c.High != 520 || c.Close != 518 || c.Volume != 250 || c.Count != 2 {
+		t.Errorf("revision 1: got %+v", c)
+	}
+	late := makeCandle("SBIN", base+59, 518, 519, 490, 491, 10)
+	late.Revision = 1
+	b.process(late, outCh)
+	c = <-outCh
+	if c.Revision != 2 || c.Low != 490 || c.Close != 491 || c.Volume != 260 || c.Count != 3 || c.Open != 500 {
+		t.Errorf("revision 2: got %+v", c)
+	}
+
+	// A revision inside the forming minute emits a forming snapshot
+	rev = makeCandle("SBIN", base+61, 506, 507, 503, 505, 120)
+	rev.Revision = 1
+	b.process(rev, outCh)
+	c = <-outCh
+	if !c.Forming || c.Revision != 0 || c.Low != 503 || c.Volume != 120 || c.Count != 1 {
+		t.Errorf("forming amend: got %+v", c)
+	}
+
+	// Beyond the window: dropped
+	b.process(makeCandle("SBIN", base+120, 505, 505, 505, 505, 1), outCh)
+	for len(outCh) > 0 {
+		<-outCh
+	}
+	old := makeCandle("SBIN", base+58, 505, 530, 500, 518, 150)
+	old.Revision = 2
+	b.process(old, outCh)
+	if len(outCh) != 0 {
+		t.Errorf("revision beyond the window should be dropped, got %+v", <-outCh)
+	}
+}
+
+func TestBuilder_AmendPrunesPerToken(t *testing.T) {
+	b := New([]int{60})
+	b.StaleTolerance = 0
+	b.AmendWindow = 30 * time.Second
+	outCh := make(chan model.TFCandle, 1000)
+
+	base := time.Date(2026, 1, 5, 3, 45, 0, 0, time.UTC).Unix() // 9:15 IST
+	for i := int64(0); i < 300; i++ {
+		b.process(makeCandle("SBIN", base+i, 500, 500, 500, 500, 1), outCh)
+		b.process(makeCandle("INFY", base+i, 900, 900, 900, 900, 1), outCh)
+		for len(outCh) > 0 {
+			<-outCh
+		}
+	}
+	for _, key := range []string{"NSE:SBIN", "NSE:INFY"} {
+		r := b.recent1s[key]
+		if len(r.bySec) != 31 || len(r.secs) != 31 {
+			t.Errorf("%s: kept %d 1s candles (%d secs), want the 31 within the window", key, len(r.bySec), len(r.secs))
+		}
+		if n := len(b.closed[0][key]); n > 2 {
+			t.Errorf("%s: kept %d closed minutes, want at most 2", key, n)
+		}
+	}
+}
+
+func TestBuilder_Synthetic(t *testing.T) {
+	b := New([]int{60})
+	b.StaleTolerance = 0
+	outCh := make(chan model.TFCandle, 500)
+
+	base := time.Date(2026, 1, 5, 3, 45, 0, 0, time.UTC).Unix() // 9:15 IST
+	flat := func(sec, price int64) model.Candle {
+		c := makeCandle("SBIN", sec, price, price, price, price, 0)
+		c.TicksCount, c.Synthetic = 0, true
+		return c
+	}
+
+	// A minute of gap-fill candles only: a synthetic bar
+	for i := int64(0); i < 60; i++ {
+		b.process(flat(base+i, 500), outCh)
+	}
+	// Next minute: filled, then a trade — the trade's prices replace the flat open/high/low
+	b.process(flat(base+60, 500), outCh)
+	b.process(makeCandle("SBIN", base+61, 510, 512, 508, 511, 40), outCh)
+	b.process(flat(base+62, 511), outCh)
+	b.process(makeCandle("SBIN", base+120, 511, 511, 511, 511, 1), outCh)
+
+	var finals []model.TFCandle
+	for len(outCh) > 0 {
+		if c := <-outCh; !c.Forming {
+			finals = append(finals, c)
+		}
+	}
+	if len(finals) != 2 {
+		t.Fatalf("expected 2 finalized candles, got %d", len(finals))
+	}
+	if f := finals[0]; !f.Synthetic || f.Open != 500 || f.Volume != 0 || f.Count != 60 {
+		t.Errorf("gap-filled minute: got %+v", f)
+	}
+	if f := finals[1]; f.Synthetic || f.Open != 510 || f.High != 512 || f.Low != 508 || f.Close != 511 || f.Volume != 40 || f.Count != 3 {
+		t.Errorf("traded minute: got %+v", f)
+	}
+}
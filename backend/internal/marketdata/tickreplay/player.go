@@ -0,0 +1,163 @@
+package tickreplay
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"trading-systemv1/internal/model"
+)
+
+// Status is a snapshot of a Player's position, for the HTTP controls.
+type Status struct {
+	Paused   bool      `json:"paused"`
+	Done     bool      `json:"done"`
+	Speed    float64   `json:"speed"`
+	Position int       `json:"position"` // index of the next tick
+	Total    int       `json:"total"`
+	ReplayTS time.Time `json:"replay_ts"` // arrival time of the next tick (zero when done)
+}
+
+// Player emits ticks with their original inter-arrival timing divided by
+// speed (0 = as fast as possible). Ticks keep their recorded timestamps.
+//
+// Timing runs on a virtual clock anchored at the first tick emitted after
+// any control change, so pauses, seeks and speed changes never accumulate
+// drift. Controls are safe to call from any goroutine while Run is active.
+type Player struct {
+	mu     sync.Mutex
+	ticks  []model.Tick // arrival order
+	pos    int
+	speed  float64
+	paused bool
+	gen    int // bumped on every control change
+
+	// Virtual clock: tick at anchorTS is due at anchorWall.
+	anchored   bool
+	anchorWall time.Time
+	anchorTS   time.Time
+
+	wake chan struct{}
+}
+
+// NewPlayer creates a Player over ticks sorted by arrival time.
+func NewPlayer(ticks []model.Tick, speed float64) *Player {
+	return &Player{ticks: ticks, speed: speed, wake: make(chan struct{}, 1)}
+}
+
+// Run emits ticks until ctx is cancelled. At the end of the recording it
+// idles, so a Seek can restart playback.
+func (p *Player) Run(ctx context.Context, emit func(model.Tick)) error {
+	timer := time.NewTimer(time.Hour)
+	timer.Stop()
+
+	for {
+		p.mu.Lock()
+		if p.paused || p.pos >= len(p.ticks) {
+			p.mu.Unlock()
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-p.wake:
+			}
+			continue
+		}
+
+		t := p.ticks[p.pos]
+		if !p.anchored {
+			p.anchored, p.anchorWall, p.anchorTS = true, time.Now(), t.TickTS
+		}
+		var wait time.Duration
+		if p.speed > 0 {
+			due := p.anchorWall.Add(time.Duration(float64(t.TickTS.Sub(p.anchorTS)) / p.speed))
+			wait = time.Until(due)
+		}
+		gen := p.gen
+		p.mu.Unlock()
+
+		if wait > 0 {
+			timer.Reset(wait)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-p.wake:
+				if !timer.Stop() {
+					<-timer.C
+				}
+				continue
+			case <-timer.C:
+			}
+		} else if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		p.mu.Lock()
+		if gen != p.gen {
+			p.mu.Unlock()
+			continue // a control changed while waiting; re-evaluate
+		}
+		p.pos++
+		p.mu.Unlock()
+		emit(t)
+	}
+}
+
+// Pause stops emission until Resume.
+func (p *Player) Pause() {
+	p.control(func() { p.paused = true })
+}
+
+// Resume continues after Pause, timing from the current tick.
+func (p *Player) Resume() {
+	p.control(func() { p.paused = false })
+}
+
+// SetSpeed changes the speed multiplier (0 = as fast as possible).
+func (p *Player) SetSpeed(speed float64) error {
+	if speed < 0 {
+		return errors.New("speed must be >= 0")
+	}
+	p.control(func() { p.speed = speed })
+	return nil
+}
+
+// Seek moves playback to the first tick that arrived at or after ts.
+func (p *Player) Seek(ts time.Time) {
+	p.control(func() {
+		p.pos = sort.Search(len(p.ticks), func(i int) bool {
+			return !p.ticks[i].TickTS.Before(ts)
+		})
+	})
+}
+
+// Status returns the current playback state.
+func (p *Player) Status() Status {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s := Status{
+		Paused:   p.paused,
+		Done:     p.pos >= len(p.ticks),
+		Speed:    p.speed,
+		Position: p.pos,
+		Total:    len(p.ticks),
+	}
+	if !s.Done {
+		s.ReplayTS = p.ticks[p.pos].TickTS
+	}
+	return s
+}
+
+// control applies a change under the lock, re-anchors the clock and wakes Run.
+func (p *Player) control(change func()) {
+	p.mu.Lock()
+	change()
+	p.gen++
+	p.anchored = false
+	p.mu.Unlock()
+	select {
+	case p.wake <- struct{}{}:
+	default:
+	}
+}
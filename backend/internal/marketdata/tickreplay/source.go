@@ -0,0 +1,114 @@
+// Package tickreplay replays a recorded session as a tick stream: archived
+// raw ticks (tickstore) or, where none were archived, candles_1s rows
+// expanded into synthetic ticks. A Player emits them with the original
+// inter-arrival timing scaled by a speed multiplier and supports pause,
+// resume and seek, so the full mdengine → indengine → gateway stack can run
+// against a past session.
+package tickreplay
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"trading-systemv1/internal/markethours"
+	"trading-systemv1/internal/model"
+	sqlitestore "trading-systemv1/internal/store/sqlite"
+	"trading-systemv1/internal/store/tickstore"
+)
+
+// Replay sources accepted by Load.
+const (
+	SourceAuto    = "auto"    // archived ticks where available, else candles_1s
+	SourceTicks   = "ticks"   // archived ticks only
+	SourceCandles = "candles" // synthetic ticks from candles_1s only
+)
+
+// Instrument identifies one replayed instrument.
+type Instrument struct {
+	Exchange string
+	Token    string
+}
+
+// Load returns the ticks of instruments for the IST trading day of day,
+// merged in arrival order. ticks or candles may be nil when the source does
+// not use them.
+func Load(day time.Time, instruments []Instrument, source string, ticks *tickstore.Reader, candles *sqlitestore.Reader) ([]model.Tick, error) {
+	y, m, d := day.In(markethours.IST).Date()
+	from := time.Date(y, m, d, 0, 0, 0, 0, markethours.IST)
+	to := from.AddDate(0, 0, 1)
+
+	var all []model.Tick
+	for _, in := range instruments {
+		var got []model.Tick
+		var err error
+		switch source {
+		case SourceTicks, SourceAuto:
+			if ticks != nil {
+				got, err = ticks.ReadTicks(in.Exchange, in.Token, from, to)
+				if err != nil {
+					return nil, err
+				}
+			}
+			if len(got) > 0 || source == SourceTicks {
+				break
+			}
+			fallthrough
+		case SourceCandles:
+			if candles == nil {
+				break
+			}
+			var cs []model.Candle
+			cs, err = candles.Read1sCandles(in.Exchange, in.Token, from, to)
+			if err != nil {
+				return nil, err
+			}
+			for _, c := range cs {
+				got = append(got, SyntheticTicks(c)...)
+			}
+		default:
+			return nil, fmt.Errorf("unknown replay source %q", source)
+		}
+		log.Printf("[tickreplay] %s:%s — %d ticks for %s", in.Exchange, in.Token, len(got), from.Format("2006-01-02"))
+		all = append(all, got...)
+	}
+
+	sort.SliceStable(all, func(i, j int) bool {
+		return all[i].TickTS.Before(all[j].TickTS)
+	})
+	return all, nil
+}
+
+// SyntheticTicks expands a 1s candle into up to four ticks spread over its
+// second: open, then the extreme against the candle's direction, the other
+// extreme, and close (open → low → high → close for an up candle). Repeated
+// prices are merged and the volume is split evenly, the remainder going to
+// the last tick, so re-aggregating them rebuilds the same candle.
+func SyntheticTicks(c model.Candle) []model.Tick {
+	path := []int64{c.Open, c.Low, c.High, c.Close}
+	if c.Close < c.Open {
+		path[1], path[2] = c.High, c.Low
+	}
+	prices := append(make([]int64, 0, len(path)), path[0])
+	for _, p := range path[1:] {
+		if p != prices[len(prices)-1] {
+			prices = append(prices, p)
+		}
+	}
+
+	n := int64(len(prices))
+	ticks := make([]model.Tick, len(prices))
+	step := time.Second / time.Duration(len(prices))
+	for i, p := range prices {
+		ticks[i] = model.Tick{
+			Token:    c.Token,
+			Exchange: c.Exchange,
+			Price:    p,
+			Qty:      c.Volume / n,
+			TickTS:   c.TS.Add(time.Duration(i) * step),
+		}
+	}
+	ticks[n-1].Qty += c.Volume % n
+	return ticks
+}
@@ -0,0 +1,145 @@
+package tickreplay
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"trading-systemv1/internal/marketdata/agg"
+	"trading-systemv1/internal/markethours"
+	"trading-systemv1/internal/model"
+	sqlitestore "trading-systemv1/internal/store/sqlite"
+)
+
+var t0 = time.Date(2026, time.January, 5, 9, 15, 0, 0, markethours.IST).UTC()
+
+func TestSyntheticTicks_RebuildCandle(t *testing.T) {
+	candles := []model.Candle{
+		{Token: "2885", Exchange: "NSE", TS: t0, Open: 100, High: 110, Low: 95, Close: 108, Volume: 10},
+		{Token: "2885", Exchange: "NSE", TS: t0.Add(time.Second), Open: 108, High: 109, Low: 101, Close: 102, Volume: 7},
+		{Token: "2885", Exchange: "NSE", TS: t0.Add(2 * time.Second), Open: 102, High: 102, Low: 102, Close: 102, Volume: 3},
+	}
+
+	up := SyntheticTicks(candles[0])
+	if len(up) != 4 || up[1].Price != 95 || up[2].Price != 110 {
+		t.Errorf("up candle path should be O→L→H→C: %+v", up)
+	}
+	down := SyntheticTicks(candles[1])
+	if down[1].Price != 109 || down[2].Price != 101 {
+		t.Errorf("down candle path should be O→H→L→C: %+v", down)
+	}
+	if flat := SyntheticTicks(candles[2]); len(flat) != 1 || flat[0].Qty != 3 {
+		t.Errorf("flat candle should be one tick carrying the volume: %+v", flat)
+	}
+
+	// Re-aggregate through the real aggregator.
+	tickCh := make(chan model.Tick, 100)
+	for _, c := range candles {
+		for _, tk := range SyntheticTicks(c) {
+			tickCh <- tk
+		}
+	}
+	close(tickCh)
+	candleCh := make(chan model.Candle, 100)
+	agg.New().Run(context.Background(), tickCh, candleCh)
+	close(candleCh)
+
+	var got []model.Candle
+	for c := range candleCh {
+		got = append(got, c)
+	}
+	if len(got) != len(candles) {
+		t.Fatalf("expected %d candles, got %d", len(candles), len(got))
+	}
+	for i, want := range candles {
+		g := got[i]
+		if !g.TS.Equal(want.TS) || g.Open != want.Open || g.High != want.High || g.Low != want.Low ||
+			g.Close != want.Close || g.Volume != want.Volume {
+			t.Errorf("candle %d: got %+v, want %+v", i, g, want)
+		}
+	}
+}
+
+func TestLoad_FallsBackToCandles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "candles.db")
+	w, err := sqlitestore.New(sqlitestore.WriterConfig{DBPath: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	ch := make(chan model.Candle, 4)
+	ch <- model.Candle{Token: "2885", Exchange: "NSE", TS: t0.Add(time.Second), Open: 5, High: 5, Low: 5, Close: 5, Volume: 1}
+	ch <- model.Candle{Token: "1594", Exchange: "NSE", TS: t0, Open: 7, High: 7, Low: 7, Close: 7, Volume: 1}
+	ch <- model.Candle{Token: "2885", Exchange: "NSE", TS: t0.AddDate(0, 0, 1), Open: 9, High: 9, Low: 9, Close: 9, Volume: 1}
+	close(ch)
+	w.Run(context.Background(), ch)
+
+	r, err := sqlitestore.NewReader(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	ticks, err := Load(t0, []Instrument{{"NSE", "2885"}, {"NSE", "1594"}}, SourceAuto, nil, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ticks) != 2 || ticks[0].Token != "1594" || ticks[1].Token != "2885" {
+		t.Errorf("expected both instruments' ticks of the day merged by time, got %+v", ticks)
+	}
+	if _, err := Load(t0, nil, "bogus", nil, r); err != nil {
+		t.Errorf("no instruments, no error: %v", err)
+	}
+}
+
+func TestPlayer_TimingAndControls(t *testing.T) {
+	var ticks []model.Tick
+	for i := 0; i < 5; i++ {
+		ticks = append(ticks, model.Tick{Token: "2885", Exchange: "NSE", Price: int64(100 + i), TickTS: t0.Add(time.Duration(i) * time.Second)})
+	}
+	p := NewPlayer(ticks, 20) // 1s gaps → 50ms
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	out := make(chan model.Tick, 10)
+	start := time.Now()
+	go p.Run(ctx, func(tk model.Tick) { out <- tk })
+
+	<-out
+	<-out
+	if el := time.Since(start); el < 40*time.Millisecond {
+		t.Errorf("second tick after %v, want ~50ms at 20x", el)
+	}
+
+	p.Pause()
+	select {
+	case tk := <-out:
+		if tk.Price != 102 { // at most the tick already due may slip through
+			t.Fatalf("emitted %d while paused", tk.Price)
+		}
+	case <-time.After(150 * time.Millisecond):
+	}
+	if s := p.Status(); !s.Paused || s.Total != 5 {
+		t.Errorf("status while paused: %+v", s)
+	}
+
+	p.Seek(t0.Add(4 * time.Second))
+	p.SetSpeed(0)
+	p.Resume()
+	if tk := <-out; tk.Price != 104 {
+		t.Errorf("after seek got price %d, want 104", tk.Price)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if s := p.Status(); !s.Done || !s.ReplayTS.IsZero() {
+		t.Errorf("expected done: %+v", s)
+	}
+
+	p.Seek(t0) // restart from the beginning
+	if tk := <-out; tk.Price != 100 {
+		t.Errorf("after rewind got price %d, want 100", tk.Price)
+	}
+	if err := p.SetSpeed(-1); err == nil {
+		t.Error("negative speed should be rejected")
+	}
+}
@@ -30,38 +30,61 @@ type IngestConfig struct {
 	FeedToken  string
 
 	// Tokens to subscribe, grouped by exchange type and mode.
+	// Quote and SnapQuote packets also produce a model.Quote (OnQuote).
 	SubscribeMode int
 	TokenList     []smartconnect.TokenListEntry
+
+	// SubscribeDepth additionally subscribes TokenList in Depth mode (at
+	// most 50 tokens); its packets produce a model.Depth (OnDepth), no ticks.
+	SubscribeDepth bool
+
+	// URL overrides the SmartStream endpoint, e.g. a local emulator
+	// ("ws://localhost:9001/smart-stream"). Empty = Angel One.
+	URL string
+
+	// RetryDelaySec is the initial reconnect delay (default 5).
+	RetryDelaySec int
 }
 
 // Ingest connects to Angel One WebSocket and pushes normalized ticks into tickCh.
 type Ingest struct {
-	cfg IngestConfig
-	ws  *smartconnect.SmartWebSocketV3
+	cfg    IngestConfig
+	ws     *smartconnect.SmartWebSocketV3
+	volume *volumeTracker // per-packet volume from the Quote-mode day counter
 
 	// Optional metrics hooks
 	OnReconnect func()
 	OnTick      func(price int64) // called with LTP on every tick (for close detection)
+
+	// Optional market-data hooks, called from the WS read goroutine.
+	OnQuote func(q model.Quote)
+	OnDepth func(d model.Depth)
 }
 
 // New creates a new Ingest instance.
 func New(cfg IngestConfig) (*Ingest, error) {
+	if cfg.RetryDelaySec <= 0 {
+		cfg.RetryDelaySec = 5
+	}
 	ws, err := smartconnect.NewSmartWebSocketV3(
 		cfg.AuthToken,
 		cfg.APIKey,
 		cfg.ClientCode,
 		cfg.FeedToken,
-		5,  // maxRetryAttempt
-		1,  // retryStrategy: exponential
-		5,  // retryDelaySec
-		2,  // retryMultiplier
-		30, // retryDurationMin
+		5,                 // maxRetryAttempt
+		1,                 // retryStrategy: exponential
+		cfg.RetryDelaySec, // retryDelaySec
+		2,                 // retryMultiplier
+		30,                // retryDurationMin
 	)
 	if err != nil {
 		return nil, fmt.Errorf("ws ingest: create websocket: %w", err)
 	}
+	if cfg.URL != "" {
+		ws.URL = cfg.URL
+	}
 
-	return &Ingest{cfg: cfg, ws: ws}, nil
+	return &Ingest{cfg: cfg, ws: ws, volume: newVolumeTracker()}, nil
 }
 
 // Start connects to the WebSocket and begins streaming ticks into tickCh.
@@ -77,15 +100,41 @@ func (ing *Ingest) Start(ctx context.Context, tickCh chan<- model.Tick) error {
 		} else {
 			log.Println("[ws] subscription sent successfully")
 		}
+		if ing.cfg.SubscribeDepth {
+			if err := ing.ws.Subscribe("depth_ingest", smartconnect.ModeDepth, ing.cfg.TokenList); err != nil {
+				log.Printf("[ws] depth subscribe error: %v", err)
+			}
+		}
 	}
 
 	ing.ws.OnData = func(msg map[string]interface{}) {
+		switch toInt(msg["subscription_mode"]) {
+		case smartconnect.ModeDepth: // order book only — no LTP in the packet
+			if ing.OnDepth != nil {
+				if d, err := parseDepth(msg); err == nil {
+					ing.OnDepth(d)
+				}
+			}
+			return
+		case smartconnect.ModeQuote, smartconnect.ModeSnapQuote:
+			if ing.OnQuote != nil {
+				if q, err := parseQuote(msg); err == nil {
+					ing.OnQuote(q)
+				}
+			}
+		}
+
 		log.Printf("[ws] raw tick: token=%v ltp=%v exchange_type=%v", msg["token"], msg["last_traded_price"], msg["exchange_type"])
 		tick, err := parseTick(msg)
 		if err != nil {
 			log.Printf("[ws] parse error: %v", err)
 			return
 		}
+		// Quote modes carry the day's cumulative volume: count what traded
+		// since the previous packet rather than the last trade's size.
+		if cum, ok := msg["volume_trade_for_the_day"]; ok {
+			tick.Qty = ing.volume.delta(tick.Exchange+":"+tick.Token, toInt64(cum), tick.Qty, tick.TickTS)
+		}
 
 		// Notify close detector (price observation for market close detection)
 		if ing.OnTick != nil {
@@ -125,18 +174,27 @@ func (ing *Ingest) Start(ctx context.Context, tickCh chan<- model.Tick) error {
 	return nil
 }
 
-// parseTick converts the raw WS message map into a model.Tick.
-func parseTick(msg map[string]interface{}) (model.Tick, error) {
-	token, _ := msg["token"].(string)
+// parseInstrument extracts the token and exchange name of a WS message.
+func parseInstrument(msg map[string]interface{}) (token, exchange string, err error) {
+	token, _ = msg["token"].(string)
 	if token == "" {
-		return model.Tick{}, fmt.Errorf("missing token")
+		return "", "", fmt.Errorf("missing token")
 	}
 
 	exType := toInt(msg["exchange_type"])
-	exchange := exchangeTypeToName[exType]
+	exchange = exchangeTypeToName[exType]
 	if exchange == "" {
 		exchange = fmt.Sprintf("EX_%d", exType)
 	}
+	return token, exchange, nil
+}
+
+// parseTick converts the raw WS message map into a model.Tick.
+func parseTick(msg map[string]interface{}) (model.Tick, error) {
+	token, exchange, err := parseInstrument(msg)
+	if err != nil {
+		return model.Tick{}, err
+	}
 
 	price := toInt64(msg["last_traded_price"])
 	qty := toInt64(msg["last_traded_quantity"])
@@ -156,13 +214,102 @@ func parseTick(msg map[string]interface{}) (model.Tick, error) {
 		Price:    price,
 		Qty:      qty,
 		TickTS:   tickTS,
+		OI:       toInt64(msg["open_interest"]), // SnapQuote only
 	}, nil
 }
 
+// parseQuote converts a Quote or SnapQuote message into a model.Quote.
+func parseQuote(msg map[string]interface{}) (model.Quote, error) {
+	token, exchange, err := parseInstrument(msg)
+	if err != nil {
+		return model.Quote{}, err
+	}
+	q := model.Quote{
+		Token:        token,
+		Exchange:     exchange,
+		Mode:         model.QuoteModeQuote,
+		TS:           msTime(msg["exchange_timestamp"]),
+		LTP:          toInt64(msg["last_traded_price"]),
+		LastQty:      toInt64(msg["last_traded_quantity"]),
+		AvgPrice:     toInt64(msg["average_traded_price"]),
+		Volume:       toInt64(msg["volume_trade_for_the_day"]),
+		TotalBuyQty:  toInt64(msg["total_buy_quantity"]),
+		TotalSellQty: toInt64(msg["total_sell_quantity"]),
+		Open:         toInt64(msg["open_price_of_the_day"]),
+		High:         toInt64(msg["high_price_of_the_day"]),
+		Low:          toInt64(msg["low_price_of_the_day"]),
+		PrevClose:    toInt64(msg["closed_price"]),
+	}
+	if toInt(msg["subscription_mode"]) != smartconnect.ModeSnapQuote {
+		return q, nil
+	}
+
+	q.Mode = model.QuoteModeSnapQuote
+	q.Bids = parseLevels(msg["best_5_buy_data"], "no_of_orders")
+	q.Asks = parseLevels(msg["best_5_sell_data"], "no_of_orders")
+	if len(q.Bids) > 0 {
+		q.BidPrice, q.BidQty = q.Bids[0].Price, q.Bids[0].Qty
+	}
+	if len(q.Asks) > 0 {
+		q.AskPrice, q.AskQty = q.Asks[0].Price, q.Asks[0].Qty
+	}
+	q.OI = toInt64(msg["open_interest"])
+	q.UpperCircuit = toInt64(msg["upper_circuit_limit"])
+	q.LowerCircuit = toInt64(msg["lower_circuit_limit"])
+	q.High52W = toInt64(msg["52_week_high_price"])
+	q.Low52W = toInt64(msg["52_week_low_price"])
+	return q, nil
+}
+
+// parseDepth converts a Depth message into a model.Depth.
+func parseDepth(msg map[string]interface{}) (model.Depth, error) {
+	token, exchange, err := parseInstrument(msg)
+	if err != nil {
+		return model.Depth{}, err
+	}
+	return model.Depth{
+		Token:    token,
+		Exchange: exchange,
+		TS:       msTime(msg["packet_received_time"]),
+		Bids:     parseLevels(msg["depth_20_buy_data"], "num_of_orders"),
+		Asks:     parseLevels(msg["depth_20_sell_data"], "num_of_orders"),
+	}, nil
+}
+
+// parseLevels converts smartconnect's level maps, skipping empty levels.
+func parseLevels(v interface{}, ordersKey string) []model.DepthLevel {
+	raw, _ := v.([]map[string]interface{})
+	levels := make([]model.DepthLevel, 0, len(raw))
+	for _, l := range raw {
+		lv := model.DepthLevel{
+			Price:  toInt64(l["price"]),
+			Qty:    toInt64(l["quantity"]),
+			Orders: toInt(l[ordersKey]),
+		}
+		if lv.Price == 0 && lv.Qty == 0 {
+			continue
+		}
+		levels = append(levels, lv)
+	}
+	return levels
+}
+
+// msTime converts an epoch-milliseconds field, falling back to now.
+func msTime(v interface{}) time.Time {
+	if ms := toInt64(v); ms > 0 {
+		return time.UnixMilli(ms).UTC()
+	}
+	return time.Now().UTC()
+}
+
 func toInt(v interface{}) int {
 	switch t := v.(type) {
 	case int:
 		return t
+	case int16:
+		return int(t)
+	case int32:
+		return int(t)
 	case int64:
 		return int(t)
 	case float64:
@@ -181,6 +328,10 @@ func toInt64(v interface{}) int64 {
 		return t
 	case int:
 		return int64(t)
+	case int32:
+		return int64(t)
+	case int16:
+		return int64(t)
 	case float64:
 		return int64(t)
 	case string:
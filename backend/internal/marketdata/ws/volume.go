@@ -0,0 +1,70 @@
+package ws
+
+import (
+	"log"
+	"time"
+
+	"trading-systemv1/internal/markethours"
+)
+
+// volumeOpenGrace is how long after the session open a first-seen day
+// counter is still taken whole: it then holds only the pre-open and opening
+// trades, which belong to the session's first candle.
+const volumeOpenGrace = time.Minute
+
+// volumeGapLog is the packet gap above which a volume catch-up is logged.
+const volumeGapLog = 10 * time.Second
+
+// volumeState is the last cumulative day volume seen for an instrument.
+type volumeState struct {
+	day time.Time // IST session open the counter belongs to
+	cum int64
+	ts  time.Time
+}
+
+// volumeTracker turns the cumulative day volume of Quote and SnapQuote
+// packets (volume_trade_for_the_day) into the volume traded since the
+// previous packet, per instrument. Summed over a session it matches the
+// exchange's day total, whatever the packet rate:
+//
+//   - the first counter of a session is taken whole if seen within
+//     volumeOpenGrace of the open (the exchange resets it each session);
+//     later, as after a mid-session restart, the volume before it was never
+//     ours and the packet counts its last traded quantity only
+//   - after a reconnect the volume traded during the gap goes to the first
+//     packet, so totals stay exact
+//   - a counter going backwards (a late, out-of-order packet) counts zero
+//     and keeps the baseline
+//
+// It is not safe for concurrent use; the Ingest calls it from the WS read
+// goroutine.
+type volumeTracker struct {
+	last map[string]volumeState // "exchange:token"
+}
+
+func newVolumeTracker() *volumeTracker {
+	return &volumeTracker{last: make(map[string]volumeState)}
+}
+
+// delta returns the volume traded since key's previous packet, given the
+// packet's cumulative day volume, last traded quantity and exchange time.
+func (v *volumeTracker) delta(key string, cum, lastQty int64, ts time.Time) int64 {
+	day := markethours.SessionOpen(ts)
+	st, ok := v.last[key]
+	if !ok || !st.day.Equal(day) {
+		v.last[key] = volumeState{day: day, cum: cum, ts: ts}
+		if ts.Before(day.Add(volumeOpenGrace)) {
+			return cum
+		}
+		return min(lastQty, cum)
+	}
+	if cum < st.cum {
+		return 0
+	}
+	d := cum - st.cum
+	if gap := ts.Sub(st.ts); gap > volumeGapLog && d > 0 {
+		log.Printf("[ws] volume catch-up %s: %d over a %v gap", key, d, gap.Round(time.Second))
+	}
+	v.last[key] = volumeState{day: day, cum: cum, ts: ts}
+	return d
+}
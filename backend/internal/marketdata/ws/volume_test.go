@@ -0,0 +1,45 @@
+package ws
+
+import (
+	"testing"
+	"time"
+
+	"trading-systemv1/internal/markethours"
+)
+
+func TestVolumeTracker(t *testing.T) {
+	open := time.Date(2026, 1, 5, 9, 15, 0, 0, markethours.IST)
+	nextOpen := open.AddDate(0, 0, 1)
+	v := newVolumeTracker()
+
+	steps := []struct {
+		name     string
+		key      string
+		cum, qty int64
+		ts       time.Time
+		want     int64
+	}{
+		{"first at the open: pre-open and opening trades", "NFO:1", 5000, 75, open.Add(2 * time.Second), 5000},
+		{"increment", "NFO:1", 5150, 75, open.Add(3 * time.Second), 150},
+		{"quote update without a trade", "NFO:1", 5150, 75, open.Add(4 * time.Second), 0},
+		{"late packet going backwards", "NFO:1", 5100, 50, open.Add(3 * time.Second), 0},
+		{"baseline kept", "NFO:1", 5225, 75, open.Add(5 * time.Second), 75},
+		{"reconnect gap catches up", "NFO:1", 9000, 25, open.Add(2 * time.Minute), 3775},
+		{"first mid-session: last trade only", "NFO:2", 880000, 50, open.Add(2 * time.Hour), 50},
+		{"next session resets the counter", "NFO:1", 300, 25, nextOpen.Add(time.Second), 300},
+		{"first before the open (pre-open)", "NFO:3", 1200, 100, nextOpen.Add(-5 * time.Minute), 1200},
+	}
+	var total int64
+	for _, s := range steps {
+		got := v.delta(s.key, s.cum, s.qty, s.ts)
+		if got != s.want {
+			t.Errorf("%s: got %d, want %d", s.name, got, s.want)
+		}
+		if s.key == "NFO:1" && s.ts.Before(nextOpen) {
+			total += got
+		}
+	}
+	if total != 9000 {
+		t.Errorf("session total %d, want the exchange's 9000", total)
+	}
+}
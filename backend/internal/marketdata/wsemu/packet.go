@@ -0,0 +1,155 @@
+package wsemu
+
+import (
+	"encoding/binary"
+	"math"
+	"time"
+
+	"trading-systemv1/internal/markethours"
+	"trading-systemv1/internal/model"
+	smartconnect "trading-systemv1/pkg/smartconnect"
+)
+
+// Packet sizes of the SmartStream v2 binary frames, by subscription mode.
+const (
+	ltpPacketSize       = 51
+	quotePacketSize     = 123
+	snapQuotePacketSize = 379
+	depthPacketSize     = 43 + 400 // header + 20 buy and 20 sell levels
+)
+
+// tickSize is the spacing of the synthesized order book levels (paise).
+const tickSize = 5
+
+// dayState accumulates the session statistics Quote and SnapQuote packets
+// carry, per instrument. It resets at the IST day boundary.
+type dayState struct {
+	day        time.Time
+	seq        int64
+	open, high int64
+	low, close int64 // close: the previous day's last price
+	last       int64
+	lastQty    int64
+	volume     int64
+	oi, prevOI int64   // open interest now and at the previous day's end
+	turnover   float64 // Σ price × qty, for the average traded price
+	lastTS     time.Time
+}
+
+// update folds a tick into the state.
+func (st *dayState) update(t model.Tick) {
+	ts := t.CanonicalTS()
+	y, m, d := ts.In(markethours.IST).Date()
+	day := time.Date(y, m, d, 0, 0, 0, 0, markethours.IST)
+	if !day.Equal(st.day) {
+		prev := st.last
+		if prev == 0 {
+			prev = t.Price
+		}
+		*st = dayState{day: day, seq: st.seq, open: t.Price, high: t.Price, low: t.Price, close: prev, oi: st.oi, prevOI: st.oi}
+	}
+	st.seq++
+	st.high = max(st.high, t.Price)
+	st.low = min(st.low, t.Price)
+	st.last, st.lastQty, st.lastTS = t.Price, t.Qty, ts
+	st.volume += t.Qty
+	if t.OI != 0 {
+		st.oi = t.OI
+	}
+	st.turnover += float64(t.Price) * float64(t.Qty)
+}
+
+// encode builds the binary packet for mode, laid out as SmartStream v2
+// (little-endian): mode, exchange type, 25-byte token, sequence number,
+// exchange timestamp (ms), then the mode's payload.
+func encode(mode, exchangeType int, token string, st *dayState) []byte {
+	size := ltpPacketSize
+	switch mode {
+	case smartconnect.ModeQuote:
+		size = quotePacketSize
+	case smartconnect.ModeSnapQuote:
+		size = snapQuotePacketSize
+	case smartconnect.ModeDepth:
+		size = depthPacketSize
+	}
+	b := make([]byte, size)
+	b[0] = byte(mode)
+	b[1] = byte(exchangeType)
+	copy(b[2:27], token)
+	putInt(b, 27, st.seq)
+	putInt(b, 35, st.lastTS.UnixMilli())
+
+	if mode == smartconnect.ModeDepth {
+		for i := 0; i < 20; i++ {
+			putLevel10(b[43+i*10:], st.last-int64(i+1)*tickSize, int32(100*(i+1)), int16(i+1))
+			putLevel10(b[243+i*10:], st.last+int64(i+1)*tickSize, int32(100*(i+1)), int16(i+1))
+		}
+		return b
+	}
+
+	putInt(b, 43, st.last)
+	if mode == smartconnect.ModeLTP {
+		return b
+	}
+
+	avg := int64(0)
+	if st.volume > 0 {
+		avg = int64(math.Round(st.turnover / float64(st.volume)))
+	}
+	bookQty := float64(0)
+	for i := 1; i <= 5; i++ {
+		bookQty += float64(100 * i)
+	}
+	putInt(b, 51, st.lastQty)
+	putInt(b, 59, avg)
+	putInt(b, 67, st.volume)
+	putFloat(b, 75, bookQty) // total buy quantity
+	putFloat(b, 83, bookQty) // total sell quantity
+	putInt(b, 91, st.open)
+	putInt(b, 99, st.high)
+	putInt(b, 107, st.low)
+	putInt(b, 115, st.close)
+	if mode == smartconnect.ModeQuote {
+		return b
+	}
+
+	putInt(b, 123, st.lastTS.Unix())
+	putInt(b, 131, st.oi)
+	oiChangePct := int64(0)
+	if st.prevOI > 0 {
+		oiChangePct = (st.oi - st.prevOI) * 100 / st.prevOI
+	}
+	putInt(b, 139, oiChangePct) // open interest change over the day, %
+	for i := 0; i < 5; i++ {
+		putLevel20(b[147+i*20:], 0, st.last-int64(i+1)*tickSize, int64(100*(i+1)), int16(i+1)) // buy
+		putLevel20(b[247+i*20:], 1, st.last+int64(i+1)*tickSize, int64(100*(i+1)), int16(i+1)) // sell
+	}
+	putInt(b, 347, st.close*120/100) // upper circuit: ±20% of the previous close
+	putInt(b, 355, st.close*80/100)
+	putInt(b, 363, st.high) // 52-week high/low: this session's range
+	putInt(b, 371, st.low)
+	return b
+}
+
+func putInt(b []byte, off int, v int64) {
+	binary.LittleEndian.PutUint64(b[off:], uint64(v))
+}
+
+func putFloat(b []byte, off int, v float64) {
+	binary.LittleEndian.PutUint64(b[off:], math.Float64bits(v))
+}
+
+// putLevel20 writes a best-five level: flag (0 buy, 1 sell), qty, price, orders.
+func putLevel20(b []byte, flag int16, price, qty int64, orders int16) {
+	binary.LittleEndian.PutUint16(b[0:], uint16(flag))
+	binary.LittleEndian.PutUint64(b[2:], uint64(qty))
+	binary.LittleEndian.PutUint64(b[10:], uint64(price))
+	binary.LittleEndian.PutUint16(b[18:], uint16(orders))
+}
+
+// putLevel10 writes a depth-20 level: qty, price, orders.
+func putLevel10(b []byte, price int64, qty int32, orders int16) {
+	binary.LittleEndian.PutUint32(b[0:], uint32(qty))
+	binary.LittleEndian.PutUint32(b[4:], uint32(price))
+	binary.LittleEndian.PutUint16(b[8:], uint16(orders))
+}
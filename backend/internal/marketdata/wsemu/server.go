@@ -0,0 +1,254 @@
+// Package wsemu emulates the Angel One SmartStream v2 WebSocket, so the
+// production ingest path (marketdata/ws + smartconnect.SmartWebSocketV3)
+// can run end to end offline against cmd/tickserver.
+//
+// The emulator checks the auth headers, accepts the subscribe/unsubscribe
+// JSON requests, answers "ping" heartbeats with "pong" (and WebSocket pings
+// with pongs) and sends each published tick as a little-endian binary
+// packet in every mode a client subscribed the instrument in: LTP, Quote,
+// SnapQuote or Depth. Session statistics (open/high/low, volume, average
+// price) and open interest are accumulated from the published ticks; order
+// book levels are synthesized around the last price.
+//
+// DropClients closes every connection, to exercise reconnect and resubscribe.
+package wsemu
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"trading-systemv1/internal/model"
+	smartconnect "trading-systemv1/pkg/smartconnect"
+
+	"github.com/gorilla/websocket"
+)
+
+// exchangeTypes maps exchange names to SmartStream exchange types.
+var exchangeTypes = map[string]int{
+	"NSE": smartconnect.NSE_CM,
+	"NFO": smartconnect.NSE_FO,
+	"BSE": smartconnect.BSE_CM,
+	"BFO": smartconnect.BSE_FO,
+	"MCX": smartconnect.MCX_FO,
+	"NCX": smartconnect.NCX_FO,
+	"CDE": smartconnect.CDE_FO,
+}
+
+// authHeaders must be present on the upgrade request.
+var authHeaders = []string{"Authorization", "x-api-key", "x-client-code", "x-feed-token"}
+
+// instrumentKey identifies an instrument on the wire.
+type instrumentKey struct {
+	exchangeType int
+	token        string
+}
+
+type subKey struct {
+	instrumentKey
+	mode int
+}
+
+// frame is one outgoing WebSocket message.
+type frame struct {
+	msgType int
+	data    []byte
+}
+
+type client struct {
+	conn *websocket.Conn
+	send chan frame
+
+	mu   sync.Mutex
+	subs map[subKey]struct{}
+}
+
+// request is a subscribe/unsubscribe message.
+type request struct {
+	CorrelationID string `json:"correlationID"`
+	Action        *int   `json:"action"`
+	Params        struct {
+		Mode      int                           `json:"mode"`
+		TokenList []smartconnect.TokenListEntry `json:"tokenList"`
+	} `json:"params"`
+}
+
+// Server is the SmartStream emulator; mount it as an http.Handler.
+type Server struct {
+	upgrader websocket.Upgrader
+
+	mu      sync.Mutex
+	clients map[*client]struct{}
+	state   map[instrumentKey]*dayState
+}
+
+// New creates an emulator with no clients.
+func New() *Server {
+	return &Server{
+		upgrader: websocket.Upgrader{CheckOrigin: func(_ *http.Request) bool { return true }},
+		clients:  make(map[*client]struct{}),
+		state:    make(map[instrumentKey]*dayState),
+	}
+}
+
+// ServeHTTP upgrades an authenticated request and serves the connection.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	for _, h := range authHeaders {
+		if r.Header.Get(h) == "" {
+			http.Error(w, "missing "+h, http.StatusUnauthorized)
+			return
+		}
+	}
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("[wsemu] upgrade error: %v", err)
+		return
+	}
+	c := &client{conn: conn, send: make(chan frame, 1024), subs: make(map[subKey]struct{})}
+	s.mu.Lock()
+	s.clients[c] = struct{}{}
+	s.mu.Unlock()
+	log.Printf("[wsemu] client connected: %s (%s)", r.RemoteAddr, r.Header.Get("x-client-code"))
+
+	go c.writePump()
+	s.readPump(c)
+
+	s.mu.Lock()
+	delete(s.clients, c)
+	s.mu.Unlock()
+	close(c.send)
+	conn.Close()
+	log.Printf("[wsemu] client disconnected: %s", r.RemoteAddr)
+}
+
+// readPump handles heartbeats and subscription requests until the
+// connection fails.
+func (s *Server) readPump(c *client) {
+	for {
+		mt, msg, err := c.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		if mt != websocket.TextMessage {
+			continue
+		}
+		if string(msg) == smartconnect.HeartBeatMessage {
+			c.push(frame{websocket.TextMessage, []byte("pong")})
+			continue
+		}
+
+		var req request
+		if err := json.Unmarshal(msg, &req); err != nil || req.Action == nil ||
+			req.Params.Mode < smartconnect.ModeLTP || req.Params.Mode > smartconnect.ModeDepth {
+			c.push(errorFrame(req.CorrelationID, "E1002", "Invalid Request Payload."))
+			continue
+		}
+
+		c.mu.Lock()
+		n := 0
+		for _, tl := range req.Params.TokenList {
+			for _, tok := range tl.Tokens {
+				k := subKey{instrumentKey{tl.ExchangeType, tok}, req.Params.Mode}
+				if *req.Action == smartconnect.SubscribeAction {
+					c.subs[k] = struct{}{}
+				} else {
+					delete(c.subs, k)
+				}
+				n++
+			}
+		}
+		c.mu.Unlock()
+		log.Printf("[wsemu] action=%d mode=%s tokens=%d", *req.Action, smartconnect.SubscriptionModeMap[req.Params.Mode], n)
+	}
+}
+
+// writePump serializes writes to the connection.
+func (c *client) writePump() {
+	for f := range c.send {
+		c.conn.SetWriteDeadline(time.Now().Add(5 * time.Second))
+		if err := c.conn.WriteMessage(f.msgType, f.data); err != nil {
+			c.conn.Close() // unblocks readPump
+			for range c.send {
+			}
+			return
+		}
+	}
+}
+
+// push queues a frame, dropping it if the client is too slow.
+func (c *client) push(f frame) {
+	select {
+	case c.send <- f:
+	default:
+	}
+}
+
+func errorFrame(correlationID, code, msg string) frame {
+	b, _ := json.Marshal(map[string]string{
+		"correlationID": correlationID,
+		"errorCode":     code,
+		"errorMessage":  msg,
+	})
+	return frame{websocket.TextMessage, b}
+}
+
+// Publish sends t to every client subscribed to its instrument, once per
+// subscribed mode. Ticks on unknown exchanges are ignored.
+func (s *Server) Publish(t model.Tick) {
+	ex, ok := exchangeTypes[t.Exchange]
+	if !ok {
+		return
+	}
+	key := instrumentKey{ex, t.Token}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st := s.state[key]
+	if st == nil {
+		st = &dayState{}
+		s.state[key] = st
+	}
+	st.update(t)
+
+	var packets [smartconnect.ModeDepth + 1][]byte
+	for c := range s.clients {
+		c.mu.Lock()
+		for mode := smartconnect.ModeLTP; mode <= smartconnect.ModeDepth; mode++ {
+			if _, ok := c.subs[subKey{key, mode}]; !ok {
+				continue
+			}
+			if packets[mode] == nil {
+				packets[mode] = encode(mode, ex, t.Token, st)
+			}
+			c.push(frame{websocket.BinaryMessage, packets[mode]})
+		}
+		c.mu.Unlock()
+	}
+}
+
+// Subscriptions returns the number of (instrument, mode) subscriptions
+// across all connected clients.
+func (s *Server) Subscriptions() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for c := range s.clients {
+		c.mu.Lock()
+		n += len(c.subs)
+		c.mu.Unlock()
+	}
+	return n
+}
+
+// DropClients closes every client connection without a close handshake, as
+// a network failure would, and returns how many were dropped.
+func (s *Server) DropClients() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for c := range s.clients {
+		c.conn.Close()
+	}
+	return len(s.clients)
+}
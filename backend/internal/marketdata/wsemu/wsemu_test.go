@@ -0,0 +1,298 @@
+package wsemu
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"trading-systemv1/internal/marketdata/ws"
+	"trading-systemv1/internal/model"
+	smartconnect "trading-systemv1/pkg/smartconnect"
+
+	"github.com/gorilla/websocket"
+)
+
+func startEmulator(t *testing.T) (*Server, string) {
+	t.Helper()
+	emu := New()
+	srv := httptest.NewServer(emu)
+	t.Cleanup(srv.Close)
+	return emu, "ws" + strings.TrimPrefix(srv.URL, "http")
+}
+
+// waitFor polls cond until it holds or the deadline passes.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func tick(token string, price, qty int64, ts time.Time) model.Tick {
+	return model.Tick{Token: token, Exchange: "NSE", Price: price, Qty: qty, TickTS: ts}
+}
+
+// TestIngest_EndToEnd runs the production ws.Ingest against the emulator,
+// including a dropped connection and the resubscribe that follows.
+func TestIngest_EndToEnd(t *testing.T) {
+	emu, url := startEmulator(t)
+
+	ing, err := ws.New(ws.IngestConfig{
+		AuthToken: "jwt", APIKey: "key", ClientCode: "C1", FeedToken: "feed",
+		SubscribeMode: smartconnect.ModeLTP,
+		TokenList:     []smartconnect.TokenListEntry{{ExchangeType: smartconnect.NSE_CM, Tokens: []string{"2885"}}},
+		URL:           url,
+		RetryDelaySec: 1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	tickCh := make(chan model.Tick, 16)
+	go ing.Start(ctx, tickCh)
+
+	waitFor(t, "subscription", func() bool { return emu.Subscriptions() == 1 })
+	ts := time.Date(2026, time.January, 5, 3, 45, 0, 0, time.UTC)
+	emu.Publish(tick("1594", 90000, 1, ts)) // not subscribed
+	emu.Publish(tick("2885", 245050, 10, ts))
+
+	got := <-tickCh
+	if got.Token != "2885" || got.Exchange != "NSE" || got.Price != 245050 || !got.TickTS.Equal(ts) {
+		t.Fatalf("unexpected tick %+v", got)
+	}
+
+	if emu.DropClients() != 1 {
+		t.Fatal("expected one client to drop")
+	}
+	waitFor(t, "drop", func() bool { return emu.Subscriptions() == 0 })
+	waitFor(t, "resubscribe", func() bool { return emu.Subscriptions() == 1 })
+	emu.Publish(tick("2885", 245100, 5, ts.Add(time.Second)))
+	select {
+	case got = <-tickCh:
+		if got.Price != 245100 {
+			t.Errorf("after reconnect got %+v", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no tick after reconnect")
+	}
+	select {
+	case extra := <-tickCh:
+		t.Errorf("unexpected extra tick %+v", extra)
+	default:
+	}
+}
+
+// TestIngest_QuoteAndDepth checks the SnapQuote and Depth packets reach
+// ws.Ingest's market-data hooks, that SnapQuote ticks carry open interest
+// and that depth produces no ticks.
+func TestIngest_QuoteAndDepth(t *testing.T) {
+	emu, url := startEmulator(t)
+
+	ing, err := ws.New(ws.IngestConfig{
+		AuthToken: "jwt", APIKey: "key", ClientCode: "C1", FeedToken: "feed",
+		SubscribeMode:  smartconnect.ModeSnapQuote,
+		SubscribeDepth: true,
+		TokenList:      []smartconnect.TokenListEntry{{ExchangeType: smartconnect.NSE_CM, Tokens: []string{"2885"}}},
+		URL:            url,
+		RetryDelaySec:  1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	quoteCh := make(chan model.Quote, 4)
+	depthCh := make(chan model.Depth, 4)
+	ing.OnQuote = func(q model.Quote) { quoteCh <- q }
+	ing.OnDepth = func(d model.Depth) { depthCh <- d }
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	tickCh := make(chan model.Tick, 4)
+	go ing.Start(ctx, tickCh)
+
+	waitFor(t, "subscriptions", func() bool { return emu.Subscriptions() == 2 })
+	ts := time.Date(2026, time.January, 5, 3, 45, 0, 0, time.UTC)
+	tk := tick("2885", 1000, 10, ts)
+	tk.OI = 150000
+	emu.Publish(tk)
+
+	q := <-quoteCh
+	if q.Mode != model.QuoteModeSnapQuote || q.Token != "2885" || q.Exchange != "NSE" || q.LTP != 1000 ||
+		q.Volume != 10 || q.TotalBuyQty != 1500 || q.OI != 150000 || !q.TS.Equal(ts) {
+		t.Errorf("unexpected quote %+v", q)
+	}
+	if q.BidPrice != 995 || q.BidQty != 100 || q.AskPrice != 1005 || len(q.Bids) != 5 || q.UpperCircuit != 1200 {
+		t.Errorf("book fields: bid %d×%d ask %d, %d bids, upper %d", q.BidPrice, q.BidQty, q.AskPrice, len(q.Bids), q.UpperCircuit)
+	}
+	d := <-depthCh
+	if len(d.Bids) != 20 || len(d.Asks) != 20 || d.Bids[0] != (model.DepthLevel{Price: 995, Qty: 100, Orders: 1}) {
+		t.Errorf("unexpected depth %+v", d)
+	}
+	if got := <-tickCh; got.Price != 1000 || got.OI != 150000 {
+		t.Errorf("unexpected tick %+v", got)
+	}
+	select {
+	case extra := <-tickCh:
+		t.Errorf("depth packet produced a tick %+v", extra)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+// TestIngest_QuoteVolume checks ticks count the volume traded since the
+// previous packet, including trades missed while disconnected, so their sum
+// matches the day counter.
+func TestIngest_QuoteVolume(t *testing.T) {
+	emu, url := startEmulator(t)
+
+	ing, err := ws.New(ws.IngestConfig{
+		AuthToken: "jwt", APIKey: "key", ClientCode: "C1", FeedToken: "feed",
+		SubscribeMode: smartconnect.ModeQuote,
+		TokenList:     []smartconnect.TokenListEntry{{ExchangeType: smartconnect.NSE_CM, Tokens: []string{"2885"}}},
+		URL:           url,
+		RetryDelaySec: 1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	tickCh := make(chan model.Tick, 16)
+	go ing.Start(ctx, tickCh)
+
+	next := func() model.Tick {
+		t.Helper()
+		select {
+		case got := <-tickCh:
+			return got
+		case <-time.After(5 * time.Second):
+			t.Fatal("no tick")
+		}
+		return model.Tick{}
+	}
+
+	waitFor(t, "subscription", func() bool { return emu.Subscriptions() == 1 })
+	open := time.Date(2026, time.January, 5, 3, 45, 0, 0, time.UTC) // 9:15 IST
+	emu.Publish(tick("2885", 1000, 10, open))
+	emu.Publish(tick("2885", 1001, 30, open.Add(time.Second)))
+	var got []int64
+	for i := 0; i < 2; i++ {
+		got = append(got, next().Qty)
+	}
+
+	emu.DropClients()
+	waitFor(t, "drop", func() bool { return emu.Subscriptions() == 0 })
+	emu.Publish(tick("2885", 1002, 20, open.Add(2*time.Second))) // missed
+	waitFor(t, "resubscribe", func() bool { return emu.Subscriptions() == 1 })
+	emu.Publish(tick("2885", 1003, 5, open.Add(20*time.Second)))
+	got = append(got, next().Qty)
+
+	if want := []int64{10, 30, 25}; got[0] != want[0] || got[1] != want[1] || got[2] != want[2] {
+		t.Errorf("tick volumes %v, want %v", got, want)
+	}
+}
+
+// TestPacketModes decodes every mode with the production parser.
+func TestPacketModes(t *testing.T) {
+	emu, url := startEmulator(t)
+
+	sw, err := smartconnect.NewSmartWebSocketV3("jwt", "key", "C1", "feed", 0, 0, 1, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sw.URL = url
+	var mu sync.Mutex
+	byMode := map[int]map[string]interface{}{}
+	sw.OnData = func(msg map[string]interface{}) {
+		mu.Lock()
+		byMode[msg["subscription_mode"].(int)] = msg
+		mu.Unlock()
+	}
+	if err := sw.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer sw.CloseConnection()
+	nse := []smartconnect.TokenListEntry{{ExchangeType: smartconnect.NSE_CM, Tokens: []string{"2885"}}}
+	for mode := smartconnect.ModeQuote; mode <= smartconnect.ModeDepth; mode++ {
+		if err := sw.Subscribe("t", mode, nse); err != nil {
+			t.Fatal(err)
+		}
+	}
+	waitFor(t, "subscriptions", func() bool { return emu.Subscriptions() == 3 })
+
+	ts := time.Date(2026, time.January, 5, 3, 45, 0, 0, time.UTC)
+	emu.Publish(tick("2885", 1000, 10, ts))
+	emu.Publish(tick("2885", 1200, 30, ts.Add(time.Second)))
+	emu.Publish(tick("2885", 900, 10, ts.Add(2*time.Second)))
+	waitFor(t, "packets", func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(byMode) == 3 && byMode[smartconnect.ModeQuote]["sequence_number"] == int64(3) &&
+			byMode[smartconnect.ModeSnapQuote]["sequence_number"] == int64(3) &&
+			byMode[smartconnect.ModeDepth]["sequence_number"] == int64(3)
+	})
+
+	mu.Lock()
+	defer mu.Unlock()
+	q := byMode[smartconnect.ModeQuote]
+	want := map[string]interface{}{
+		"token": "2885", "exchange_type": 1, "last_traded_price": int64(900), "last_traded_quantity": int64(10),
+		"volume_trade_for_the_day": int64(50), "average_traded_price": int64(1100),
+		"open_price_of_the_day": int64(1000), "high_price_of_the_day": int64(1200), "low_price_of_the_day": int64(900),
+		"closed_price": int64(1000), "exchange_timestamp": ts.Add(2 * time.Second).UnixMilli(),
+	}
+	for k, v := range want {
+		if q[k] != v {
+			t.Errorf("quote %s = %v (%T), want %v", k, q[k], q[k], v)
+		}
+	}
+
+	sq := byMode[smartconnect.ModeSnapQuote]
+	buys := sq["best_5_buy_data"].([]map[string]interface{})
+	sells := sq["best_5_sell_data"].([]map[string]interface{})
+	if len(buys) != 5 || len(sells) != 5 || buys[0]["price"] != int64(895) || sells[0]["price"] != int64(905) {
+		t.Errorf("best five: buys %v sells %v", buys, sells)
+	}
+	if sq["upper_circuit_limit"] != int64(1200) || sq["lower_circuit_limit"] != int64(800) {
+		t.Errorf("circuit limits %v / %v", sq["upper_circuit_limit"], sq["lower_circuit_limit"])
+	}
+
+	d := byMode[smartconnect.ModeDepth]
+	dbuy := d["depth_20_buy_data"].([]map[string]interface{})
+	dsell := d["depth_20_sell_data"].([]map[string]interface{})
+	if len(dbuy) != 20 || len(dsell) != 20 || dbuy[19]["price"] != int32(800) || dsell[0]["price"] != int32(905) {
+		t.Errorf("depth: buy %v sell %v", dbuy, dsell)
+	}
+}
+
+func TestAuthAndHeartbeat(t *testing.T) {
+	_, url := startEmulator(t)
+
+	if _, resp, err := websocket.DefaultDialer.Dial(url, nil); err == nil || resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without auth headers, got %v", err)
+	}
+
+	h := http.Header{}
+	for _, k := range authHeaders {
+		h.Set(k, "x")
+	}
+	conn, _, err := websocket.DefaultDialer.Dial(url, h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.WriteMessage(websocket.TextMessage, []byte("ping"))
+	if _, msg, err := conn.ReadMessage(); err != nil || string(msg) != "pong" {
+		t.Fatalf("heartbeat reply %q, %v", msg, err)
+	}
+	conn.WriteMessage(websocket.TextMessage, []byte(`{"action":1,"params":{"mode":9}}`))
+	if _, msg, err := conn.ReadMessage(); err != nil || !strings.Contains(string(msg), "E1002") {
+		t.Fatalf("invalid request reply %q, %v", msg, err)
+	}
+}
@@ -6,7 +6,7 @@ import (
 )
 
 // IST is the Indian Standard Time location (UTC+5:30).
-var IST = time.FixedZone("IST", 5*3600+30*60)
+var IST = time.FixedZone("IST", istOffset)
 
 // Market hours in IST
 const (
@@ -89,6 +89,66 @@ func TodayClose(t time.Time) time.Time {
 	return time.Date(ist.Year(), ist.Month(), ist.Day(), CloseHour, CloseMinute, 0, 0, IST)
 }
 
+// SessionOpen returns the 9:15 AM IST open on t's IST calendar date.
+// Candles of the same trading session share the same SessionOpen.
+func SessionOpen(t time.Time) time.Time {
+	ist := t.In(IST)
+	return time.Date(ist.Year(), ist.Month(), ist.Day(), OpenHour, OpenMinute, 0, 0, IST)
+}
+
+// CurrentOrNextOpen returns the open of the session in progress at t, or
+// of the next session if the market has closed for the day or t is not a
+// trading day. Before today's open on a trading day it returns today's open.
+func CurrentOrNextOpen(t time.Time) time.Time {
+	ist := t.In(IST)
+	if IsTradingDay(ist) && ist.Before(TodayClose(ist)) {
+		return SessionOpen(ist)
+	}
+	return NextOpen(ist)
+}
+
+// PrevOpen returns the open of the last trading day before t's IST date.
+func PrevOpen(t time.Time) time.Time {
+	ist := t.In(IST)
+	d := time.Date(ist.Year(), ist.Month(), ist.Day(), OpenHour, OpenMinute, 0, 0, IST)
+	for i := 0; i < 10; i++ { // max 10 days back (holidays + weekends)
+		d = d.AddDate(0, 0, -1)
+		if IsTradingDay(d) {
+			return d
+		}
+	}
+	// Fallback: previous day
+	return time.Date(ist.Year(), ist.Month(), ist.Day()-1, OpenHour, OpenMinute, 0, 0, IST)
+}
+
+// WeekOpen returns the open of the first trading day of t's IST week:
+// Monday, or the next weekday when Monday is a holiday.
+func WeekOpen(t time.Time) time.Time {
+	ist := t.In(IST)
+	offset := (int(ist.Weekday()) + 6) % 7 // days since Monday
+	return firstTradingOpen(time.Date(ist.Year(), ist.Month(), ist.Day()-offset, OpenHour, OpenMinute, 0, 0, IST), 5)
+}
+
+// MonthOpen returns the open of the first trading day of t's IST month,
+// skipping weekends and holidays.
+func MonthOpen(t time.Time) time.Time {
+	ist := t.In(IST)
+	return firstTradingOpen(time.Date(ist.Year(), ist.Month(), 1, OpenHour, OpenMinute, 0, 0, IST), 10)
+}
+
+// firstTradingOpen returns the open of the first trading day among the
+// days days starting at open, or open itself if none is.
+func firstTradingOpen(open time.Time, days int) time.Time {
+	d := open
+	for i := 0; i < days; i++ {
+		if IsTradingDay(d) {
+			return d
+		}
+		d = d.AddDate(0, 0, 1)
+	}
+	return open
+}
+
 // TimeUntilClose returns the duration until today's close.
 // Returns 0 if market is already closed.
 func TimeUntilClose(t time.Time) time.Duration {
@@ -0,0 +1,145 @@
+package markethours
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"trading-systemv1/internal/model"
+)
+
+// Calendar timeframes. Their buckets follow the session calendar rather
+// than a fixed duration; the values are nominal lengths in seconds so they
+// share the TF-in-seconds stream keys and candles_tf rows with intraday TFs.
+const (
+	TFDay   = 86400      // one trading session, bucket TS = SessionOpen
+	TFWeek  = 7 * TFDay  // bucket TS = WeekOpen
+	TFMonth = 30 * TFDay // bucket TS = MonthOpen
+)
+
+// IsCalendarTF reports whether tf is one of TFDay, TFWeek or TFMonth.
+func IsCalendarTF(tf int) bool {
+	return tf == TFDay || tf == TFWeek || tf == TFMonth
+}
+
+// ParseTF parses a timeframe: seconds ("300"), minutes or hours ("5m",
+// "75m", "1h"), a calendar alias ("D", "W", "M", optionally prefixed with 1;
+// D and W in either case, but month only as upper-case "M" or "MN", since
+// "1m" is a minute), a key segment such as "300s" or the bar series
+// "tick500", "vol100000", "range1000", "renko500", or any of those behind
+// "HA " for Heikin-Ashi. It accepts every label TFLabel returns.
+func ParseTF(s string) (int, error) {
+	s = strings.TrimSpace(s)
+	if tf, ok := model.ParseTFKey(s); ok {
+		return tf, nil
+	}
+	if len(s) > 3 && strings.EqualFold(s[:3], "HA ") {
+		tf, err := ParseTF(s[3:])
+		if err != nil || model.IsHATF(tf) {
+			return 0, fmt.Errorf("invalid timeframe %q", s)
+		}
+		return model.HATF(tf), nil
+	}
+	switch strings.TrimPrefix(s, "1") {
+	case "D", "d":
+		return TFDay, nil
+	case "W", "w":
+		return TFWeek, nil
+	case "M", "MN":
+		return TFMonth, nil
+	}
+	num, unit := s, 1
+	switch {
+	case strings.HasSuffix(s, "m"):
+		num, unit = s[:len(s)-1], 60
+	case strings.HasSuffix(s, "h"), strings.HasSuffix(s, "H"):
+		num, unit = s[:len(s)-1], 3600
+	}
+	n, err := strconv.Atoi(num)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid timeframe %q", s)
+	}
+	return n * unit, nil
+}
+
+// BucketStart returns the Unix start of the tf bucket containing ts.
+//
+// Intraday buckets are anchored to the 9:15 IST open, so a 75m TF yields
+// 9:15, 10:30, … 14:15 and the session's last bucket may be cut short by
+// the next day's anchor. Before the open, ts stays on the previous day's
+// grid. TFs dividing 15 minutes align exactly as they would on the epoch.
+// Calendar TFs start at the session, week or month open.
+//
+// Intraday TFs and TFDay are pure integer arithmetic (IST has no DST, so
+// every IST day opens at the same offset); only TFWeek and TFMonth go
+// through time.Time and the holiday calendar.
+func BucketStart(ts int64, tf int) int64 {
+	switch tf {
+	case TFWeek:
+		return WeekOpen(time.Unix(ts, 0)).Unix()
+	case TFMonth:
+		return MonthOpen(time.Unix(ts, 0)).Unix()
+	}
+	anchor := sessionOpenUnix(ts)
+	if tf == TFDay {
+		return anchor
+	}
+	if ts < anchor {
+		anchor -= secondsPerDay
+	}
+	tf64 := int64(tf)
+	return anchor + (ts-anchor)/tf64*tf64
+}
+
+const (
+	secondsPerDay = 24 * 3600
+	istOffset     = 5*3600 + 30*60 // IST - UTC, in seconds
+	openOffset    = OpenHour*3600 + OpenMinute*60
+)
+
+// sessionOpenUnix is SessionOpen(time.Unix(ts, 0)).Unix() without the
+// time.Time conversions: the 9:15 IST open on ts's IST calendar date.
+func sessionOpenUnix(ts int64) int64 {
+	local := ts + istOffset
+	day := local / secondsPerDay
+	if local%secondsPerDay < 0 {
+		day-- // floor for instants before the epoch
+	}
+	return day*secondsPerDay - istOffset + openOffset
+}
+
+// PeriodEnds reports whether the session containing t closes tf's bucket:
+// always for intraday TFs and TFDay, for TFWeek and TFMonth only on the
+// period's last trading day (holidays skipped).
+func PeriodEnds(tf int, t time.Time) bool {
+	switch tf {
+	case TFWeek:
+		return !WeekOpen(NextOpen(t)).Equal(WeekOpen(t))
+	case TFMonth:
+		return !MonthOpen(NextOpen(t)).Equal(MonthOpen(t))
+	}
+	return true
+}
+
+// TFLabel returns a short label: "45s", "5m", "75m", "1h", "D", "W", "M",
+// the key of a bar series ("tick500"), or "HA 5m" for Heikin-Ashi.
+func TFLabel(tf int) string {
+	switch {
+	case model.IsHATF(tf):
+		return "HA " + TFLabel(model.BaseTF(tf))
+	case tf == TFDay:
+		return "D"
+	case tf == TFWeek:
+		return "W"
+	case tf == TFMonth:
+		return "M"
+	case model.IsBarTF(tf):
+		return model.TFKey(tf)
+	case tf < 60 || tf%60 != 0:
+		return strconv.Itoa(tf) + "s"
+	case tf < 3600 || tf%3600 != 0:
+		return strconv.Itoa(tf/60) + "m"
+	}
+	return strconv.Itoa(tf/3600) + "h"
+}
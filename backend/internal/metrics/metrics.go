@@ -55,6 +55,14 @@ type Metrics struct {
 	WatermarkDelay   prometheus.Gauge     // current watermark delay vs wall clock
 	LateTicks        prometheus.Counter   // ticks dropped behind watermark
 	ReorderBufferLen prometheus.Gauge     // current reorder buffer occupancy
+	CandleAmendments prometheus.Counter   // 1s candles revised by late ticks
+	GapFilledCandles prometheus.Counter   // synthetic 1s candles filling empty seconds
+
+	// Raw tick archive
+	TickArchiveDrops prometheus.Counter // ticks not archived because the writer fell behind
+
+	// Quote / depth market data
+	MarketDataDrops prometheus.Counter // quotes and books dropped because the Redis writer fell behind
 
 	// Market session state (ADR-006)
 	MarketState        prometheus.Gauge       // 0=closed, 1=open
@@ -177,6 +185,26 @@ func NewMetrics() *Metrics {
 			Name: "mdengine_reorder_buffer_len",
 			Help: "Current number of candle buckets held in the reorder buffer",
 		}),
+		CandleAmendments: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "mdengine_candle_amendments_total",
+			Help: "Finalized 1s candles revised by late ticks within CANDLE_AMEND_WINDOW",
+		}),
+		GapFilledCandles: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "mdengine_gap_filled_candles_total",
+			Help: "Synthetic flat 1s candles emitted for seconds without ticks (GAP_FILL)",
+		}),
+
+		// Tick archive
+		TickArchiveDrops: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "mdengine_tick_archive_drops_total",
+			Help: "Ticks not archived because the tick archive channel was full",
+		}),
+
+		// Quote / depth market data
+		MarketDataDrops: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "mdengine_market_data_drops_total",
+			Help: "Quotes and order books dropped because the market data channel was full",
+		}),
 
 		// Market session (ADR-006)
 		MarketState: prometheus.NewGauge(prometheus.GaugeOpts{
@@ -213,6 +241,10 @@ func NewMetrics() *Metrics {
 		m.WatermarkDelay,
 		m.LateTicks,
 		m.ReorderBufferLen,
+		m.CandleAmendments,
+		m.GapFilledCandles,
+		m.TickArchiveDrops,
+		m.MarketDataDrops,
 		m.MarketState,
 		m.SessionTransitions,
 	)
@@ -0,0 +1,101 @@
+package model
+
+import "strconv"
+
+// Bar kinds: bars that close on activity rather than time.
+const (
+	BarTick   = 1 // closes after N ticks
+	BarVolume = 2 // closes once its volume reaches N
+	BarRange  = 3 // closes once its high-low range reaches N paise
+	BarRenko  = 4 // bricks of N paise on closing prices
+)
+
+// barKindBase encodes a bar series in the int TF used by streams, the
+// indicator engine and the gateway: tf = kind*barKindBase + size. It lies far
+// above any time TF (the largest, the nominal month, is 2,592,000 s), so
+// requires a 64-bit int.
+const barKindBase = 1_000_000_000_000
+
+// barKindNames are the key prefixes, indexed by kind: "tick500", "vol100000".
+var barKindNames = [...]string{"", "tick", "vol", "range", "renko"}
+
+// BarTF returns the TF identity of a bar series.
+func BarTF(kind int, size int64) int {
+	return kind*barKindBase + int(size)
+}
+
+// IsBarTF reports whether tf identifies a bar series rather than a time TF,
+// including the Heikin-Ashi series derived from one.
+func IsBarTF(tf int) bool {
+	return BaseTF(tf) >= barKindBase
+}
+
+// BarSpec decodes a bar TF into its kind and size.
+func BarSpec(tf int) (kind int, size int64) {
+	tf = BaseTF(tf)
+	return tf / barKindBase, int64(tf % barKindBase)
+}
+
+// haBase marks a Heikin-Ashi series: tf = haBase + the TF it is derived from,
+// which may itself be a bar series.
+const haBase = 100 * barKindBase
+
+// HATF returns the TF identity of the Heikin-Ashi series derived from tf.
+func HATF(tf int) int {
+	return haBase + BaseTF(tf)
+}
+
+// IsHATF reports whether tf identifies a Heikin-Ashi series.
+func IsHATF(tf int) bool {
+	return tf >= haBase
+}
+
+// BaseTF strips the Heikin-Ashi marker: the TF whose candles a series is built from.
+func BaseTF(tf int) int {
+	return tf % haBase
+}
+
+// TFKey returns the TF segment of Redis keys and channels: "60s" for time
+// TFs, "tick500", "vol100000", "range1000" or "renko500" for bar series, and
+// an "ha" prefix for Heikin-Ashi series ("ha60s", "hatick500").
+func TFKey(tf int) string {
+	if IsHATF(tf) {
+		return "ha" + TFKey(BaseTF(tf))
+	}
+	if IsBarTF(tf) {
+		kind, size := BarSpec(tf)
+		if kind < len(barKindNames) {
+			return barKindNames[kind] + Itoa(int(size))
+		}
+	}
+	return Itoa(tf) + "s"
+}
+
+// ParseTFKey parses a TFKey segment ("60s", "tick500", "ha60s") back to its TF.
+func ParseTFKey(s string) (int, bool) {
+	if len(s) > 2 && s[:2] == "ha" {
+		tf, ok := ParseTFKey(s[2:])
+		if !ok || IsHATF(tf) {
+			return 0, false
+		}
+		return HATF(tf), true
+	}
+	for kind := BarTick; kind < len(barKindNames); kind++ {
+		name := barKindNames[kind]
+		if len(s) > len(name) && s[:len(name)] == name {
+			size, err := strconv.ParseInt(s[len(name):], 10, 64)
+			if err != nil || size <= 0 || size >= barKindBase {
+				return 0, false
+			}
+			return BarTF(kind, size), true
+		}
+	}
+	if len(s) < 2 || s[len(s)-1] != 's' {
+		return 0, false
+	}
+	n, err := strconv.Atoi(s[:len(s)-1])
+	if err != nil || n <= 0 || n >= barKindBase {
+		return 0, false
+	}
+	return n, true
+}
@@ -10,13 +10,17 @@ import (
 type Candle struct {
 	Token      string    `json:"token"`
 	Exchange   string    `json:"exchange"`
-	TS         time.Time `json:"ts"`          // bucket start time (UTC, second-aligned)
-	Open       int64     `json:"open"`        // paise
-	High       int64     `json:"high"`        // paise
-	Low        int64     `json:"low"`         // paise
-	Close      int64     `json:"close"`       // paise
-	Volume     int64     `json:"volume"`      // cumulative quantity in this second
-	TicksCount int       `json:"ticks_count"` // number of ticks aggregated
+	TS         time.Time `json:"ts"`                  // bucket start time (UTC, second-aligned)
+	Open       int64     `json:"open"`                // paise
+	High       int64     `json:"high"`                // paise
+	Low        int64     `json:"low"`                 // paise
+	Close      int64     `json:"close"`               // paise
+	Volume     int64     `json:"volume"`              // cumulative quantity in this second
+	TicksCount int       `json:"ticks_count"`         // number of ticks aggregated
+	OIOpen     int64     `json:"oi_open,omitempty"`   // first open interest seen in this second (F&O)
+	OIClose    int64     `json:"oi_close,omitempty"`  // last open interest seen in this second
+	Revision   int       `json:"revision,omitempty"`  // >0: amended by late ticks after it was finalized
+	Synthetic  bool      `json:"synthetic,omitempty"` // gap fill for a second without ticks: flat at the previous close, no volume
 }
 
 // Key returns a unique key for this candle's instrument: "exchange:token".
@@ -24,6 +28,12 @@ func (c *Candle) Key() string {
 	return c.Exchange + ":" + c.Token
 }
 
+// AmendChannel returns the PubSub channel for revisions of this candle:
+// "pub:amend:1s:{exchange}:{token}".
+func (c *Candle) AmendChannel() string {
+	return "pub:amend:1s:" + c.Exchange + ":" + c.Token
+}
+
 // JSON returns the JSON-encoded candle (ignoring errors for hot-path usage).
 func (c *Candle) JSON() []byte {
 	b, _ := json.Marshal(c)
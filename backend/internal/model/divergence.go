@@ -0,0 +1,46 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Divergence kinds.
+const (
+	DivRegularBullish = "regular_bullish" // price lower low, oscillator higher low
+	DivRegularBearish = "regular_bearish" // price higher high, oscillator lower high
+	DivHiddenBullish  = "hidden_bullish"  // price higher low, oscillator lower low
+	DivHiddenBearish  = "hidden_bearish"  // price lower high, oscillator higher high
+)
+
+// DivergenceEvent is a price/oscillator divergence between two swing pivots.
+type DivergenceEvent struct {
+	Kind       string    `json:"kind"`       // DivRegularBullish, ...
+	Oscillator string    `json:"oscillator"` // indicator result name, e.g. "RSI_14"
+	Token      string    `json:"token"`
+	Exchange   string    `json:"exchange"`
+	TF         int       `json:"tf"`        // timeframe in seconds
+	TS         time.Time `json:"ts"`        // candle that confirmed the second pivot
+	Pivot1TS   time.Time `json:"pivot1_ts"` // earlier pivot
+	Pivot2TS   time.Time `json:"pivot2_ts"` // later pivot
+	Price1     int64     `json:"price1"`    // pivot low/high in paise
+	Price2     int64     `json:"price2"`
+	Osc1       float64   `json:"osc1"` // oscillator at the pivots
+	Osc2       float64   `json:"osc2"`
+}
+
+// StreamKey returns the Redis stream key: "div:{TF}s:{exchange}:{token}".
+func (d *DivergenceEvent) StreamKey() string {
+	return "div:" + TFKey(d.TF) + ":" + d.Exchange + ":" + d.Token
+}
+
+// PubSubChannel returns the Redis PubSub channel: "pub:div:{TF}s:{exchange}:{token}".
+func (d *DivergenceEvent) PubSubChannel() string {
+	return "pub:div:" + TFKey(d.TF) + ":" + d.Exchange + ":" + d.Token
+}
+
+// JSON returns the JSON-encoded divergence event.
+func (d *DivergenceEvent) JSON() []byte {
+	b, _ := json.Marshal(d)
+	return b
+}
@@ -0,0 +1,68 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// PivotLevels is one family of pivot levels. All prices are in paise.
+// R4/S4 are only set by families that define them (Camarilla).
+type PivotLevels struct {
+	P  int64 `json:"p"`
+	R1 int64 `json:"r1"`
+	R2 int64 `json:"r2"`
+	R3 int64 `json:"r3"`
+	R4 int64 `json:"r4,omitempty"`
+	S1 int64 `json:"s1"`
+	S2 int64 `json:"s2"`
+	S3 int64 `json:"s3"`
+	S4 int64 `json:"s4,omitempty"`
+}
+
+// CPR is the Central Pivot Range: top central, pivot and bottom central
+// levels in paise, with TC >= BC.
+type CPR struct {
+	TC    int64 `json:"tc"`
+	Pivot int64 `json:"pivot"`
+	BC    int64 `json:"bc"`
+}
+
+// SessionLevels holds the price levels for one trading session, derived from
+// the previous session's OHLC.
+type SessionLevels struct {
+	Token       string    `json:"token"`
+	Exchange    string    `json:"exchange"`
+	Session     time.Time `json:"session"`      // open of the session the levels apply to
+	PrevSession time.Time `json:"prev_session"` // open of the session they were computed from
+
+	PrevOpen  int64 `json:"prev_open"` // paise
+	PrevHigh  int64 `json:"prev_high"`
+	PrevLow   int64 `json:"prev_low"`
+	PrevClose int64 `json:"prev_close"`
+
+	Classic   PivotLevels `json:"classic"`
+	Fibonacci PivotLevels `json:"fibonacci"`
+	Camarilla PivotLevels `json:"camarilla"`
+	CPR       CPR         `json:"cpr"`
+}
+
+// Key returns "exchange:token".
+func (l *SessionLevels) Key() string {
+	return l.Exchange + ":" + l.Token
+}
+
+// RedisKey returns the Redis key holding the current levels: "levels:{exchange}:{token}".
+func (l *SessionLevels) RedisKey() string {
+	return "levels:" + l.Exchange + ":" + l.Token
+}
+
+// PubSubChannel returns the Redis PubSub channel: "pub:levels:{exchange}:{token}".
+func (l *SessionLevels) PubSubChannel() string {
+	return "pub:levels:" + l.Exchange + ":" + l.Token
+}
+
+// JSON returns the JSON-encoded session levels.
+func (l *SessionLevels) JSON() []byte {
+	b, _ := json.Marshal(l)
+	return b
+}
@@ -0,0 +1,47 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// OI buildup classes: how price and open interest moved together over a bar.
+const (
+	BuildupLong          = "long_buildup"   // price up, OI up
+	BuildupShort         = "short_buildup"  // price down, OI up
+	BuildupShortCovering = "short_covering" // price up, OI down
+	BuildupLongUnwinding = "long_unwinding" // price down, OI down
+)
+
+// OIBar is the open interest analytics of one finalized F&O bar. Changes
+// are against the previous bar of the series, or against the bar's own
+// open for the first one.
+type OIBar struct {
+	Token       string    `json:"token"`
+	Exchange    string    `json:"exchange"`
+	TF          int       `json:"tf"`           // timeframe in seconds
+	TS          time.Time `json:"ts"`           // bar start
+	Close       int64     `json:"close"`        // paise
+	PriceChange int64     `json:"price_change"` // paise
+	OIOpen      int64     `json:"oi_open"`
+	OIClose     int64     `json:"oi_close"`
+	OIChange    int64     `json:"oi_change"`
+	OIChangePct float64   `json:"oi_change_pct"`
+	Buildup     string    `json:"buildup"` // BuildupLong, ...; "" when price or OI is unchanged
+}
+
+// StreamKey returns the Redis stream key: "oi:{TF}s:{exchange}:{token}".
+func (b *OIBar) StreamKey() string {
+	return "oi:" + TFKey(b.TF) + ":" + b.Exchange + ":" + b.Token
+}
+
+// PubSubChannel returns the Redis PubSub channel: "pub:oi:{TF}s:{exchange}:{token}".
+func (b *OIBar) PubSubChannel() string {
+	return "pub:oi:" + TFKey(b.TF) + ":" + b.Exchange + ":" + b.Token
+}
+
+// JSON returns the JSON-encoded OI bar.
+func (b *OIBar) JSON() []byte {
+	out, _ := json.Marshal(b)
+	return out
+}
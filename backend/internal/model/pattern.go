@@ -0,0 +1,44 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Pattern bias values.
+const (
+	BiasBullish = "bullish"
+	BiasBearish = "bearish"
+	BiasNeutral = "neutral"
+)
+
+// PatternEvent is a candlestick pattern detected on finalized TF candles.
+type PatternEvent struct {
+	Name     string    `json:"name"` // e.g. "BULLISH_ENGULFING", "DOJI"
+	Token    string    `json:"token"`
+	Exchange string    `json:"exchange"`
+	TF       int       `json:"tf"`       // timeframe in seconds
+	TS       time.Time `json:"ts"`       // candle that completed the pattern
+	StartTS  time.Time `json:"start_ts"` // first candle of the pattern
+	Bars     int       `json:"bars"`     // number of candles in the pattern
+	Bias     string    `json:"bias"`     // BiasBullish, BiasBearish or BiasNeutral
+}
+
+// StreamKey returns the Redis stream key: "pattern:{TF}s:{exchange}:{token}".
+// All patterns of a token and TF share one stream so a chart can load its
+// markers with a single read.
+func (p *PatternEvent) StreamKey() string {
+	return "pattern:" + TFKey(p.TF) + ":" + p.Exchange + ":" + p.Token
+}
+
+// PubSubChannel returns the Redis PubSub channel for this pattern:
+// "pub:pattern:{name}:{TF}s:{exchange}:{token}", mirroring pub:ind:*.
+func (p *PatternEvent) PubSubChannel() string {
+	return "pub:pattern:" + p.Name + ":" + TFKey(p.TF) + ":" + p.Exchange + ":" + p.Token
+}
+
+// JSON returns the JSON-encoded pattern event.
+func (p *PatternEvent) JSON() []byte {
+	b, _ := json.Marshal(p)
+	return b
+}
@@ -0,0 +1,107 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// DepthLevel is one price level of the order book. Price is in paise.
+type DepthLevel struct {
+	Price  int64 `json:"price"`
+	Qty    int64 `json:"qty"`
+	Orders int   `json:"orders"`
+}
+
+// Quote modes, as carried in Quote.Mode.
+const (
+	QuoteModeQuote     = "quote"     // LTP, day OHLC, volume, total buy/sell qty
+	QuoteModeSnapQuote = "snapquote" // Quote plus best five, OI and circuit limits
+)
+
+// Quote is the market picture of one instrument from a Quote or SnapQuote
+// packet. All prices are in paise. Best bid/ask, the best-five book, OI and
+// the circuit limits are only set in SnapQuote mode.
+type Quote struct {
+	Token    string    `json:"token"`
+	Exchange string    `json:"exchange"`
+	Mode     string    `json:"mode"` // QuoteModeQuote or QuoteModeSnapQuote
+	TS       time.Time `json:"ts"`   // exchange timestamp
+
+	LTP      int64 `json:"ltp"`
+	LastQty  int64 `json:"last_qty"`
+	AvgPrice int64 `json:"avg_price"`
+	Volume   int64 `json:"volume"` // cumulative day volume
+
+	TotalBuyQty  int64 `json:"total_buy_qty"`
+	TotalSellQty int64 `json:"total_sell_qty"`
+
+	Open      int64 `json:"open"` // day open/high/low
+	High      int64 `json:"high"`
+	Low       int64 `json:"low"`
+	PrevClose int64 `json:"prev_close"` // previous session's close
+
+	BidPrice int64        `json:"bid_price,omitempty"`
+	BidQty   int64        `json:"bid_qty,omitempty"`
+	AskPrice int64        `json:"ask_price,omitempty"`
+	AskQty   int64        `json:"ask_qty,omitempty"`
+	Bids     []DepthLevel `json:"bids,omitempty"` // best five, best first
+	Asks     []DepthLevel `json:"asks,omitempty"`
+
+	OI           int64 `json:"oi,omitempty"`
+	UpperCircuit int64 `json:"upper_circuit,omitempty"`
+	LowerCircuit int64 `json:"lower_circuit,omitempty"`
+	High52W      int64 `json:"high_52w,omitempty"`
+	Low52W       int64 `json:"low_52w,omitempty"`
+}
+
+// Key returns "exchange:token".
+func (q *Quote) Key() string {
+	return q.Exchange + ":" + q.Token
+}
+
+// RedisKey returns the Redis key holding the latest quote: "quote:{exchange}:{token}".
+func (q *Quote) RedisKey() string {
+	return "quote:" + q.Exchange + ":" + q.Token
+}
+
+// PubSubChannel returns the Redis PubSub channel: "pub:quote:{exchange}:{token}".
+func (q *Quote) PubSubChannel() string {
+	return "pub:quote:" + q.Exchange + ":" + q.Token
+}
+
+// JSON returns the JSON-encoded quote.
+func (q *Quote) JSON() []byte {
+	b, _ := json.Marshal(q)
+	return b
+}
+
+// Depth is the 20-level order book of one instrument from a Depth packet.
+// Empty levels are omitted; both sides are ordered best first.
+type Depth struct {
+	Token    string       `json:"token"`
+	Exchange string       `json:"exchange"`
+	TS       time.Time    `json:"ts"` // packet time
+	Bids     []DepthLevel `json:"bids"`
+	Asks     []DepthLevel `json:"asks"`
+}
+
+// Key returns "exchange:token".
+func (d *Depth) Key() string {
+	return d.Exchange + ":" + d.Token
+}
+
+// RedisKey returns the Redis key holding the latest book: "depth:{exchange}:{token}".
+func (d *Depth) RedisKey() string {
+	return "depth:" + d.Exchange + ":" + d.Token
+}
+
+// PubSubChannel returns the Redis PubSub channel: "pub:depth:{exchange}:{token}".
+func (d *Depth) PubSubChannel() string {
+	return "pub:depth:" + d.Exchange + ":" + d.Token
+}
+
+// JSON returns the JSON-encoded book.
+func (d *Depth) JSON() []byte {
+	b, _ := json.Marshal(d)
+	return b
+}
@@ -1,7 +1,10 @@
 package model
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
+	"math"
 	"strconv"
 	"time"
 )
@@ -10,17 +13,21 @@ import (
 // TF is the timeframe duration in seconds (e.g., 60 = 1 minute).
 // All prices are in paise (int64) to avoid floating-point drift.
 type TFCandle struct {
-	Token    string    `json:"token"`
-	Exchange string    `json:"exchange"`
-	TF       int       `json:"tf"`      // timeframe in seconds
-	TS       time.Time `json:"ts"`      // bucket start time (UTC, TF-aligned)
-	Open     int64     `json:"open"`    // paise
-	High     int64     `json:"high"`    // paise
-	Low      int64     `json:"low"`     // paise
-	Close    int64     `json:"close"`   // paise
-	Volume   int64     `json:"volume"`  // cumulative quantity
-	Count    int       `json:"count"`   // number of 1s candles merged
-	Forming  bool      `json:"forming"` // true if bucket is still open
+	Token     string    `json:"token"`
+	Exchange  string    `json:"exchange"`
+	TF        int       `json:"tf"`                  // timeframe in seconds
+	TS        time.Time `json:"ts"`                  // bucket start time (UTC, TF-aligned)
+	Open      int64     `json:"open"`                // paise
+	High      int64     `json:"high"`                // paise
+	Low       int64     `json:"low"`                 // paise
+	Close     int64     `json:"close"`               // paise
+	Volume    int64     `json:"volume"`              // cumulative quantity
+	Count     int       `json:"count"`               // number of 1s candles merged
+	Forming   bool      `json:"forming"`             // true if bucket is still open
+	OIOpen    int64     `json:"oi_open,omitempty"`   // first open interest of the bar (F&O); 0 = none
+	OIClose   int64     `json:"oi_close,omitempty"`  // last open interest of the bar
+	Revision  int       `json:"revision,omitempty"`  // >0: finalized bar amended by late ticks
+	Synthetic bool      `json:"synthetic,omitempty"` // built from gap-fill candles only: no trade in the bar
 }
 
 // Key returns "exchange:token".
@@ -28,9 +35,26 @@ func (c *TFCandle) Key() string {
 	return c.Exchange + ":" + c.Token
 }
 
+// MergeOI folds the open interest of a later sub-candle into the bar: the
+// first non-zero reading is the bar's OIOpen, the latest its OIClose.
+func (c *TFCandle) MergeOI(oiOpen, oiClose int64) {
+	if c.OIOpen == 0 {
+		c.OIOpen = oiOpen
+	}
+	if oiClose != 0 {
+		c.OIClose = oiClose
+	}
+}
+
 // StreamKey returns the Redis stream key: "candle:{TF}s:{exchange}:{token}".
 func (c *TFCandle) StreamKey() string {
-	return "candle:" + Itoa(c.TF) + "s:" + c.Exchange + ":" + c.Token
+	return "candle:" + TFKey(c.TF) + ":" + c.Exchange + ":" + c.Token
+}
+
+// AmendChannel returns the PubSub channel for revisions of finalized bars:
+// "pub:amend:{TF}s:{exchange}:{token}".
+func (c *TFCandle) AmendChannel() string {
+	return "pub:amend:" + TFKey(c.TF) + ":" + c.Exchange + ":" + c.Token
 }
 
 // JSON returns the JSON-encoded TF candle.
@@ -49,17 +73,110 @@ type IndicatorResult struct {
 	TS       time.Time `json:"ts"`    // candle timestamp that produced this value
 	Ready    bool      `json:"ready"` // true when indicator has enough data
 	Live     bool      `json:"live"`  // true for preview values from forming candles
+
+	// Components holds named outputs for multi-output indicators
+	// (e.g. MACD macd/signal/hist). Value mirrors the first component.
+	// Nil for single-output indicators.
+	Components IndicatorComponents `json:"components,omitempty"`
+}
+
+// IndicatorComponent is a single named output of a multi-output indicator.
+type IndicatorComponent struct {
+	Name  string
+	Value float64
+}
+
+// IndicatorComponents is an ordered list of named indicator outputs.
+// It encodes as a JSON object ({"upper":1.0,"mid":0.5,...}) preserving order.
+type IndicatorComponents []IndicatorComponent
+
+// Get returns the value of the named component and whether it exists.
+func (c IndicatorComponents) Get(name string) (float64, bool) {
+	for _, comp := range c {
+		if comp.Name == name {
+			return comp.Value, true
+		}
+	}
+	return 0, false
+}
+
+// appendJSON appends the components as a JSON object to buf.
+func (c IndicatorComponents) appendJSON(buf []byte) []byte {
+	buf = append(buf, '{')
+	for i, comp := range c {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		buf = append(buf, '"')
+		buf = append(buf, comp.Name...)
+		buf = append(buf, `":`...)
+		buf = appendFloat(buf, comp.Value)
+	}
+	return append(buf, '}')
+}
+
+// appendFloat appends v with 4 decimals, or null if v is NaN or ±Inf,
+// which JSON cannot represent.
+func appendFloat(buf []byte, v float64) []byte {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return append(buf, "null"...)
+	}
+	return strconv.AppendFloat(buf, v, 'f', 4, 64)
+}
+
+// MarshalJSON encodes the components as an ordered JSON object.
+func (c IndicatorComponents) MarshalJSON() ([]byte, error) {
+	if c == nil {
+		return []byte("null"), nil
+	}
+	return c.appendJSON(make([]byte, 0, 16*len(c)+2)), nil
+}
+
+// UnmarshalJSON decodes a JSON object into components, preserving key order.
+// A null value (non-finite when encoded) decodes as NaN.
+func (c *IndicatorComponents) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok == nil {
+		*c = nil
+		return nil
+	}
+	if d, ok := tok.(json.Delim); !ok || d != '{' {
+		return fmt.Errorf("indicator components: expected object, got %v", tok)
+	}
+	var out IndicatorComponents
+	for dec.More() {
+		keyTok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		key, _ := keyTok.(string)
+		var v *float64
+		if err := dec.Decode(&v); err != nil {
+			return err
+		}
+		value := math.NaN()
+		if v != nil {
+			value = *v
+		}
+		out = append(out, IndicatorComponent{Name: key, Value: value})
+	}
+	*c = out
+	return nil
 }
 
 // StreamKey returns the Redis stream key: "ind:{name}:{TF}s:{exchange}:{token}".
 func (r *IndicatorResult) StreamKey() string {
-	return "ind:" + r.Name + ":" + Itoa(r.TF) + "s:" + r.Exchange + ":" + r.Token
+	return "ind:" + r.Name + ":" + TFKey(r.TF) + ":" + r.Exchange + ":" + r.Token
 }
 
 // PubSubChannel returns the Redis PubSub channel for this indicator result.
 // Uses string concatenation instead of fmt.Sprintf for zero-alloc hot path.
 func (r *IndicatorResult) PubSubChannel() string {
-	return "pub:ind:" + r.Name + ":" + Itoa(r.TF) + "s:" + r.Exchange + ":" + r.Token
+	return "pub:ind:" + r.Name + ":" + TFKey(r.TF) + ":" + r.Exchange + ":" + r.Token
 }
 
 // JSON returns the JSON-encoded indicator result.
@@ -78,7 +195,7 @@ func (r *IndicatorResult) JSON() []byte {
 	buf = append(buf, `","tf":`...)
 	buf = strconv.AppendInt(buf, int64(r.TF), 10)
 	buf = append(buf, `,"value":`...)
-	buf = strconv.AppendFloat(buf, r.Value, 'f', 4, 64)
+	buf = appendFloat(buf, r.Value)
 	buf = append(buf, `,"ts":"`...)
 	buf = r.TS.AppendFormat(buf, time.RFC3339Nano)
 	buf = append(buf, `","ready":`...)
@@ -90,6 +207,10 @@ func (r *IndicatorResult) JSON() []byte {
 	if r.Live {
 		buf = append(buf, `,"live":true`...)
 	}
+	if len(r.Components) > 0 {
+		buf = append(buf, `,"components":`...)
+		buf = r.Components.appendJSON(buf)
+	}
 	buf = append(buf, '}')
 
 	return buf
@@ -0,0 +1,49 @@
+package model
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIndicatorResultJSON_NonFinite(t *testing.T) {
+	r := IndicatorResult{
+		Name: "BB_20", Token: "2885", Exchange: "NSE", TF: 60,
+		Value: math.NaN(),
+		TS:    time.Date(2026, 1, 5, 3, 45, 0, 0, time.UTC),
+		Components: IndicatorComponents{
+			{Name: "mid", Value: 101.5},
+			{Name: "upper", Value: math.Inf(1)},
+			{Name: "lower", Value: math.Inf(-1)},
+		},
+	}
+	data := r.JSON()
+	if !json.Valid(data) {
+		t.Fatalf("invalid JSON: %s", data)
+	}
+	for _, want := range []string{`"value":null`, `"mid":101.5000`, `"upper":null`, `"lower":null`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("%s missing from %s", want, data)
+		}
+	}
+
+	var got IndicatorResult
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Components) != 3 || got.Components[0].Value != 101.5 || !math.IsNaN(got.Components[1].Value) {
+		t.Errorf("unexpected components after round trip: %+v", got.Components)
+	}
+}
+
+func TestIndicatorComponents_MarshalJSON(t *testing.T) {
+	data, err := json.Marshal(IndicatorComponents{{Name: "macd", Value: 1.25}, {Name: "signal", Value: math.NaN()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"macd":1.2500,"signal":null}` {
+		t.Errorf("got %s", data)
+	}
+}
@@ -11,6 +11,7 @@ type Tick struct {
 	Qty      int64     `json:"qty"`                // last traded quantity
 	TickTS   time.Time `json:"tick_ts"`            // UTC arrival timestamp
 	EventTS  time.Time `json:"event_ts,omitempty"` // exchange-provided canonical time
+	OI       int64     `json:"oi,omitempty"`       // open interest (SnapQuote, F&O only); 0 = unknown
 }
 
 // CanonicalTS returns the best available timestamp for this tick.
@@ -0,0 +1,88 @@
+// Package oi derives open interest analytics from finalized F&O bars: the
+// OI change over the bar and the buildup class read off the direction of
+// price and OI together:
+//
+//	price ↑  OI ↑   long buildup      (fresh longs)
+//	price ↓  OI ↑   short buildup     (fresh shorts)
+//	price ↑  OI ↓   short covering    (shorts exiting)
+//	price ↓  OI ↓   long unwinding    (longs exiting)
+//
+// Both changes are measured from the previous bar's close, so the move
+// between two bars is not lost; the first bar of a series compares with its
+// own open.
+package oi
+
+import (
+	"math"
+
+	"trading-systemv1/internal/model"
+)
+
+// Classify returns the buildup class for a price and an OI change, or ""
+// when either is zero.
+func Classify(priceChange, oiChange int64) string {
+	switch {
+	case priceChange > 0 && oiChange > 0:
+		return model.BuildupLong
+	case priceChange < 0 && oiChange > 0:
+		return model.BuildupShort
+	case priceChange > 0 && oiChange < 0:
+		return model.BuildupShortCovering
+	case priceChange < 0 && oiChange < 0:
+		return model.BuildupLongUnwinding
+	}
+	return ""
+}
+
+// last is the close of the previous bar of a series.
+type last struct {
+	close, oi int64
+}
+
+// Tracker turns finalized TF candles into OIBars. It is not safe for
+// concurrent use.
+type Tracker struct {
+	prev map[int]map[string]last // tf → "exchange:token" → previous bar
+}
+
+// NewTracker creates an empty Tracker.
+func NewTracker() *Tracker {
+	return &Tracker{prev: make(map[int]map[string]last)}
+}
+
+// Update returns the OI analytics of c. It reports false for forming
+// candles, Heikin-Ashi series and candles without open interest.
+func (t *Tracker) Update(c model.TFCandle) (model.OIBar, bool) {
+	if c.Forming || c.OIClose == 0 || model.IsHATF(c.TF) {
+		return model.OIBar{}, false
+	}
+	byToken, ok := t.prev[c.TF]
+	if !ok {
+		byToken = make(map[string]last, 64)
+		t.prev[c.TF] = byToken
+	}
+	key := c.Key()
+
+	base := last{close: c.Open, oi: c.OIOpen}
+	if p, ok := byToken[key]; ok {
+		base = p
+	}
+	byToken[key] = last{close: c.Close, oi: c.OIClose}
+
+	bar := model.OIBar{
+		Token:       c.Token,
+		Exchange:    c.Exchange,
+		TF:          c.TF,
+		TS:          c.TS,
+		Close:       c.Close,
+		PriceChange: c.Close - base.close,
+		OIOpen:      c.OIOpen,
+		OIClose:     c.OIClose,
+		OIChange:    c.OIClose - base.oi,
+	}
+	if base.oi > 0 {
+		bar.OIChangePct = math.Round(float64(bar.OIChange)/float64(base.oi)*1e4) / 100
+	}
+	bar.Buildup = Classify(bar.PriceChange, bar.OIChange)
+	return bar, true
+}
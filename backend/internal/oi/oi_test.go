@@ -0,0 +1,96 @@
+package oi
+
+import (
+	"testing"
+	"time"
+
+	"trading-systemv1/internal/model"
+)
+
+var t0 = time.Date(2026, 1, 5, 3, 45, 0, 0, time.UTC)
+
+// bar builds a finalized 60s NFO candle; prices in paise.
+func bar(i int, open, close, oiOpen, oiClose int64) model.TFCandle {
+	return model.TFCandle{
+		Token: "43650", Exchange: "NFO", TF: 60, TS: t0.Add(time.Duration(i) * time.Minute),
+		Open: open, High: max(open, close), Low: min(open, close), Close: close,
+		OIOpen: oiOpen, OIClose: oiClose,
+	}
+}
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		price, oi int64
+		want      string
+	}{
+		{5, 100, model.BuildupLong},
+		{-5, 100, model.BuildupShort},
+		{5, -100, model.BuildupShortCovering},
+		{-5, -100, model.BuildupLongUnwinding},
+		{0, 100, ""},
+		{5, 0, ""},
+	}
+	for _, tt := range tests {
+		if got := Classify(tt.price, tt.oi); got != tt.want {
+			t.Errorf("Classify(%d, %d) = %q, want %q", tt.price, tt.oi, got, tt.want)
+		}
+	}
+}
+
+func TestTracker_Series(t *testing.T) {
+	tr := NewTracker()
+	tests := []struct {
+		c         model.TFCandle
+		price, oi int64
+		pct       float64
+		buildup   string
+	}{
+		// First bar compares with its own open.
+		{bar(0, 10000, 10050, 200000, 210000), 50, 10000, 5, model.BuildupLong},
+		// OI moved between bars (210000 → 212000 at the open): counted.
+		{bar(1, 10040, 9990, 212000, 215000), -60, 5000, 2.38, model.BuildupShort},
+		{bar(2, 9990, 10100, 215000, 209000), 110, -6000, -2.79, model.BuildupShortCovering},
+		{bar(3, 10100, 10020, 209000, 205000), -80, -4000, -1.91, model.BuildupLongUnwinding},
+		{bar(4, 10020, 10020, 205000, 206000), 0, 1000, 0.49, ""},
+	}
+	for i, tt := range tests {
+		got, ok := tr.Update(tt.c)
+		if !ok {
+			t.Fatalf("bar %d: not tracked", i)
+		}
+		if got.PriceChange != tt.price || got.OIChange != tt.oi || got.OIChangePct != tt.pct || got.Buildup != tt.buildup {
+			t.Errorf("bar %d: got price %d oi %d (%.2f%%) %q, want %d %d (%.2f%%) %q",
+				i, got.PriceChange, got.OIChange, got.OIChangePct, got.Buildup, tt.price, tt.oi, tt.pct, tt.buildup)
+		}
+		if got.OIOpen != tt.c.OIOpen || got.OIClose != tt.c.OIClose || !got.TS.Equal(tt.c.TS) || got.TF != 60 {
+			t.Errorf("bar %d: fields not carried over: %+v", i, got)
+		}
+	}
+}
+
+func TestTracker_Skips(t *testing.T) {
+	tr := NewTracker()
+	forming := bar(0, 100, 110, 1000, 1100)
+	forming.Forming = true
+	noOI := bar(0, 100, 110, 0, 0)
+	ha := bar(0, 100, 110, 1000, 1100)
+	ha.TF = model.HATF(60)
+	for _, c := range []model.TFCandle{forming, noOI, ha} {
+		if _, ok := tr.Update(c); ok {
+			t.Errorf("tracked %+v", c)
+		}
+	}
+
+	// Series are independent per TF and instrument.
+	tr.Update(bar(0, 100, 110, 1000, 1100))
+	other := bar(0, 100, 90, 5000, 5100)
+	other.Token = "43651"
+	if got, _ := tr.Update(other); got.PriceChange != -10 || got.OIChange != 100 {
+		t.Errorf("other instrument used the wrong base: %+v", got)
+	}
+	fiveMin := bar(0, 100, 120, 1000, 1200)
+	fiveMin.TF = 300
+	if got, _ := tr.Update(fiveMin); got.PriceChange != 20 || got.OIChange != 200 {
+		t.Errorf("other TF used the wrong base: %+v", got)
+	}
+}
@@ -0,0 +1,167 @@
+package pattern
+
+import "trading-systemv1/internal/model"
+
+// Candle geometry helpers. All values are in paise.
+
+func body(c model.TFCandle) int64 {
+	if c.Close > c.Open {
+		return c.Close - c.Open
+	}
+	return c.Open - c.Close
+}
+
+func span(c model.TFCandle) int64 { return c.High - c.Low }
+
+func bodyTop(c model.TFCandle) int64 { return max(c.Open, c.Close) }
+
+func bodyBottom(c model.TFCandle) int64 { return min(c.Open, c.Close) }
+
+func upperShadow(c model.TFCandle) int64 { return c.High - bodyTop(c) }
+
+func lowerShadow(c model.TFCandle) int64 { return bodyBottom(c) - c.Low }
+
+func bullish(c model.TFCandle) bool { return c.Close > c.Open }
+
+func bearish(c model.TFCandle) bool { return c.Close < c.Open }
+
+// strong reports whether the body is at least half the candle's range.
+func strong(c model.TFCandle) bool { return span(c) > 0 && 2*body(c) >= span(c) }
+
+// last returns the n-th most recent candle (0 = latest).
+func last(w []model.TFCandle, n int) model.TFCandle { return w[len(w)-1-n] }
+
+// fallingInto reports whether the candle before the latest closed below the
+// one before it — the minimal downtrend context for reversal patterns.
+func fallingInto(w []model.TFCandle) bool {
+	return len(w) >= 3 && last(w, 1).Close < last(w, 2).Close
+}
+
+// risingInto is the uptrend counterpart of fallingInto.
+func risingInto(w []model.TFCandle) bool {
+	return len(w) >= 3 && last(w, 1).Close > last(w, 2).Close
+}
+
+// doji: open and close almost equal — body at most 10% of the range.
+func doji(w []model.TFCandle) (string, bool) {
+	c := last(w, 0)
+	return model.BiasNeutral, span(c) > 0 && 10*body(c) <= span(c)
+}
+
+// hammer: small body at the top, lower shadow at least twice the body and
+// 60% of the range, little upper shadow, after a decline.
+func hammer(w []model.TFCandle) (string, bool) {
+	c := last(w, 0)
+	r := span(c)
+	ok := r > 0 && fallingInto(w) &&
+		3*body(c) <= r &&
+		lowerShadow(c) >= 2*body(c) && 5*lowerShadow(c) >= 3*r &&
+		5*upperShadow(c) <= r
+	return model.BiasBullish, ok
+}
+
+// shootingStar: the inverted hammer after an advance.
+func shootingStar(w []model.TFCandle) (string, bool) {
+	c := last(w, 0)
+	r := span(c)
+	ok := r > 0 && risingInto(w) &&
+		3*body(c) <= r &&
+		upperShadow(c) >= 2*body(c) && 5*upperShadow(c) >= 3*r &&
+		5*lowerShadow(c) <= r
+	return model.BiasBearish, ok
+}
+
+// bullishEngulfing: a bullish body that engulfs the previous bearish body.
+func bullishEngulfing(w []model.TFCandle) (string, bool) {
+	prev, c := last(w, 1), last(w, 0)
+	ok := bearish(prev) && bullish(c) &&
+		c.Open <= prev.Close && c.Close >= prev.Open && body(c) > body(prev)
+	return model.BiasBullish, ok
+}
+
+// bearishEngulfing: a bearish body that engulfs the previous bullish body.
+func bearishEngulfing(w []model.TFCandle) (string, bool) {
+	prev, c := last(w, 1), last(w, 0)
+	ok := bullish(prev) && bearish(c) &&
+		c.Open >= prev.Close && c.Close <= prev.Open && body(c) > body(prev)
+	return model.BiasBearish, ok
+}
+
+// insideBar: the range lies within the previous candle's range.
+func insideBar(w []model.TFCandle) (string, bool) {
+	prev, c := last(w, 1), last(w, 0)
+	ok := c.High <= prev.High && c.Low >= prev.Low && span(c) < span(prev)
+	return model.BiasNeutral, ok
+}
+
+// outsideBar: the range exceeds the previous candle's on both sides; the
+// bias follows the outside candle's direction.
+func outsideBar(w []model.TFCandle) (string, bool) {
+	prev, c := last(w, 1), last(w, 0)
+	if c.High <= prev.High || c.Low >= prev.Low {
+		return "", false
+	}
+	switch {
+	case bullish(c):
+		return model.BiasBullish, true
+	case bearish(c):
+		return model.BiasBearish, true
+	}
+	return model.BiasNeutral, true
+}
+
+// morningStar: a strong bearish candle, a small-bodied candle, then a
+// bullish candle closing above the middle of the first body.
+func morningStar(w []model.TFCandle) (string, bool) {
+	first, star, c := last(w, 2), last(w, 1), last(w, 0)
+	ok := bearish(first) && strong(first) &&
+		3*body(star) <= body(first) &&
+		bullish(c) && 2*c.Close > first.Open+first.Close
+	return model.BiasBullish, ok
+}
+
+// eveningStar: a strong bullish candle, a small-bodied candle, then a
+// bearish candle closing below the middle of the first body.
+func eveningStar(w []model.TFCandle) (string, bool) {
+	first, star, c := last(w, 2), last(w, 1), last(w, 0)
+	ok := bullish(first) && strong(first) &&
+		3*body(star) <= body(first) &&
+		bearish(c) && 2*c.Close < first.Open+first.Close
+	return model.BiasBearish, ok
+}
+
+// threeWhiteSoldiers: three strong bullish candles, each opening within the
+// previous body and closing higher.
+func threeWhiteSoldiers(w []model.TFCandle) (string, bool) {
+	for i := 0; i < 3; i++ {
+		c := last(w, i)
+		if !bullish(c) || !strong(c) {
+			return "", false
+		}
+		if i < 2 {
+			prev := last(w, i+1)
+			if c.Close <= prev.Close || c.Open < prev.Open || c.Open > prev.Close {
+				return "", false
+			}
+		}
+	}
+	return model.BiasBullish, true
+}
+
+// threeBlackCrows: three strong bearish candles, each opening within the
+// previous body and closing lower.
+func threeBlackCrows(w []model.TFCandle) (string, bool) {
+	for i := 0; i < 3; i++ {
+		c := last(w, i)
+		if !bearish(c) || !strong(c) {
+			return "", false
+		}
+		if i < 2 {
+			prev := last(w, i+1)
+			if c.Close >= prev.Close || c.Open > prev.Open || c.Open < prev.Close {
+				return "", false
+			}
+		}
+	}
+	return model.BiasBearish, true
+}
@@ -0,0 +1,115 @@
+// Package pattern recognizes candlestick patterns on finalized TF candles.
+//
+// A Detector keeps the last few candles per token and TF and runs every
+// registered Pattern after each completed candle. Patterns work on integer
+// paise prices, so results are exact and reproducible across replays.
+package pattern
+
+import "trading-systemv1/internal/model"
+
+// depth is how many recent candles the detector keeps per token and TF:
+// three for the longest pattern plus one for trend context.
+const depth = 4
+
+// Pattern is a single candlestick pattern recognizer.
+type Pattern struct {
+	// Name is the published pattern name, e.g. "BULLISH_ENGULFING".
+	Name string
+
+	// Bars is the number of candles the pattern spans (ending with the
+	// latest one).
+	Bars int
+
+	// Match reports whether the pattern completes on the latest candle and
+	// its bias. w holds the recent candles, oldest first; it has at least
+	// Bars candles but may be shorter than depth.
+	Match func(w []model.TFCandle) (bias string, ok bool)
+}
+
+// Patterns is the default set of recognizers, in detection order.
+var Patterns = []Pattern{
+	{Name: "DOJI", Bars: 1, Match: doji},
+	{Name: "HAMMER", Bars: 1, Match: hammer},
+	{Name: "SHOOTING_STAR", Bars: 1, Match: shootingStar},
+	{Name: "BULLISH_ENGULFING", Bars: 2, Match: bullishEngulfing},
+	{Name: "BEARISH_ENGULFING", Bars: 2, Match: bearishEngulfing},
+	{Name: "INSIDE_BAR", Bars: 2, Match: insideBar},
+	{Name: "OUTSIDE_BAR", Bars: 2, Match: outsideBar},
+	{Name: "MORNING_STAR", Bars: 3, Match: morningStar},
+	{Name: "EVENING_STAR", Bars: 3, Match: eveningStar},
+	{Name: "THREE_WHITE_SOLDIERS", Bars: 3, Match: threeWhiteSoldiers},
+	{Name: "THREE_BLACK_CROWS", Bars: 3, Match: threeBlackCrows},
+}
+
+// window holds the most recent candles of one token and TF, oldest first.
+type window struct {
+	bars []model.TFCandle
+}
+
+func (w *window) push(c model.TFCandle) {
+	if len(w.bars) == depth {
+		copy(w.bars, w.bars[1:])
+		w.bars = w.bars[:depth-1]
+	}
+	w.bars = append(w.bars, c)
+}
+
+// Detector runs patterns over finalized TF candles for many tokens and TFs.
+// Designed for single-goroutine usage — no locks needed.
+type Detector struct {
+	patterns []Pattern
+
+	// windows["TF:exchange:token"] → recent candles
+	windows map[string]*window
+}
+
+// NewDetector creates a detector for the given patterns, or for the
+// default Patterns if none are given.
+func NewDetector(patterns ...Pattern) *Detector {
+	if len(patterns) == 0 {
+		patterns = Patterns
+	}
+	return &Detector{
+		patterns: patterns,
+		windows:  make(map[string]*window, 64),
+	}
+}
+
+// Process takes a finalized TF candle and returns the patterns it completes.
+// Forming candles are ignored, and so are gap-fill bars, whose flat shape
+// reflects no trading.
+func (d *Detector) Process(tfc model.TFCandle) []model.PatternEvent {
+	if tfc.Forming || tfc.Synthetic {
+		return nil
+	}
+
+	key := model.Itoa(tfc.TF) + ":" + tfc.Key()
+	w, ok := d.windows[key]
+	if !ok {
+		w = &window{bars: make([]model.TFCandle, 0, depth)}
+		d.windows[key] = w
+	}
+	w.push(tfc)
+
+	var events []model.PatternEvent
+	for _, p := range d.patterns {
+		if len(w.bars) < p.Bars {
+			continue
+		}
+		bias, ok := p.Match(w.bars)
+		if !ok {
+			continue
+		}
+		events = append(events, model.PatternEvent{
+			Name:     p.Name,
+			Token:    tfc.Token,
+			Exchange: tfc.Exchange,
+			TF:       tfc.TF,
+			TS:       tfc.TS,
+			StartTS:  w.bars[len(w.bars)-p.Bars].TS,
+			Bars:     p.Bars,
+			Bias:     bias,
+		})
+	}
+	return events
+}
@@ -0,0 +1,158 @@
+package pattern
+
+import (
+	"testing"
+	"time"
+
+	"trading-systemv1/internal/model"
+)
+
+var base = time.Date(2026, 1, 5, 3, 45, 0, 0, time.UTC)
+
+// bar builds a 60s candle; prices are in rupees for readability.
+func bar(i int, o, h, l, c float64) model.TFCandle {
+	return model.TFCandle{
+		Token:    "99926000",
+		Exchange: "NSE",
+		TF:       60,
+		TS:       base.Add(time.Duration(i) * time.Minute),
+		Open:     int64(o * 100),
+		High:     int64(h * 100),
+		Low:      int64(l * 100),
+		Close:    int64(c * 100),
+	}
+}
+
+// detect feeds bars and returns the pattern names completed on the last one.
+func detect(bars ...model.TFCandle) map[string]model.PatternEvent {
+	d := NewDetector()
+	var events []model.PatternEvent
+	for _, b := range bars {
+		events = d.Process(b)
+	}
+	out := make(map[string]model.PatternEvent, len(events))
+	for _, e := range events {
+		out[e.Name] = e
+	}
+	return out
+}
+
+func TestPatterns(t *testing.T) {
+	tests := []struct {
+		name string
+		bars []model.TFCandle
+		want string
+		bias string
+	}{
+		{"doji", []model.TFCandle{bar(0, 100, 102, 98, 100.1)}, "DOJI", model.BiasNeutral},
+		{"hammer", []model.TFCandle{
+			bar(0, 110, 111, 105, 106),
+			bar(1, 106, 107, 101, 102),
+			bar(2, 101, 102, 95, 101.8),
+		}, "HAMMER", model.BiasBullish},
+		{"shooting star", []model.TFCandle{
+			bar(0, 100, 104, 99, 103),
+			bar(1, 103, 108, 102, 107),
+			bar(2, 107.5, 114, 107, 107.2),
+		}, "SHOOTING_STAR", model.BiasBearish},
+		{"bullish engulfing", []model.TFCandle{
+			bar(0, 105, 106, 101, 102),
+			bar(1, 101.5, 107, 101, 106.5),
+		}, "BULLISH_ENGULFING", model.BiasBullish},
+		{"bearish engulfing", []model.TFCandle{
+			bar(0, 102, 106, 101, 105),
+			bar(1, 105.5, 106, 100, 101),
+		}, "BEARISH_ENGULFING", model.BiasBearish},
+		{"inside bar", []model.TFCandle{
+			bar(0, 100, 110, 90, 105),
+			bar(1, 104, 108, 95, 101),
+		}, "INSIDE_BAR", model.BiasNeutral},
+		{"outside bar", []model.TFCandle{
+			bar(0, 100, 104, 98, 102),
+			bar(1, 101, 106, 96, 105),
+		}, "OUTSIDE_BAR", model.BiasBullish},
+		{"morning star", []model.TFCandle{
+			bar(0, 110, 111, 99, 100),
+			bar(1, 99.5, 100, 98, 99),
+			bar(2, 99.5, 108, 99, 107),
+		}, "MORNING_STAR", model.BiasBullish},
+		{"evening star", []model.TFCandle{
+			bar(0, 100, 111, 99, 110),
+			bar(1, 110.5, 112, 110, 111),
+			bar(2, 110.5, 111, 102, 103),
+		}, "EVENING_STAR", model.BiasBearish},
+		{"three white soldiers", []model.TFCandle{
+			bar(0, 100, 104.5, 99.5, 104),
+			bar(1, 102, 108.5, 101.5, 108),
+			bar(2, 106, 112.5, 105.5, 112),
+		}, "THREE_WHITE_SOLDIERS", model.BiasBullish},
+		{"three black crows", []model.TFCandle{
+			bar(0, 112, 112.5, 107.5, 108),
+			bar(1, 110, 110.5, 103.5, 104),
+			bar(2, 106, 106.5, 99.5, 100),
+		}, "THREE_BLACK_CROWS", model.BiasBearish},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := detect(tt.bars...)
+			e, ok := got[tt.want]
+			if !ok {
+				t.Fatalf("expected %s, got %v", tt.want, got)
+			}
+			if e.Bias != tt.bias {
+				t.Errorf("bias: got %s, want %s", e.Bias, tt.bias)
+			}
+			first := tt.bars[len(tt.bars)-e.Bars]
+			if !e.StartTS.Equal(first.TS) || !e.TS.Equal(tt.bars[len(tt.bars)-1].TS) {
+				t.Errorf("span %s–%s, want %s–%s", e.StartTS, e.TS, first.TS, tt.bars[len(tt.bars)-1].TS)
+			}
+		})
+	}
+}
+
+func TestPatterns_NoFalsePositives(t *testing.T) {
+	// Hammer shape without a preceding decline is not a hammer.
+	got := detect(
+		bar(0, 100, 101, 99, 100.5),
+		bar(1, 100.5, 102, 100, 101.5),
+		bar(2, 101, 102, 95, 101.8),
+	)
+	if _, ok := got["HAMMER"]; ok {
+		t.Error("HAMMER reported without a decline")
+	}
+
+	// A plain trending candle completes nothing.
+	if got := detect(bar(0, 100, 105, 99.5, 104.5)); len(got) != 0 {
+		t.Errorf("expected no patterns, got %v", got)
+	}
+}
+
+func TestDetector_SeparatesTokensAndTFs(t *testing.T) {
+	d := NewDetector()
+	d.Process(bar(0, 105, 106, 101, 102))
+
+	other := bar(1, 101.5, 107, 101, 106.5)
+	other.Token = "2885"
+	for _, e := range d.Process(other) {
+		if e.Name == "BULLISH_ENGULFING" {
+			t.Error("engulfing matched across tokens")
+		}
+	}
+
+	forming := bar(1, 101.5, 107, 101, 106.5)
+	forming.Forming = true
+	if events := d.Process(forming); events != nil {
+		t.Errorf("forming candles must be ignored, got %v", events)
+	}
+}
+
+func TestPatternEvent_Keys(t *testing.T) {
+	e := model.PatternEvent{Name: "DOJI", Exchange: "NSE", Token: "99926000", TF: 300}
+	if got := e.StreamKey(); got != "pattern:300s:NSE:99926000" {
+		t.Errorf("stream key: %s", got)
+	}
+	if got := e.PubSubChannel(); got != "pub:pattern:DOJI:300s:NSE:99926000" {
+		t.Errorf("channel: %s", got)
+	}
+}
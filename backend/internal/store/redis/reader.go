@@ -9,6 +9,7 @@ import (
 	"unsafe"
 
 	"trading-systemv1/internal/indicator"
+	"trading-systemv1/internal/markethours"
 	"trading-systemv1/internal/model"
 
 	goredis "github.com/go-redis/redis/v8"
@@ -380,12 +381,35 @@ func (r *Reader) ReplayFromID(ctx context.Context, stream, startID string, out c
 	return lastID, nil
 }
 
+// ReadLatestTFCandles reads the n most recent finalized candles of a stream
+// (XREVRANGE COUNT n), oldest first. Used to warm up indicators added at
+// runtime without replaying the whole stream.
+func (r *Reader) ReadLatestTFCandles(ctx context.Context, stream string, n int) ([]model.TFCandle, error) {
+	results, err := r.client.XRevRangeN(ctx, stream, "+", "-", int64(n)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("xrevrange %s: %w", stream, err)
+	}
+	candles := make([]model.TFCandle, 0, len(results))
+	for i := len(results) - 1; i >= 0; i-- {
+		data, ok := results[i].Values["data"].(string)
+		if !ok {
+			continue
+		}
+		var tfc model.TFCandle
+		if err := json.Unmarshal([]byte(data), &tfc); err != nil || tfc.Forming {
+			continue
+		}
+		candles = append(candles, tfc)
+	}
+	return candles, nil
+}
+
 // DiscoverTFStreams finds all TF candle streams matching the pattern for known tokens.
 func (r *Reader) DiscoverTFStreams(ctx context.Context, tfs []int, tokens []string) []string {
 	var streams []string
 	for _, tf := range tfs {
 		for _, tok := range tokens {
-			stream := fmt.Sprintf("candle:%ds:%s", tf, tok)
+			stream := "candle:" + model.TFKey(tf) + ":" + tok
 			// Verify stream exists
 			exists, err := r.client.Exists(ctx, stream).Result()
 			if err == nil && exists > 0 {
@@ -432,9 +456,22 @@ func (r *Reader) SubscribeFormingCandles(ctx context.Context, out chan<- model.T
 // This enables live indicator ProcessPeek without depending on the mdengine
 // publishing forming TF candles.
 //
+// Bar series (model.IsBarTF) are skipped: their boundaries depend on
+// activity, so only mdengine's bar builder can form them. Heikin-Ashi
+// series (model.IsHATF) are skipped too: they chain on the previous HA
+// candle, which only mdengine tracks.
+//
 // OPTIMIZED: uses manual JSON field extraction instead of json.Unmarshal
 // and string concat instead of fmt.Sprintf for state keys.
 func (r *Reader) Subscribe1sForPeek(ctx context.Context, tfs []int, out chan<- model.TFCandle) error {
+	timeTFs := make([]int, 0, len(tfs))
+	for _, tf := range tfs {
+		if !model.IsBarTF(tf) && !model.IsHATF(tf) {
+			timeTFs = append(timeTFs, tf)
+		}
+	}
+	tfs = timeTFs
+
 	pubsub := r.client.PSubscribe(ctx, "pub:candle:1s:*")
 	defer pubsub.Close()
 
@@ -479,8 +516,7 @@ func (r *Reader) Subscribe1sForPeek(ctx context.Context, tfs []int, out chan<- m
 
 			ts := c.TS.Unix()
 			for i, tf := range tfs {
-				tf64 := int64(tf)
-				bucket := ts - (ts % tf64)
+				bucket := markethours.BucketStart(ts, tf) // same buckets as tfbuilder
 				// String concat instead of fmt.Sprintf
 				key := tfStrs[i] + ":" + c.Exchange + ":" + c.Token
 
@@ -495,7 +531,7 @@ func (r *Reader) Subscribe1sForPeek(ctx context.Context, tfs []int, out chan<- m
 						bucket: bucket,
 						candle: model.TFCandle{
 							Token: c.Token, Exchange: c.Exchange,
-							TF: tf, TS: c.TS,
+							TF: tf, TS: time.Unix(bucket, 0).UTC(),
 							Open: c.Open, High: c.High,
 							Low: c.Low, Close: c.Close,
 							Volume: c.Volume, Count: 1,
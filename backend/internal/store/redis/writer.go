@@ -16,6 +16,12 @@ const (
 	// Stream trimming: ~3h of 1s candles + buffer
 	stream1sMaxLen   = 12000
 	defaultLatestTTL = 30 * time.Minute
+	// Session levels must outlive long weekends and holiday runs
+	levelsTTL = 7 * 24 * time.Hour
+	// Latest quote/depth stay readable after the close until the next session
+	marketDataTTL = 24 * time.Hour
+	// Quotes and depth are coalesced to the latest per instrument between flushes
+	marketDataFlushEvery = 250 * time.Millisecond
 )
 
 // WriterConfig configures the Redis writer.
@@ -97,7 +103,7 @@ func (w *Writer) RunFormingTFCandles(ctx context.Context, ch <-chan model.TFCand
 			}
 			jsonBytes := tfc.JSON()
 			jsonData := *(*string)(unsafe.Pointer(&jsonBytes))
-			pubsubCh := "pub:candle:" + itoa(tfc.TF) + "s:" + tfc.Exchange + ":" + tfc.Token
+			pubsubCh := "pub:candle:" + model.TFKey(tfc.TF) + ":" + tfc.Exchange + ":" + tfc.Token
 			w.client.Publish(ctx, pubsubCh, jsonData)
 		}
 	}
@@ -146,7 +152,7 @@ func (w *Writer) WriteIndicatorBatch(ctx context.Context, results []model.Indica
 
 		// Confirmed: XADD + SET + PUBLISH
 		streamKey := ind.StreamKey()
-		maxLen := int64(10800/ind.TF) + 100
+		maxLen := int64(10800/model.BaseTF(ind.TF)) + 100
 		if maxLen < 200 {
 			maxLen = 200
 		}
@@ -156,7 +162,7 @@ func (w *Writer) WriteIndicatorBatch(ctx context.Context, results []model.Indica
 			Approx: true,
 			Values: map[string]interface{}{"data": jsonData},
 		})
-		latestKey := "ind:" + ind.Name + ":" + itoa(ind.TF) + "s:latest:" + ind.Exchange + ":" + ind.Token
+		latestKey := "ind:" + ind.Name + ":" + model.TFKey(ind.TF) + ":latest:" + ind.Exchange + ":" + ind.Token
 		pipe.Set(ctx, latestKey, jsonData, defaultLatestTTL)
 		pipe.Publish(ctx, pubsubCh, jsonData)
 	}
@@ -167,6 +173,243 @@ func (w *Writer) WriteIndicatorBatch(ctx context.Context, results []model.Indica
 	}
 }
 
+// ReplaceIndicatorHistory rewrites the ind:* streams of backfilled indicators
+// with the given results (oldest first), so history reads return the full
+// series at once. Each affected stream is cleared first, in the same
+// MULTI/EXEC transaction, so readers never see it empty or half written; the
+// newest result per stream also becomes its latest value. Nothing is
+// published on pub:ind:* — live subscribers only care about new values.
+func (w *Writer) ReplaceIndicatorHistory(ctx context.Context, results []model.IndicatorResult) {
+	if len(results) == 0 {
+		return
+	}
+
+	pipe := w.client.TxPipeline()
+	cleared := make(map[string]bool)
+	latest := make(map[string]string)
+	for i := range results {
+		ind := &results[i]
+		if !ind.Ready {
+			continue
+		}
+		streamKey := ind.StreamKey()
+		if !cleared[streamKey] {
+			pipe.Del(ctx, streamKey)
+			cleared[streamKey] = true
+		}
+		jsonData := string(ind.JSON())
+		maxLen := int64(10800/model.BaseTF(ind.TF)) + 100
+		if maxLen < 200 {
+			maxLen = 200
+		}
+		pipe.XAdd(ctx, &goredis.XAddArgs{
+			Stream: streamKey,
+			MaxLen: maxLen,
+			Approx: true,
+			Values: map[string]interface{}{"data": jsonData},
+		})
+		latest["ind:"+ind.Name+":"+model.TFKey(ind.TF)+":latest:"+ind.Exchange+":"+ind.Token] = jsonData
+	}
+	for key, jsonData := range latest {
+		pipe.Set(ctx, key, jsonData, defaultLatestTTL)
+	}
+
+	_, err := pipe.Exec(ctx)
+	if err != nil {
+		log.Printf("[redis] indicator history pipeline error (%d results): %v", len(results), err)
+	}
+}
+
+// WritePatternBatch persists candlestick pattern events to their token/TF
+// stream and publishes each on its pub:pattern:* channel, in one pipeline.
+func (w *Writer) WritePatternBatch(ctx context.Context, events []model.PatternEvent) {
+	if len(events) == 0 {
+		return
+	}
+
+	pipe := w.client.Pipeline()
+	for i := range events {
+		ev := &events[i]
+		jsonData := string(ev.JSON())
+		maxLen := int64(10800/model.BaseTF(ev.TF)) + 100
+		if maxLen < 200 {
+			maxLen = 200
+		}
+		pipe.XAdd(ctx, &goredis.XAddArgs{
+			Stream: ev.StreamKey(),
+			MaxLen: maxLen,
+			Approx: true,
+			Values: map[string]interface{}{"data": jsonData},
+		})
+		pipe.Publish(ctx, ev.PubSubChannel(), jsonData)
+	}
+
+	_, err := pipe.Exec(ctx)
+	if err != nil {
+		log.Printf("[redis] pattern batch pipeline error (%d events): %v", len(events), err)
+	}
+}
+
+// WriteDivergenceBatch persists divergence events to their token/TF stream
+// and publishes each on its pub:div:* channel, in one pipeline.
+func (w *Writer) WriteDivergenceBatch(ctx context.Context, events []model.DivergenceEvent) {
+	if len(events) == 0 {
+		return
+	}
+
+	pipe := w.client.Pipeline()
+	for i := range events {
+		ev := &events[i]
+		jsonData := string(ev.JSON())
+		pipe.XAdd(ctx, &goredis.XAddArgs{
+			Stream: ev.StreamKey(),
+			MaxLen: 1000,
+			Approx: true,
+			Values: map[string]interface{}{"data": jsonData},
+		})
+		pipe.Publish(ctx, ev.PubSubChannel(), jsonData)
+	}
+
+	_, err := pipe.Exec(ctx)
+	if err != nil {
+		log.Printf("[redis] divergence batch pipeline error (%d events): %v", len(events), err)
+	}
+}
+
+// RunOIBars reads OI analytics bars and writes them to Redis Streams.
+// Blocks until ctx is cancelled or channel is closed.
+func (w *Writer) RunOIBars(ctx context.Context, ch <-chan model.OIBar) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case bar, ok := <-ch:
+			if !ok {
+				return
+			}
+			w.writeOIBar(ctx, bar)
+		}
+	}
+}
+
+// writeOIBar appends an OI bar to its token/TF stream and publishes it on
+// its pub:oi:* channel.
+func (w *Writer) writeOIBar(ctx context.Context, bar model.OIBar) {
+	// Same retention as the TF candle stream it annotates
+	maxLen := int64(10800/model.BaseTF(bar.TF)) + 100
+	if maxLen < 200 {
+		maxLen = 200
+	}
+	jsonData := string(bar.JSON())
+
+	pipe := w.client.Pipeline()
+	pipe.XAdd(ctx, &goredis.XAddArgs{
+		Stream: bar.StreamKey(),
+		MaxLen: maxLen,
+		Approx: true,
+		Values: map[string]interface{}{"data": jsonData},
+	})
+	pipe.Publish(ctx, bar.PubSubChannel(), jsonData)
+
+	_, err := pipe.Exec(ctx)
+	if err != nil {
+		log.Printf("[redis] OI bar pipeline error for %s:%s: %v", bar.Exchange, bar.Token, err)
+	}
+}
+
+// WriteLevels stores each instrument's session levels under levels:* and
+// publishes them on their pub:levels:* channel, in one pipeline.
+func (w *Writer) WriteLevels(ctx context.Context, levels []model.SessionLevels) {
+	if len(levels) == 0 {
+		return
+	}
+
+	pipe := w.client.Pipeline()
+	for i := range levels {
+		lv := &levels[i]
+		jsonData := string(lv.JSON())
+		pipe.Set(ctx, lv.RedisKey(), jsonData, levelsTTL)
+		pipe.Publish(ctx, lv.PubSubChannel(), jsonData)
+	}
+
+	_, err := pipe.Exec(ctx)
+	if err != nil {
+		log.Printf("[redis] levels pipeline error (%d instruments): %v", len(levels), err)
+	}
+}
+
+// RunMarketData stores and publishes quotes and order books. Packets are
+// coalesced per instrument and flushed every marketDataFlushEvery, so a busy
+// feed costs at most one write per instrument per flush. Blocks until ctx is
+// cancelled or both channels are closed.
+func (w *Writer) RunMarketData(ctx context.Context, quoteCh <-chan model.Quote, depthCh <-chan model.Depth) {
+	ticker := time.NewTicker(marketDataFlushEvery)
+	defer ticker.Stop()
+
+	quotes := make(map[string]model.Quote)
+	depths := make(map[string]model.Depth)
+	flush := func() {
+		if len(quotes) == 0 && len(depths) == 0 {
+			return
+		}
+		qs := make([]model.Quote, 0, len(quotes))
+		for _, q := range quotes {
+			qs = append(qs, q)
+		}
+		ds := make([]model.Depth, 0, len(depths))
+		for _, d := range depths {
+			ds = append(ds, d)
+		}
+		w.WriteMarketData(ctx, qs, ds)
+		clear(quotes)
+		clear(depths)
+	}
+
+	for quoteCh != nil || depthCh != nil {
+		select {
+		case <-ctx.Done():
+			return
+		case q, ok := <-quoteCh:
+			if !ok {
+				quoteCh = nil
+				continue
+			}
+			quotes[q.Key()] = q
+		case d, ok := <-depthCh:
+			if !ok {
+				depthCh = nil
+				continue
+			}
+			depths[d.Key()] = d
+		case <-ticker.C:
+			flush()
+		}
+	}
+	flush()
+}
+
+// WriteMarketData stores each quote and book under quote:* / depth:* and
+// publishes it on its pub:quote:* / pub:depth:* channel, in one pipeline.
+func (w *Writer) WriteMarketData(ctx context.Context, quotes []model.Quote, depths []model.Depth) {
+	pipe := w.client.Pipeline()
+	for i := range quotes {
+		q := &quotes[i]
+		jsonData := string(q.JSON())
+		pipe.Set(ctx, q.RedisKey(), jsonData, marketDataTTL)
+		pipe.Publish(ctx, q.PubSubChannel(), jsonData)
+	}
+	for i := range depths {
+		d := &depths[i]
+		jsonData := string(d.JSON())
+		pipe.Set(ctx, d.RedisKey(), jsonData, marketDataTTL)
+		pipe.Publish(ctx, d.PubSubChannel(), jsonData)
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Printf("[redis] market data pipeline error (%d quotes, %d books): %v", len(quotes), len(depths), err)
+	}
+}
+
 // PublishFormingBatch publishes multiple forming TF candles in a single pipeline.
 func (w *Writer) PublishFormingBatch(ctx context.Context, candles []model.TFCandle) {
 	if len(candles) == 0 {
@@ -176,7 +419,7 @@ func (w *Writer) PublishFormingBatch(ctx context.Context, candles []model.TFCand
 	pipe := w.client.Pipeline()
 	for _, tfc := range candles {
 		jsonData := string(tfc.JSON())
-		pubsubCh := fmt.Sprintf("pub:candle:%ds:%s:%s", tfc.TF, tfc.Exchange, tfc.Token)
+		pubsubCh := "pub:candle:" + model.TFKey(tfc.TF) + ":" + tfc.Exchange + ":" + tfc.Token
 		pipe.Publish(ctx, pubsubCh, jsonData)
 	}
 
@@ -221,8 +464,10 @@ func (w *Writer) writeCandle(ctx context.Context, candle model.Candle) {
 
 	pipe := w.client.Pipeline()
 
-	// SET latest candle with TTL
-	pipe.Set(ctx, latestKey, jsonData, defaultLatestTTL)
+	// SET latest candle with TTL (a revision is of an older second: skip)
+	if candle.Revision == 0 {
+		pipe.Set(ctx, latestKey, jsonData, defaultLatestTTL)
+	}
 
 	// XADD to stream with auto-trimming (~3h window)
 	pipe.XAdd(ctx, &goredis.XAddArgs{
@@ -234,7 +479,10 @@ func (w *Writer) writeCandle(ctx context.Context, candle model.Candle) {
 		},
 	})
 
-	// PUBLISH to pubsub channel
+	// PUBLISH to pubsub channel; revisions go out as amend events
+	if candle.Revision > 0 {
+		pubsubCh = candle.AmendChannel()
+	}
 	pipe.Publish(ctx, pubsubCh, jsonData)
 
 	_, err := pipe.Exec(ctx)
@@ -247,7 +495,7 @@ func (w *Writer) writeCandle(ctx context.Context, candle model.Candle) {
 func (w *Writer) writeTFCandle(ctx context.Context, tfc model.TFCandle) {
 	streamKey := tfc.StreamKey()
 	// Proportional MAXLEN: 3h of TF candles = 10800/TF + buffer
-	maxLen := int64(10800/tfc.TF) + 100
+	maxLen := int64(10800/model.BaseTF(tfc.TF)) + 100
 	if maxLen < 200 {
 		maxLen = 200
 	}
@@ -266,12 +514,17 @@ func (w *Writer) writeTFCandle(ctx context.Context, tfc model.TFCandle) {
 		},
 	})
 
-	// SET latest TF candle
-	latestKey := fmt.Sprintf("candle:%ds:latest:%s:%s", tfc.TF, tfc.Exchange, tfc.Token)
-	pipe.Set(ctx, latestKey, jsonData, defaultLatestTTL)
+	// SET latest TF candle (a revision is of an older bar: skip)
+	if tfc.Revision == 0 {
+		latestKey := "candle:" + model.TFKey(tfc.TF) + ":latest:" + tfc.Exchange + ":" + tfc.Token
+		pipe.Set(ctx, latestKey, jsonData, defaultLatestTTL)
+	}
 
-	// PUBLISH for real-time subscribers
-	pubsubCh := fmt.Sprintf("pub:candle:%ds:%s:%s", tfc.TF, tfc.Exchange, tfc.Token)
+	// PUBLISH for real-time subscribers; revisions go out as amend events
+	pubsubCh := "pub:candle:" + model.TFKey(tfc.TF) + ":" + tfc.Exchange + ":" + tfc.Token
+	if tfc.Revision > 0 {
+		pubsubCh = tfc.AmendChannel()
+	}
 	pipe.Publish(ctx, pubsubCh, jsonData)
 
 	_, err := pipe.Exec(ctx)
@@ -301,7 +554,7 @@ func (w *Writer) writeIndicator(ctx context.Context, ind model.IndicatorResult)
 	pipe := w.client.Pipeline()
 
 	// XADD to indicator stream (keep ~3h worth)
-	maxLen := int64(10800/ind.TF) + 100
+	maxLen := int64(10800/model.BaseTF(ind.TF)) + 100
 	if maxLen < 200 {
 		maxLen = 200
 	}
@@ -315,7 +568,7 @@ func (w *Writer) writeIndicator(ctx context.Context, ind model.IndicatorResult)
 	})
 
 	// SET latest indicator value
-	latestKey := "ind:" + ind.Name + ":" + itoa(ind.TF) + "s:latest:" + ind.Exchange + ":" + ind.Token
+	latestKey := "ind:" + ind.Name + ":" + model.TFKey(ind.TF) + ":latest:" + ind.Exchange + ":" + ind.Token
 	pipe.Set(ctx, latestKey, jsonData, defaultLatestTTL)
 
 	// PUBLISH for real-time subscribers (dashboard)
@@ -35,7 +35,8 @@ func NewReader(dbPath string) (*Reader, error) {
 // Results are ordered by timestamp ascending for correct replay order.
 func (r *Reader) ReadTFCandles(exchange, token string, tf int, afterTS int64) ([]model.TFCandle, error) {
 	rows, err := r.db.Query(`
-		SELECT token, exchange, tf, ts, open, high, low, close, volume, count
+		SELECT token, exchange, tf, ts, open, high, low, close, volume, count,
+			COALESCE(oi_open, 0), COALESCE(oi_close, 0)
 		FROM candles_tf
 		WHERE exchange = ? AND token = ? AND tf = ? AND ts > ?
 		ORDER BY ts ASC
@@ -49,7 +50,7 @@ func (r *Reader) ReadTFCandles(exchange, token string, tf int, afterTS int64) ([
 	for rows.Next() {
 		var c model.TFCandle
 		var tsUnix int64
-		if err := rows.Scan(&c.Token, &c.Exchange, &c.TF, &tsUnix, &c.Open, &c.High, &c.Low, &c.Close, &c.Volume, &c.Count); err != nil {
+		if err := rows.Scan(&c.Token, &c.Exchange, &c.TF, &tsUnix, &c.Open, &c.High, &c.Low, &c.Close, &c.Volume, &c.Count, &c.OIOpen, &c.OIClose); err != nil {
 			return nil, fmt.Errorf("sqlite scan candles_tf: %w", err)
 		}
 		c.TS = time.Unix(tsUnix, 0).UTC()
@@ -58,10 +59,73 @@ func (r *Reader) ReadTFCandles(exchange, token string, tf int, afterTS int64) ([
 	return candles, rows.Err()
 }
 
+// ReadLatestTFCandles reads the n most recent TF candles of one instrument,
+// ordered by timestamp ascending. Used to warm up indicators added at runtime.
+func (r *Reader) ReadLatestTFCandles(exchange, token string, tf, n int) ([]model.TFCandle, error) {
+	rows, err := r.db.Query(`
+		SELECT token, exchange, tf, ts, open, high, low, close, volume, count,
+			COALESCE(oi_open, 0), COALESCE(oi_close, 0)
+		FROM candles_tf
+		WHERE exchange = ? AND token = ? AND tf = ?
+		ORDER BY ts DESC
+		LIMIT ?
+	`, exchange, token, tf, n)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite query latest candles_tf: %w", err)
+	}
+	defer rows.Close()
+
+	var candles []model.TFCandle
+	for rows.Next() {
+		var c model.TFCandle
+		var tsUnix int64
+		if err := rows.Scan(&c.Token, &c.Exchange, &c.TF, &tsUnix, &c.Open, &c.High, &c.Low, &c.Close, &c.Volume, &c.Count, &c.OIOpen, &c.OIClose); err != nil {
+			return nil, fmt.Errorf("sqlite scan candles_tf: %w", err)
+		}
+		c.TS = time.Unix(tsUnix, 0).UTC()
+		candles = append(candles, c)
+	}
+	for i, j := 0, len(candles)-1; i < j; i, j = i+1, j-1 {
+		candles[i], candles[j] = candles[j], candles[i]
+	}
+	return candles, rows.Err()
+}
+
+// Read1sCandles reads the traded 1s candles of one instrument with ts in
+// [from, to), ordered by timestamp. Gap-fill candles are left out.
+func (r *Reader) Read1sCandles(exchange, token string, from, to time.Time) ([]model.Candle, error) {
+	rows, err := r.db.Query(`
+		SELECT token, exchange, ts, open, high, low, close, volume, ticks_count
+		FROM candles_1s
+		WHERE exchange = ? AND token = ? AND ts >= ? AND ts < ? AND synthetic IS NULL
+		ORDER BY ts ASC
+	`, exchange, token, from.Unix(), to.Unix())
+	if err != nil {
+		return nil, fmt.Errorf("sqlite query candles_1s: %w", err)
+	}
+	defer rows.Close()
+
+	var candles []model.Candle
+	for rows.Next() {
+		var c model.Candle
+		var tsUnix int64
+		var volume, ticks sql.NullInt64
+		if err := rows.Scan(&c.Token, &c.Exchange, &tsUnix, &c.Open, &c.High, &c.Low, &c.Close, &volume, &ticks); err != nil {
+			return nil, fmt.Errorf("sqlite scan candles_1s: %w", err)
+		}
+		c.TS = time.Unix(tsUnix, 0).UTC()
+		c.Volume = volume.Int64
+		c.TicksCount = int(ticks.Int64)
+		candles = append(candles, c)
+	}
+	return candles, rows.Err()
+}
+
 // ReadAllTFCandles reads all TF candles from SQLite for backfill, ordered by timestamp.
 func (r *Reader) ReadAllTFCandles(tf int, afterTS int64) ([]model.TFCandle, error) {
 	rows, err := r.db.Query(`
-		SELECT token, exchange, tf, ts, open, high, low, close, volume, count
+		SELECT token, exchange, tf, ts, open, high, low, close, volume, count,
+			COALESCE(oi_open, 0), COALESCE(oi_close, 0)
 		FROM candles_tf
 		WHERE tf = ? AND ts > ?
 		ORDER BY ts ASC
@@ -75,7 +139,7 @@ func (r *Reader) ReadAllTFCandles(tf int, afterTS int64) ([]model.TFCandle, erro
 	for rows.Next() {
 		var c model.TFCandle
 		var tsUnix int64
-		if err := rows.Scan(&c.Token, &c.Exchange, &c.TF, &tsUnix, &c.Open, &c.High, &c.Low, &c.Close, &c.Volume, &c.Count); err != nil {
+		if err := rows.Scan(&c.Token, &c.Exchange, &c.TF, &tsUnix, &c.Open, &c.High, &c.Low, &c.Close, &c.Volume, &c.Count, &c.OIOpen, &c.OIClose); err != nil {
 			return nil, fmt.Errorf("sqlite scan candles_tf: %w", err)
 		}
 		c.TS = time.Unix(tsUnix, 0).UTC()
@@ -84,6 +148,90 @@ func (r *Reader) ReadAllTFCandles(tf int, afterTS int64) ([]model.TFCandle, erro
 	return candles, rows.Err()
 }
 
+// sessionQuery1s aggregates candles_1s per instrument over [from, to).
+const sessionQuery1s = `
+	SELECT g.exchange, g.token,
+		(SELECT x.open FROM candles_1s x WHERE x.exchange = g.exchange AND x.token = g.token AND x.ts = g.first_ts),
+		g.high, g.low,
+		(SELECT x.close FROM candles_1s x WHERE x.exchange = g.exchange AND x.token = g.token AND x.ts = g.last_ts),
+		g.volume, g.n
+	FROM (
+		SELECT exchange, token, MIN(ts) AS first_ts, MAX(ts) AS last_ts,
+			MAX(high) AS high, MIN(low) AS low, SUM(COALESCE(volume, 0)) AS volume, COUNT(*) AS n
+		FROM candles_1s
+		WHERE ts >= ? AND ts < ? AND synthetic IS NULL
+		GROUP BY exchange, token
+	) g
+`
+
+// sessionQueryTF aggregates candles_tf per instrument over [from, to),
+// using each instrument's finest TF in the range.
+const sessionQueryTF = `
+	SELECT g.exchange, g.token,
+		(SELECT x.open FROM candles_tf x WHERE x.exchange = g.exchange AND x.token = g.token AND x.tf = g.tf AND x.ts = g.first_ts),
+		g.high, g.low,
+		(SELECT x.close FROM candles_tf x WHERE x.exchange = g.exchange AND x.token = g.token AND x.tf = g.tf AND x.ts = g.last_ts),
+		g.volume, g.n
+	FROM (
+		SELECT exchange, token, tf, MIN(ts) AS first_ts, MAX(ts) AS last_ts,
+			MAX(high) AS high, MIN(low) AS low, SUM(COALESCE(volume, 0)) AS volume, COUNT(*) AS n
+		FROM candles_tf c
+		WHERE ts >= ? AND ts < ? AND synthetic IS NULL AND tf = (
+			SELECT MIN(tf) FROM candles_tf m
+			WHERE m.exchange = c.exchange AND m.token = c.token AND m.ts >= ? AND m.ts < ?
+		)
+		GROUP BY exchange, token
+	) g
+`
+
+// ReadSessionCandles aggregates every instrument's traded candles in
+// [from, to) (gap-fill candles excluded) into one candle per instrument: open of the first, high/low over the range,
+// close of the last, summed volume. TS is set to from and TF to the range
+// length in seconds.
+//
+// candles_1s is the source of truth; instruments without 1s data in the
+// range fall back to their finest TF in candles_tf.
+func (r *Reader) ReadSessionCandles(from, to time.Time) ([]model.TFCandle, error) {
+	candles, err := r.readSession(sessionQuery1s, from, to, from.Unix(), to.Unix())
+	if err != nil {
+		return nil, fmt.Errorf("sqlite query session candles_1s: %w", err)
+	}
+	seen := make(map[string]bool, len(candles))
+	for i := range candles {
+		seen[candles[i].Key()] = true
+	}
+
+	tfCandles, err := r.readSession(sessionQueryTF, from, to, from.Unix(), to.Unix(), from.Unix(), to.Unix())
+	if err != nil {
+		return nil, fmt.Errorf("sqlite query session candles_tf: %w", err)
+	}
+	for _, c := range tfCandles {
+		if !seen[c.Key()] {
+			candles = append(candles, c)
+		}
+	}
+	return candles, nil
+}
+
+// readSession runs one of the session aggregate queries.
+func (r *Reader) readSession(query string, from, to time.Time, args ...interface{}) ([]model.TFCandle, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var candles []model.TFCandle
+	for rows.Next() {
+		c := model.TFCandle{TF: int(to.Sub(from).Seconds()), TS: from.UTC()}
+		if err := rows.Scan(&c.Exchange, &c.Token, &c.Open, &c.High, &c.Low, &c.Close, &c.Volume, &c.Count); err != nil {
+			return nil, err
+		}
+		candles = append(candles, c)
+	}
+	return candles, rows.Err()
+}
+
 // ReadLatestSnapshot loads the most recent indicator engine snapshot from SQLite.
 func (r *Reader) ReadLatestSnapshot() (*indicator.EngineSnapshot, error) {
 	var data string
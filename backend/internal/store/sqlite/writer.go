@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"trading-systemv1/internal/indicator"
@@ -64,6 +65,8 @@ func createSchema(db *sql.DB) error {
 			close      INTEGER NOT NULL,
 			volume     INTEGER,
 			ticks_count INTEGER,
+			revision   INTEGER,
+			synthetic  INTEGER,
 			PRIMARY KEY (exchange, token, ts)
 		);
 
@@ -78,6 +81,10 @@ func createSchema(db *sql.DB) error {
 			close      INTEGER NOT NULL,
 			volume     INTEGER,
 			count      INTEGER,
+			oi_open    INTEGER,
+			oi_close   INTEGER,
+			revision   INTEGER,
+			synthetic  INTEGER,
 			PRIMARY KEY (exchange, token, tf, ts)
 		);
 
@@ -87,7 +94,47 @@ func createSchema(db *sql.DB) error {
 			created_at INTEGER NOT NULL DEFAULT (strftime('%%s', 'now'))
 		);
 	`)
-	return err
+	if err != nil {
+		return err
+	}
+	if err := addColumns(db, "candles_1s", "revision INTEGER", "synthetic INTEGER"); err != nil {
+		return err
+	}
+	return addColumns(db, "candles_tf", "oi_open INTEGER", "oi_close INTEGER", "revision INTEGER", "synthetic INTEGER")
+}
+
+// addColumns adds the columns ("name TYPE") a table created by an older
+// version lacks.
+func addColumns(db *sql.DB, table string, columns ...string) error {
+	rows, err := db.Query(`SELECT name FROM pragma_table_info(?)`, table)
+	if err != nil {
+		return err
+	}
+	have := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return err
+		}
+		have[name] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, col := range columns {
+		name, _, _ := strings.Cut(col, " ")
+		if have[name] {
+			continue
+		}
+		if _, err := db.Exec(`ALTER TABLE ` + table + ` ADD COLUMN ` + col); err != nil {
+			return fmt.Errorf("add %s.%s: %w", table, name, err)
+		}
+		log.Printf("[sqlite] added column %s.%s", table, name)
+	}
+	return nil
 }
 
 // Run reads candles from candleCh and inserts them in batched transactions.
@@ -135,7 +182,8 @@ func (w *Writer) Run(ctx context.Context, candleCh <-chan model.Candle) {
 	}
 }
 
-// insertBatch inserts a batch of candles in a single transaction.
+// insertBatch inserts a batch of candles in a single transaction. A revised
+// candle replaces the stored version of its second.
 func (w *Writer) insertBatch(candles []model.Candle) error {
 	tx, err := w.db.Begin()
 	if err != nil {
@@ -143,8 +191,8 @@ func (w *Writer) insertBatch(candles []model.Candle) error {
 	}
 
 	stmt, err := tx.Prepare(`
-		INSERT OR REPLACE INTO candles_1s (token, exchange, ts, open, high, low, close, volume, ticks_count)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT OR REPLACE INTO candles_1s (token, exchange, ts, open, high, low, close, volume, ticks_count, revision, synthetic)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, NULLIF(?, 0), NULLIF(?, 0))
 	`)
 	if err != nil {
 		tx.Rollback()
@@ -153,7 +201,7 @@ func (w *Writer) insertBatch(candles []model.Candle) error {
 	defer stmt.Close()
 
 	for _, c := range candles {
-		_, err := stmt.Exec(c.Token, c.Exchange, c.TS.Unix(), c.Open, c.High, c.Low, c.Close, c.Volume, c.TicksCount)
+		_, err := stmt.Exec(c.Token, c.Exchange, c.TS.Unix(), c.Open, c.High, c.Low, c.Close, c.Volume, c.TicksCount, c.Revision, boolInt(c.Synthetic))
 		if err != nil {
 			tx.Rollback()
 			return err
@@ -163,6 +211,14 @@ func (w *Writer) insertBatch(candles []model.Candle) error {
 	return tx.Commit()
 }
 
+// boolInt maps a flag to its column value: 1, or 0 (stored as NULL).
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
 // GetLastTimestamp returns the last stored candle timestamp for a given instrument.
 // Returns 0 if no candles exist.
 func (w *Writer) GetLastTimestamp(exchange, token string) (int64, error) {
@@ -221,7 +277,8 @@ func (w *Writer) RunTFCandles(ctx context.Context, tfCandleCh <-chan model.TFCan
 	}
 }
 
-// insertTFBatch inserts a batch of TF candles in a single transaction.
+// insertTFBatch inserts a batch of TF candles in a single transaction. A
+// revised candle replaces the stored version of its bar.
 func (w *Writer) insertTFBatch(candles []model.TFCandle) error {
 	tx, err := w.db.Begin()
 	if err != nil {
@@ -229,8 +286,8 @@ func (w *Writer) insertTFBatch(candles []model.TFCandle) error {
 	}
 
 	stmt, err := tx.Prepare(`
-		INSERT OR REPLACE INTO candles_tf (token, exchange, tf, ts, open, high, low, close, volume, count)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT OR REPLACE INTO candles_tf (token, exchange, tf, ts, open, high, low, close, volume, count, oi_open, oi_close, revision, synthetic)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, NULLIF(?, 0), NULLIF(?, 0), NULLIF(?, 0), NULLIF(?, 0))
 	`)
 	if err != nil {
 		tx.Rollback()
@@ -239,7 +296,7 @@ func (w *Writer) insertTFBatch(candles []model.TFCandle) error {
 	defer stmt.Close()
 
 	for _, c := range candles {
-		_, err := stmt.Exec(c.Token, c.Exchange, c.TF, c.TS.Unix(), c.Open, c.High, c.Low, c.Close, c.Volume, c.Count)
+		_, err := stmt.Exec(c.Token, c.Exchange, c.TF, c.TS.Unix(), c.Open, c.High, c.Low, c.Close, c.Volume, c.Count, c.OIOpen, c.OIClose, c.Revision, boolInt(c.Synthetic))
 		if err != nil {
 			tx.Rollback()
 			return err
@@ -0,0 +1,125 @@
+package tickstore
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"trading-systemv1/internal/model"
+)
+
+// blockVersion is the first byte of every encoded block. Version 1 blocks
+// (no open interest column) are still decoded.
+const blockVersion = 2
+
+// encodeBlock packs the ticks of one instrument, in arrival order, into a
+// compressed block. Each tick is varint-encoded as deltas from the previous
+// one (arrival µs, price, open interest), then qty<<1|hasEventTS, then the
+// event time as a µs offset from arrival when present. The result is
+// flate-compressed.
+func encodeBlock(ticks []model.Tick) ([]byte, error) {
+	raw := make([]byte, 0, 1+binary.MaxVarintLen64+len(ticks)*8)
+	raw = append(raw, blockVersion)
+	raw = binary.AppendUvarint(raw, uint64(len(ticks)))
+
+	var prevTS, prevPrice, prevOI int64
+	for _, t := range ticks {
+		ts := t.TickTS.UnixMicro()
+		raw = binary.AppendVarint(raw, ts-prevTS)
+		raw = binary.AppendVarint(raw, t.Price-prevPrice)
+		raw = binary.AppendVarint(raw, t.OI-prevOI)
+		prevTS, prevPrice, prevOI = ts, t.Price, t.OI
+
+		flags := uint64(t.Qty) << 1
+		if !t.EventTS.IsZero() {
+			flags |= 1
+		}
+		raw = binary.AppendUvarint(raw, flags)
+		if !t.EventTS.IsZero() {
+			raw = binary.AppendVarint(raw, t.EventTS.UnixMicro()-ts)
+		}
+	}
+	return deflate(raw)
+}
+
+// deflate flate-compresses an encoded block.
+func deflate(raw []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw, err := flate.NewWriter(&buf, flate.BestSpeed)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := zw.Write(raw); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeBlock unpacks a block written by encodeBlock, or by its version 1
+// (ticks without open interest). Timestamps are UTC.
+func decodeBlock(exchange, token string, data []byte) ([]model.Tick, error) {
+	raw, err := io.ReadAll(flate.NewReader(bytes.NewReader(data)))
+	if err != nil {
+		return nil, fmt.Errorf("inflate: %w", err)
+	}
+	if len(raw) == 0 || raw[0] < 1 || raw[0] > blockVersion {
+		return nil, errors.New("unknown block version")
+	}
+	hasOI := raw[0] >= 2
+	r := bytes.NewReader(raw[1:])
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, fmt.Errorf("block header: %w", err)
+	}
+
+	ticks := make([]model.Tick, 0, n)
+	var ts, price, oi int64
+	for i := uint64(0); i < n; i++ {
+		dts, err := binary.ReadVarint(r)
+		if err != nil {
+			return nil, fmt.Errorf("tick %d: %w", i, err)
+		}
+		dprice, err := binary.ReadVarint(r)
+		if err != nil {
+			return nil, fmt.Errorf("tick %d: %w", i, err)
+		}
+		if hasOI {
+			doi, err := binary.ReadVarint(r)
+			if err != nil {
+				return nil, fmt.Errorf("tick %d: %w", i, err)
+			}
+			oi += doi
+		}
+		flags, err := binary.ReadUvarint(r)
+		if err != nil {
+			return nil, fmt.Errorf("tick %d: %w", i, err)
+		}
+		ts += dts
+		price += dprice
+
+		t := model.Tick{
+			Token:    token,
+			Exchange: exchange,
+			Price:    price,
+			Qty:      int64(flags >> 1),
+			TickTS:   time.UnixMicro(ts).UTC(),
+			OI:       oi,
+		}
+		if flags&1 != 0 {
+			off, err := binary.ReadVarint(r)
+			if err != nil {
+				return nil, fmt.Errorf("tick %d: %w", i, err)
+			}
+			t.EventTS = time.UnixMicro(ts + off).UTC()
+		}
+		ticks = append(ticks, t)
+	}
+	return ticks, nil
+}
@@ -0,0 +1,98 @@
+package tickstore
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"trading-systemv1/internal/markethours"
+	"trading-systemv1/internal/model"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// Reader provides read-only access to the tick archive.
+type Reader struct {
+	db *sql.DB
+}
+
+// NewReader opens the tick archive for reading.
+func NewReader(dbPath string) (*Reader, error) {
+	db, err := sql.Open("sqlite3", dbPath+"?_journal_mode=WAL&_synchronous=NORMAL&_busy_timeout=5000")
+	if err != nil {
+		return nil, fmt.Errorf("tickstore open reader: %w", err)
+	}
+	db.SetMaxOpenConns(2)
+	db.SetMaxIdleConns(2)
+
+	log.Printf("[tickstore-reader] opened %s", dbPath)
+	return &Reader{db: db}, nil
+}
+
+// Days returns the archived IST trading days, oldest first.
+func (r *Reader) Days() ([]time.Time, error) {
+	names, err := listDays(r.db)
+	if err != nil {
+		return nil, fmt.Errorf("tickstore list days: %w", err)
+	}
+	days := make([]time.Time, 0, len(names))
+	for _, name := range names {
+		d, _ := time.ParseInLocation(dayLayout, name, markethours.IST)
+		days = append(days, d)
+	}
+	return days, nil
+}
+
+// ReadTicks returns the archived ticks of one instrument that arrived in
+// [from, to), in arrival order.
+func (r *Reader) ReadTicks(exchange, token string, from, to time.Time) ([]model.Tick, error) {
+	names, err := listDays(r.db)
+	if err != nil {
+		return nil, fmt.Errorf("tickstore list days: %w", err)
+	}
+	fromDay, toDay := dayOf(from), dayOf(to)
+
+	var ticks []model.Tick
+	for _, day := range names {
+		if day < fromDay || day > toDay {
+			continue
+		}
+		rows, err := r.db.Query(`
+			SELECT data FROM `+tablePrefix+day+`
+			WHERE exchange = ? AND token = ? AND last_ts >= ? AND first_ts < ?
+			ORDER BY id ASC
+		`, exchange, token, from.UnixMicro(), to.UnixMicro())
+		if err != nil {
+			return nil, fmt.Errorf("tickstore query %s: %w", day, err)
+		}
+		for rows.Next() {
+			var data []byte
+			if err := rows.Scan(&data); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("tickstore scan %s: %w", day, err)
+			}
+			block, err := decodeBlock(exchange, token, data)
+			if err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("tickstore decode %s: %w", day, err)
+			}
+			for _, t := range block {
+				if !t.TickTS.Before(from) && t.TickTS.Before(to) {
+					ticks = append(ticks, t)
+				}
+			}
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ticks, nil
+}
+
+// Close closes the database.
+func (r *Reader) Close() error {
+	return r.db.Close()
+}
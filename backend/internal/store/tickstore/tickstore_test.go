@@ -0,0 +1,145 @@
+package tickstore
+
+import (
+	"context"
+	"encoding/binary"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"trading-systemv1/internal/markethours"
+	"trading-systemv1/internal/model"
+)
+
+// istTick builds a tick arriving at the given IST wall-clock time.
+func istTick(token string, day, hour, min, sec, micro int, price, qty int64) model.Tick {
+	ts := time.Date(2026, time.January, day, hour, min, sec, micro*1000, markethours.IST)
+	return model.Tick{Token: token, Exchange: "NSE", Price: price, Qty: qty, TickTS: ts.UTC()}
+}
+
+func TestCodecRoundTrip(t *testing.T) {
+	ticks := []model.Tick{
+		istTick("2885", 5, 9, 15, 0, 123, 245050, 10),
+		istTick("2885", 5, 9, 15, 0, 456, 245000, 0),
+		istTick("2885", 5, 9, 15, 2, 0, 245125, 75),
+	}
+	ticks[1].EventTS = ticks[1].TickTS.Add(-40 * time.Millisecond)
+	ticks[2].TickTS = ticks[1].TickTS.Add(-time.Millisecond) // arrival out of order
+	ticks[0].OI, ticks[1].OI, ticks[2].OI = 1250000, 1250000, 1236500
+
+	data, err := encodeBlock(ticks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := decodeBlock("NSE", "2885", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, ticks) {
+		t.Errorf("round trip mismatch:\n got %+v\nwant %+v", got, ticks)
+	}
+	if _, err := decodeBlock("NSE", "2885", data[:len(data)/2]); err == nil {
+		t.Error("truncated block should fail to decode")
+	}
+}
+
+func TestCodecDecodesVersion1(t *testing.T) {
+	tick := istTick("2885", 5, 9, 15, 0, 123, 245050, 10)
+	ts := tick.TickTS.UnixMicro()
+
+	// Version 1: count, then per tick Δts, Δprice, qty<<1|hasEventTS
+	raw := []byte{1}
+	raw = binary.AppendUvarint(raw, 1)
+	raw = binary.AppendVarint(raw, ts)
+	raw = binary.AppendVarint(raw, tick.Price)
+	raw = binary.AppendUvarint(raw, uint64(tick.Qty)<<1)
+	data, err := deflate(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := decodeBlock("NSE", "2885", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []model.Tick{tick}; !reflect.DeepEqual(got, want) {
+		t.Errorf("v1 decode mismatch:\n got %+v\nwant %+v", got, want)
+	}
+}
+
+func TestWriterReader(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ticks.db")
+	w, err := New(WriterConfig{DBPath: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	ticks := []model.Tick{
+		istTick("2885", 5, 9, 15, 0, 0, 100, 1),
+		istTick("1594", 5, 9, 15, 0, 500, 900, 2),
+		istTick("2885", 5, 9, 15, 1, 0, 101, 3),
+		istTick("2885", 5, 15, 29, 59, 0, 102, 4),
+		istTick("2885", 6, 9, 15, 0, 0, 110, 5), // next day's table
+	}
+	ch := make(chan model.Tick, len(ticks))
+	for _, tk := range ticks {
+		ch <- tk
+	}
+	close(ch)
+	w.Run(context.Background(), ch)
+
+	r, err := NewReader(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	days, err := r.Days()
+	if err != nil || len(days) != 2 || days[0].Day() != 5 || days[1].Day() != 6 {
+		t.Fatalf("Days() = %v, %v; want 5 and 6 Jan", days, err)
+	}
+
+	from := time.Date(2026, time.January, 5, 9, 15, 1, 0, markethours.IST)
+	to := time.Date(2026, time.January, 6, 9, 15, 0, 0, markethours.IST)
+	got, err := r.ReadTicks("NSE", "2885", from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].Price != 101 || got[1].Price != 102 {
+		t.Errorf("ReadTicks [from, to) = %+v, want prices 101, 102", got)
+	}
+
+	all, _ := r.ReadTicks("NSE", "2885", from.Add(-time.Hour), to.Add(time.Hour))
+	if len(all) != 4 || all[3].Qty != 5 {
+		t.Errorf("expected 4 ticks across both days, got %+v", all)
+	}
+}
+
+func TestPrune(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ticks.db")
+	w, err := New(WriterConfig{DBPath: path, RetentionDays: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	blocks := map[blockKey][]model.Tick{}
+	for _, day := range []int{1, 2, 3, 4} {
+		tk := istTick("2885", day, 10, 0, 0, 0, 100, 1)
+		blocks[blockKey{day: dayOf(tk.TickTS), exchange: "NSE", token: "2885"}] = []model.Tick{tk}
+	}
+	if err := w.insertBlocks(blocks); err != nil {
+		t.Fatal(err)
+	}
+
+	w.prune(time.Date(2026, time.January, 5, 12, 0, 0, 0, markethours.IST))
+	days, err := listDays(w.db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"20260103", "20260104"}; !reflect.DeepEqual(days, want) {
+		t.Errorf("after prune: %v, want %v", days, want)
+	}
+}
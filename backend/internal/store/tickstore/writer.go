@@ -0,0 +1,244 @@
+// Package tickstore archives the raw tick stream in SQLite so sessions can be
+// replayed and aggregator behaviour reproduced tick by tick.
+//
+// Ticks are stored in one table per IST trading day ("ticks_20260105"), as
+// compressed blocks of consecutive ticks of one instrument (see encodeBlock).
+// Retention drops whole day tables, so pruning never rewrites live data.
+package tickstore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"trading-systemv1/internal/markethours"
+	"trading-systemv1/internal/model"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+const (
+	defaultBatchSize  = 5000        // ticks buffered before a forced flush
+	defaultFlushDelay = time.Second // max time a tick waits in memory
+	pruneInterval     = time.Hour
+
+	tablePrefix = "ticks_"
+	dayLayout   = "20060102"
+)
+
+// WriterConfig configures the tick archive writer.
+type WriterConfig struct {
+	DBPath        string // path to the archive database, e.g. "data/ticks.db"
+	RetentionDays int    // day tables older than this are dropped; 0 keeps everything
+}
+
+// Writer is a single-goroutine tick archiver with block batching.
+type Writer struct {
+	db            *sql.DB
+	retentionDays int
+	tables        map[string]bool // day tables known to exist
+}
+
+// blockKey groups buffered ticks into one block per instrument and day.
+type blockKey struct {
+	day      string // "20060102" (IST)
+	exchange string
+	token    string
+}
+
+// New opens (or creates) the tick archive with WAL mode.
+func New(cfg WriterConfig) (*Writer, error) {
+	db, err := sql.Open("sqlite3", cfg.DBPath+"?_journal_mode=WAL&_synchronous=NORMAL&_busy_timeout=5000")
+	if err != nil {
+		return nil, fmt.Errorf("tickstore open: %w", err)
+	}
+	db.SetMaxOpenConns(1)
+	db.SetMaxIdleConns(1)
+
+	w := &Writer{db: db, retentionDays: cfg.RetentionDays, tables: make(map[string]bool)}
+	days, err := listDays(db)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("tickstore list tables: %w", err)
+	}
+	for _, d := range days {
+		w.tables[d] = true
+	}
+
+	log.Printf("[tickstore] opened tick archive at %s (%d days, retention=%dd)", cfg.DBPath, len(days), cfg.RetentionDays)
+	return w, nil
+}
+
+// Run archives ticks from tickCh until ctx is cancelled or tickCh is closed.
+// Flushes every defaultBatchSize ticks OR every defaultFlushDelay, whichever
+// first, and prunes expired days every hour.
+func (w *Writer) Run(ctx context.Context, tickCh <-chan model.Tick) {
+	pending := make(map[blockKey][]model.Tick)
+	n := 0
+	timer := time.NewTimer(defaultFlushDelay)
+	defer timer.Stop()
+	pruneTicker := time.NewTicker(pruneInterval)
+	defer pruneTicker.Stop()
+
+	w.prune(time.Now())
+
+	flush := func() {
+		if n == 0 {
+			return
+		}
+		start := time.Now()
+		if err := w.insertBlocks(pending); err != nil {
+			log.Printf("[tickstore] block insert error: %v", err)
+		} else {
+			log.Printf("[tickstore] archived %d ticks in %d blocks in %v", n, len(pending), time.Since(start))
+		}
+		pending = make(map[blockKey][]model.Tick, len(pending))
+		n = 0
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			flush()
+			return
+		case t, ok := <-tickCh:
+			if !ok {
+				flush()
+				return
+			}
+			k := blockKey{day: dayOf(t.TickTS), exchange: t.Exchange, token: t.Token}
+			pending[k] = append(pending[k], t)
+			n++
+			if n >= defaultBatchSize {
+				flush()
+				timer.Reset(defaultFlushDelay)
+			}
+		case <-timer.C:
+			flush()
+			timer.Reset(defaultFlushDelay)
+		case now := <-pruneTicker.C:
+			w.prune(now)
+		}
+	}
+}
+
+// insertBlocks writes one compressed block per (day, instrument) in a single transaction.
+func (w *Writer) insertBlocks(blocks map[blockKey][]model.Tick) error {
+	tx, err := w.db.Begin()
+	if err != nil {
+		return err
+	}
+	created := make([]string, 0, 1)
+	for k, ticks := range blocks {
+		if !w.tables[k.day] {
+			if err := createDayTable(tx, k.day); err != nil {
+				tx.Rollback()
+				return err
+			}
+			created = append(created, k.day)
+		}
+		data, err := encodeBlock(ticks)
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("encode block: %w", err)
+		}
+		first, last := tickRange(ticks)
+		_, err = tx.Exec(`INSERT INTO `+tablePrefix+k.day+` (exchange, token, first_ts, last_ts, count, data)
+			VALUES (?, ?, ?, ?, ?, ?)`, k.exchange, k.token, first, last, len(ticks), data)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	for _, d := range created {
+		w.tables[d] = true
+	}
+	return nil
+}
+
+// prune drops the day tables older than the retention window.
+func (w *Writer) prune(now time.Time) {
+	if w.retentionDays <= 0 {
+		return
+	}
+	cutoff := dayOf(now.AddDate(0, 0, -w.retentionDays))
+	for day := range w.tables {
+		if day >= cutoff {
+			continue
+		}
+		if _, err := w.db.Exec(`DROP TABLE IF EXISTS ` + tablePrefix + day); err != nil {
+			log.Printf("[tickstore] prune %s: %v", day, err)
+			continue
+		}
+		delete(w.tables, day)
+		log.Printf("[tickstore] pruned ticks of %s (retention %dd)", day, w.retentionDays)
+	}
+}
+
+// Close closes the archive database.
+func (w *Writer) Close() error {
+	return w.db.Close()
+}
+
+// createDayTable creates a day's block table and its instrument index.
+func createDayTable(tx *sql.Tx, day string) error {
+	table := tablePrefix + day
+	_, err := tx.Exec(`
+		CREATE TABLE IF NOT EXISTS ` + table + ` (
+			id       INTEGER PRIMARY KEY AUTOINCREMENT,
+			exchange TEXT    NOT NULL,
+			token    TEXT    NOT NULL,
+			first_ts INTEGER NOT NULL, -- earliest tick arrival in the block (Unix µs)
+			last_ts  INTEGER NOT NULL, -- latest tick arrival in the block (Unix µs)
+			count    INTEGER NOT NULL,
+			data     BLOB    NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS ` + table + `_inst ON ` + table + ` (exchange, token, first_ts);
+	`)
+	return err
+}
+
+// listDays returns the archived days ("20060102"), oldest first.
+func listDays(db *sql.DB) ([]string, error) {
+	rows, err := db.Query(`SELECT name FROM sqlite_master WHERE type = 'table' AND name LIKE 'ticks\_%' ESCAPE '\'`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var days []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		day := strings.TrimPrefix(name, tablePrefix)
+		if _, err := time.Parse(dayLayout, day); err == nil {
+			days = append(days, day)
+		}
+	}
+	sort.Strings(days)
+	return days, rows.Err()
+}
+
+// dayOf returns the IST trading day of t as "20060102".
+func dayOf(t time.Time) string {
+	return t.In(markethours.IST).Format(dayLayout)
+}
+
+// tickRange returns the earliest and latest arrival time of ticks (Unix µs).
+func tickRange(ticks []model.Tick) (first, last int64) {
+	first, last = ticks[0].TickTS.UnixMicro(), ticks[0].TickTS.UnixMicro()
+	for _, t := range ticks[1:] {
+		ts := t.TickTS.UnixMicro()
+		first, last = min(first, ts), max(last, ts)
+	}
+	return first, last
+}
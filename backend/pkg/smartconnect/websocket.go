@@ -73,6 +73,10 @@ type SmartWebSocketV3 struct {
 	ClientCode string
 	FeedToken  string
 
+	// URL is the SmartStream endpoint (default RootURI); point it at a local
+	// emulator to exercise the binary protocol offline.
+	URL string
+
 	Conn   *websocket.Conn
 	Dialer *websocket.Dialer
 
@@ -116,6 +120,7 @@ func NewSmartWebSocketV3(authToken, apiKey, clientCode, feedToken string, maxRet
 		APIKey:          apiKey,
 		ClientCode:      clientCode,
 		FeedToken:       feedToken,
+		URL:             RootURI,
 		Dialer:          websocket.DefaultDialer,
 		inputRequestMap: make(map[int]modeMap),
 		resubscribeFlag: false,
@@ -139,7 +144,7 @@ func (s *SmartWebSocketV3) Connect() error {
 	header.Add("x-client-code", s.ClientCode)
 	header.Add("x-feed-token", s.FeedToken)
 
-	conn, resp, err := s.Dialer.Dial(RootURI, header)
+	conn, resp, err := s.Dialer.Dial(s.URL, header)
 	if err != nil {
 		if resp != nil {
 			log.Printf("Dial failed, status: %s", resp.Status)
@@ -224,11 +229,9 @@ func (s *SmartWebSocketV3) Subscribe(correlationID string, mode int, tokenList [
 	}
 	for _, tl := range tokenList {
 		ex := tl.ExchangeType
-		if existing, ok := s.inputRequestMap[mode][ex]; ok {
-			s.inputRequestMap[mode][ex] = append(existing, tl.Tokens...)
-		} else {
-			s.inputRequestMap[mode][ex] = append([]string{}, tl.Tokens...)
-		}
+		// drop tokens already held so a re-subscribe after reconnect does not duplicate them
+		existing := s.inputRequestMap[mode][ex]
+		s.inputRequestMap[mode][ex] = append(existing, filterRemove(tl.Tokens, existing)...)
 	}
 	s.mu.Unlock()
 
@@ -397,6 +400,10 @@ func (s *SmartWebSocketV3) readLoop() {
 func (s *SmartWebSocketV3) handleError(err error) {
 	// simple reconnect strategy
 	s.mu.Lock()
+	if s.disconnectFlag { // closed on purpose — do not reconnect
+		s.mu.Unlock()
+		return
+	}
 	s.resubscribeFlag = true
 	attempts := s.currentRetryAttempt
 	max := s.maxRetryAttempt
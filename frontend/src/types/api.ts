@@ -28,6 +28,10 @@ export interface CandleOut {
     exchange: string;
     tf: number;
     forming: boolean;
+    oi_open?: number;   // F&O open interest at the bar's open/close
+    oi_close?: number;
+    revision?: number;  // >0: amended by late ticks after it was finalized
+    synthetic?: boolean; // gap fill (GAP_FILL): no trade, flat at the previous close
 }
 
 export interface IndPoint {
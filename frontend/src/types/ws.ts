@@ -16,9 +16,15 @@ export interface WSEnvelope {
     // SNAPSHOT fields
     reqId?: string;
     symbol?: string;
-    tf?: number;
+    tf?: number;              // series TF: HA_BASE + tf for Heikin-Ashi
+    candleType?: 'raw' | 'ha';
     candles?: SnapshotCandle[];
     indicators?: Record<string, SnapshotIndPoint[]>;
+    patterns?: SnapshotPattern[];
+    levels?: SessionLevels | null;
+    oi?: OIBar[];             // F&O only
+    quote?: Quote;            // only when marketData included 'quote'
+    depth?: Depth;            // only when marketData included 'depth'
     // LIVE fields
     candle?: SnapshotCandle;
     // ERROR fields
@@ -64,6 +70,9 @@ export interface IndicatorPayload {
     token: string;
 }
 
+// Also the data of 'pub:amend:{tf}s:{exchange}:{token}' channel messages: a
+// finalized candle revised by late ticks (CANDLE_AMEND_WINDOW), to replace
+// the bar with the same ts.
 export interface CandlePayload {
     ts: string;
     open: number;
@@ -76,6 +85,10 @@ export interface CandlePayload {
     exchange: string;
     token: string;
     tf: number;
+    oi_open?: number;   // F&O open interest at the bar's open/close
+    oi_close?: number;
+    revision?: number;  // >0: amended after it was finalized
+    synthetic?: boolean; // gap fill (GAP_FILL): no trade, flat at the previous close
 }
 
 export interface TickPayload {
@@ -94,8 +107,10 @@ export interface SubscribeMsg {
     reqId: string;
     symbol: string;
     tf: number;
+    candleType?: 'raw' | 'ha';  // 'ha' subscribes to the Heikin-Ashi series (default 'raw')
     history: { candles: number };
     indicators: IndicatorSpecMsg[];
+    marketData?: Array<'quote' | 'depth'>;  // opt-in 'pub:quote' / 'pub:depth' channels for the symbol
 }
 
 export interface IndicatorSpecMsg {
@@ -113,6 +128,9 @@ export interface SnapshotCandle {
     close: number;
     volume: number;
     count?: number;
+    oi_open?: number;
+    oi_close?: number;
+    synthetic?: boolean;
 }
 
 export interface SnapshotIndPoint {
@@ -121,9 +139,109 @@ export interface SnapshotIndPoint {
     ready: boolean;
 }
 
+// Candlestick pattern marker (SNAPSHOT `patterns`, and the data of
+// 'pub:pattern:{name}:{tf}s:{exchange}:{token}' channel messages).
+export interface SnapshotPattern {
+    name: string;       // e.g. 'BULLISH_ENGULFING', 'DOJI'
+    ts: string;         // candle that completed the pattern
+    start_ts: string;   // first candle of the pattern
+    bars: number;
+    bias: 'bullish' | 'bearish' | 'neutral';
+}
+
+// Pivot levels in paise; r4/s4 are only set for Camarilla.
+export interface PivotLevels {
+    p: number;
+    r1: number; r2: number; r3: number; r4?: number;
+    s1: number; s2: number; s3: number; s4?: number;
+}
+
+// Session levels (SNAPSHOT `levels`, GET /api/levels, and the data of
+// 'pub:levels:{exchange}:{token}' channel messages). Prices in paise.
+export interface SessionLevels {
+    token: string;
+    exchange: string;
+    session: string;        // open of the session the levels apply to
+    prev_session: string;   // open of the session they were computed from
+    prev_open: number;
+    prev_high: number;
+    prev_low: number;
+    prev_close: number;
+    classic: PivotLevels;
+    fibonacci: PivotLevels;
+    camarilla: PivotLevels;
+    cpr: { tc: number; pivot: number; bc: number };
+}
+
+// Open interest analytics of one finalized F&O bar (SNAPSHOT `oi`, GET
+// /api/oi, and the data of 'pub:oi:{tf}s:{exchange}:{token}' channel
+// messages). Changes are against the previous bar's close.
+export interface OIBar {
+    token: string;
+    exchange: string;
+    tf: number;
+    ts: string;
+    close: number;          // paise
+    price_change: number;   // paise
+    oi_open: number;
+    oi_close: number;
+    oi_change: number;
+    oi_change_pct: number;
+    buildup: '' | 'long_buildup' | 'short_buildup' | 'short_covering' | 'long_unwinding';
+}
+
+// One order book level. Price in paise.
+export interface DepthLevel {
+    price: number;
+    qty: number;
+    orders: number;
+}
+
+// Latest quote (SNAPSHOT `quote`, GET /api/quote, and the data of
+// 'pub:quote:{exchange}:{token}' channel messages). Prices in paise; the
+// book, OI and circuit fields are only set in SnapQuote mode.
+export interface Quote {
+    token: string;
+    exchange: string;
+    mode: 'quote' | 'snapquote';
+    ts: string;
+    ltp: number;
+    last_qty: number;
+    avg_price: number;
+    volume: number;          // cumulative day volume
+    total_buy_qty: number;
+    total_sell_qty: number;
+    open: number;
+    high: number;
+    low: number;
+    prev_close: number;
+    bid_price?: number;
+    bid_qty?: number;
+    ask_price?: number;
+    ask_qty?: number;
+    bids?: DepthLevel[];     // best five, best first
+    asks?: DepthLevel[];
+    oi?: number;
+    upper_circuit?: number;
+    lower_circuit?: number;
+    high_52w?: number;
+    low_52w?: number;
+}
+
+// 20-level order book (SNAPSHOT `depth`, GET /api/depth, and the data of
+// 'pub:depth:{exchange}:{token}' channel messages).
+export interface Depth {
+    token: string;
+    exchange: string;
+    ts: string;
+    bids: DepthLevel[];
+    asks: DepthLevel[];
+}
+
 export interface UnsubscribeMsg {
     type: 'UNSUBSCRIBE';
     reqId: string;
     symbol: string;
     tf: number;
+    candleType?: 'raw' | 'ha';
 }
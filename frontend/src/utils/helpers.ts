@@ -49,9 +49,26 @@ export function entryKey(entry: { name: string; tf: number }): string {
 
 export const IST_OFFSET = 5.5 * 3600; // +5:30 IST offset in seconds
 
+// Calendar TFs use nominal lengths in seconds (see markethours.TFDay etc.).
+export const TF_DAY = 86400;
+export const TF_WEEK = 7 * TF_DAY;
+export const TF_MONTH = 30 * TF_DAY;
+
+// Bar series (tick/volume/range/Renko) encode kind*BAR_KIND_BASE + size (see model.BarTF).
+export const BAR_KIND_BASE = 1e12;
+const BAR_KIND_NAMES = ['', 'tick', 'vol', 'range', 'renko'];
+
+// Heikin-Ashi series add HA_BASE to the TF they derive from (see model.HATF).
+export const HA_BASE = 100 * BAR_KIND_BASE;
+
 export function tfLabel(tf: number): string {
-    if (tf < 60) return tf + 's';
-    if (tf < 3600) return (tf / 60) + 'm';
+    if (tf >= HA_BASE) return 'HA ' + tfLabel(tf % HA_BASE);
+    if (tf === TF_DAY) return 'D';
+    if (tf === TF_WEEK) return 'W';
+    if (tf === TF_MONTH) return 'M';
+    if (tf >= BAR_KIND_BASE) return BAR_KIND_NAMES[Math.floor(tf / BAR_KIND_BASE)] + (tf % BAR_KIND_BASE);
+    if (tf < 60 || tf % 60 !== 0) return tf + 's';
+    if (tf < 3600 || tf % 3600 !== 0) return (tf / 60) + 'm';
     return (tf / 3600) + 'h';
 }
 
@@ -77,13 +94,21 @@ export function fmtUptime(sec: number): string {
     return parts.join(' ');
 }
 
+/** Parse a channel TF segment ("60s", "tick500", "ha60s") to its TF (see model.ParseTFKey). */
+export function parseTFKey(s: string): number {
+    if (s.startsWith('ha')) return HA_BASE + parseTFKey(s.slice(2));
+    const kind = BAR_KIND_NAMES.findIndex(name => name !== '' && s.startsWith(name));
+    if (kind > 0) return kind * BAR_KIND_BASE + parseInt(s.slice(BAR_KIND_NAMES[kind].length));
+    return parseInt(s);
+}
+
 export function parseChannel(ch: string) {
     const p = ch.split(':');
     if (p[1] === 'ind') {
-        return { type: 'indicator' as const, name: p[2], tf: parseInt(p[3]), exchange: p[4], token: p[5] };
+        return { type: 'indicator' as const, name: p[2], tf: parseTFKey(p[3]), exchange: p[4], token: p[5] };
     }
     if (p[1] === 'candle') {
-        return { type: 'candle' as const, tf: parseInt(p[2]), exchange: p[3], token: p[4] };
+        return { type: 'candle' as const, tf: parseTFKey(p[2]), exchange: p[3], token: p[4] };
     }
     if (p[1] === 'tick') {
         return { type: 'tick' as const, exchange: p[2], token: p[3] };